@@ -17,19 +17,60 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samber/lo"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
+	"github.com/nitrictech/cli/pkg/paths"
 	"github.com/nitrictech/cli/pkg/project"
 	"github.com/nitrictech/cli/pkg/view/tui"
 	"github.com/nitrictech/cli/pkg/view/tui/commands/build"
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
 )
 
+var (
+	buildPlatforms []string
+	buildList      bool
+	buildPrune     bool
+	buildPush      string
+	buildTags      []string
+	buildManifest  string
+)
+
+func printProjectImages(images []project.ProjectImage) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SERVICE\tIMAGE ID\tTAGS\tCREATED\tSIZE\tCURRENT")
+
+	for _, img := range images {
+		current := ""
+		if img.Current {
+			current = "yes"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1fMB\t%s\n",
+			img.Service,
+			strings.TrimPrefix(img.Id, "sha256:")[:12],
+			strings.Join(img.Tags, ", "),
+			img.Created.Format(time.DateTime),
+			float64(img.Size)/1e6,
+			current,
+		)
+	}
+
+	w.Flush()
+}
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build a Nitric project",
-	Long:  `Build all services in a nitric project as docker container images`,
+	Long:  `Build all services and batches in a nitric project as docker container images`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// info.Run(cmd.Context())
 		fs := afero.NewOsFs()
@@ -37,17 +78,78 @@ var buildCmd = &cobra.Command{
 		proj, err := project.FromFile(fs, "")
 		tui.CheckErr(err)
 
-		updates, err := proj.BuildServices(fs, !noBuilder)
+		if buildList {
+			images, err := proj.ListImages()
+			tui.CheckErr(err)
+
+			printProjectImages(images)
+
+			return
+		}
+
+		if buildPrune {
+			removed, err := proj.PruneImages()
+			tui.CheckErr(err)
+
+			fmt.Printf("Removed %d images no longer used by %s\n", len(removed), proj.Name)
+
+			return
+		}
+
+		// parse the tag templates before building, so mistakes are reported straight away
+		tagTemplates, err := project.ParseImageTagTemplates(buildTags)
 		tui.CheckErr(err)
 
-		prog := teax.NewProgram(build.NewModel(updates, "Building Services"))
+		builder, err := proj.ImageBuilder()
+		tui.CheckErr(err)
+
+		if buildPush != "" && builder.Daemonless() {
+			tui.CheckErr(fmt.Errorf("--push requires images to be built with docker, images from daemonless builders can be pushed from their OCI archives with tools such as crane or skopeo"))
+		}
+
+		updates, err := proj.BuildServices(fs, builder, !noBuilder, buildPlatforms)
+		tui.CheckErr(err)
+
+		batchUpdates, err := proj.BuildBatches(fs, builder, !noBuilder, buildPlatforms)
+		tui.CheckErr(err)
+
+		prog := teax.NewProgram(build.NewModel(lo.FanIn(10, updates, batchUpdates), "Building Services"))
 		// blocks but quits once the above updates channel is closed by the build process
-		_, err = prog.Run()
+		buildModel, err := prog.Run()
 		tui.CheckErr(err)
+
+		if buildPush == "" {
+			return
+		}
+
+		if buildModel.(build.Model).Err != nil {
+			tui.CheckErr(fmt.Errorf("error building services"))
+		}
+
+		manifest, err := proj.PushServices(buildPush, tagTemplates, func(serviceName string, image project.PushedImage) {
+			fmt.Printf("Pushed %s to %s (%s)\n", serviceName, image.Reference, strings.Join(image.Tags, ", "))
+		})
+		tui.CheckErr(err)
+
+		manifestPath := buildManifest
+		if manifestPath == "" {
+			manifestPath = paths.NitricPushManifestFile(proj.Directory)
+		}
+
+		tui.CheckErr(project.WritePushManifest(fs, manifest, manifestPath))
+
+		fmt.Printf("Wrote pushed image digests to %s\n", manifestPath)
 	},
 }
 
 func init() {
 	buildCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
-	rootCmd.AddCommand(tui.AddDependencyCheck(buildCmd, tui.RequireContainerBuilder))
+	buildCmd.Flags().BoolVar(&buildList, "list", false, "list the images built for this project")
+	buildCmd.Flags().BoolVar(&buildPrune, "prune", false, "remove images built for this project that aren't the latest build of a current service")
+	buildCmd.Flags().StringVar(&buildPush, "push", "", "push the built images to a registry, e.g. --push registry.example.com/my-project")
+	buildCmd.Flags().StringArrayVar(&buildTags, "tag", []string{project.DefaultImageTagTemplate}, "templates for the tags of pushed images, using .Service, .GitSha, .Version and .Digest, e.g. --tag '{{.Version}}'")
+	buildCmd.Flags().StringVar(&buildManifest, "manifest", "", "where to write the digests of pushed images (default .nitric/images.json)")
+	buildCmd.MarkFlagsMutuallyExclusive("list", "prune", "push")
+	buildCmd.Flags().StringSliceVar(&buildPlatforms, "platform", []string{}, "the platforms to build images for, e.g. --platform linux/amd64,linux/arm64")
+	rootCmd.AddCommand(tui.AddDependencyCheck(buildCmd, tui.RequireImageBuilder))
 }
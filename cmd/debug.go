@@ -17,26 +17,39 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/samber/lo"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/docker"
 	"github.com/nitrictech/cli/pkg/env"
+	"github.com/nitrictech/cli/pkg/paths"
 	"github.com/nitrictech/cli/pkg/project"
 	"github.com/nitrictech/cli/pkg/view/tui"
 	"github.com/nitrictech/cli/pkg/view/tui/commands/build"
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
 )
 
 var (
-	debugEnvFile string
-	debugFile    string
+	debugEnvFile       string
+	debugFile          string
+	debugGraphFormat   string
+	debugDiffFormat    string
+	debugStack         string
+	debugSaveBaseline  bool
+	debugCollectOnHost bool
 )
 
 var debugCmd = &cobra.Command{
@@ -51,39 +64,83 @@ var debugCmd = &cobra.Command{
 	},
 }
 
-var specCmd = &cobra.Command{
-	Use:   "spec",
-	Short: "Output the nitric application cloud spec.",
-	Long:  `Output the nitric application cloud spec.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fs := afero.NewOsFs()
+func writeSpecFile(fs afero.Fs, path string, spec *deploymentspb.Spec) error {
+	marshaler := protojson.MarshalOptions{
+		Multiline: true,
+		Indent:    "  ",
+	}
+
+	specJson, err := marshaler.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	err = fs.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, specJson, 0o644)
+}
+
+func readSpecFile(fs afero.Fs, path string) (*deploymentspb.Spec, error) {
+	specJson, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &deploymentspb.Spec{}
+
+	err = protojson.Unmarshal(specJson, spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spec %s: %w", path, err)
+	}
 
-		proj, err := project.FromFile(fs, "")
+	return spec, nil
+}
+
+// collectDebugSpec builds the project's services and collects their requirements into a deployment spec.
+// Build progress is written to progress, so commands writing machine readable output to stdout can send it elsewhere.
+func collectDebugSpec(fs afero.Fs, progress io.Writer) (*project.Project, []*collector.ServiceRequirements, []*collector.BatchRequirements, *deploymentspb.Spec) {
+	proj, err := project.FromFile(fs, "")
+	tui.CheckErr(err)
+
+	collectOnHost := debugCollectOnHost
+
+	var builder docker.ImageBuilder
+
+	if !collectOnHost {
+		builder, err = proj.ImageBuilder()
 		tui.CheckErr(err)
 
-		// Build the Project's Services (Containers)
-		buildUpdates, err := proj.BuildServices(fs, !noBuilder)
+		// images from daemonless builders can't be run to collect requirements, so the services are run on the host instead
+		collectOnHost = builder.Daemonless()
+	}
+
+	// Build the Project's Services (Containers), unless they're run on the host using their start commands
+	if !collectOnHost {
+		buildUpdates, err := proj.BuildServices(fs, builder, !noBuilder, []string{docker.NativePlatform()})
 		tui.CheckErr(err)
 
-		batchBuildUpdates, err := proj.BuildBatches(fs, !noBuilder)
+		batchBuildUpdates, err := proj.BuildBatches(fs, builder, !noBuilder, []string{docker.NativePlatform()})
 		tui.CheckErr(err)
 
 		allBuildUpdates := lo.FanIn(10, buildUpdates, batchBuildUpdates)
 
 		if isNonInteractive() {
-			fmt.Println("building project services")
+			fmt.Fprintln(progress, "building project services")
 			for _, service := range proj.GetServices() {
-				fmt.Printf("service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
+				fmt.Fprintf(progress, "service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
 			}
 
 			// non-interactive environment
-			for update := range buildUpdates {
+			for update := range allBuildUpdates {
 				for _, line := range strings.Split(strings.TrimSuffix(update.Message, "\n"), "\n") {
-					fmt.Printf("%s [%s]: %s\n", update.ServiceName, update.Status, line)
+					fmt.Fprintf(progress, "%s [%s]: %s\n", update.ServiceName, update.Status, line)
 				}
 			}
 		} else {
-			prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"))
+			prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"), tea.WithOutput(progress))
 			// blocks but quits once the above updates channel is closed by the build process
 			buildModel, err := prog.Run()
 			tui.CheckErr(err)
@@ -91,39 +148,68 @@ var specCmd = &cobra.Command{
 				tui.CheckErr(fmt.Errorf("error building services"))
 			}
 		}
+	}
 
-		// Step 2. Start the collectors and containers (respectively in pairs)
-		// Step 3. Merge requirements from collectors into a specification
-		serviceRequirements, err := proj.CollectServicesRequirements()
+	// Step 2. Start the collectors and containers (respectively in pairs)
+	// Step 3. Merge requirements from collectors into a specification
+	var (
+		serviceRequirements []*collector.ServiceRequirements
+		batchRequirements   []*collector.BatchRequirements
+	)
+
+	if collectOnHost {
+		serviceRequirements, err = proj.CollectServicesRequirementsWithCommand()
 		tui.CheckErr(err)
 
-		batchRequirements, err := proj.CollectBatchRequirements()
+		batchRequirements, err = proj.CollectBatchRequirementsWithCommand()
+		tui.CheckErr(err)
+	} else {
+		serviceRequirements, err = proj.CollectServicesRequirements()
 		tui.CheckErr(err)
 
-		additionalEnvFiles := []string{}
+		batchRequirements, err = proj.CollectBatchRequirements()
+		tui.CheckErr(err)
+	}
 
-		if debugEnvFile != "" {
-			additionalEnvFiles = append(additionalEnvFiles, envFile)
-		}
+	additionalEnvFiles := []string{}
 
-		envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
-		if err != nil && os.IsNotExist(err) {
-			if !os.IsNotExist(err) {
-				tui.CheckErr(err)
-			}
-			// If it doesn't exist set blank
-			envVariables = map[string]string{}
+	if debugEnvFile != "" {
+		additionalEnvFiles = append(additionalEnvFiles, envFile)
+	}
+
+	envVariables, err := env.ReadLocalEnv(additionalEnvFiles...)
+	if err != nil && os.IsNotExist(err) {
+		if !os.IsNotExist(err) {
+			tui.CheckErr(err)
 		}
+		// If it doesn't exist set blank
+		envVariables = map[string]string{}
+	}
 
-		spec, err := collector.ServiceRequirementsToSpec(proj.Name, envVariables, serviceRequirements, batchRequirements)
-		tui.CheckErr(err)
+	spec, err := collector.ServiceRequirementsToSpec(proj.Name, envVariables, serviceRequirements, batchRequirements)
+	tui.CheckErr(err)
+
+	return proj, serviceRequirements, batchRequirements, spec
+}
+
+var specCmd = &cobra.Command{
+	Use:   "spec",
+	Short: "Output the nitric application cloud spec.",
+	Long:  `Output the nitric application cloud spec.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		proj, serviceRequirements, batchRequirements, spec := collectDebugSpec(fs, os.Stdout)
 
 		migrationImageContexts, err := collector.GetMigrationImageBuildContexts(serviceRequirements, batchRequirements, fs)
 		tui.CheckErr(err)
 		// Build images from contexts and provide updates on the builds
 
 		if len(migrationImageContexts) > 0 {
-			migrationBuildUpdates, err := project.BuildMigrationImages(fs, migrationImageContexts, !noBuilder)
+			builder, err := proj.ImageBuilder()
+			tui.CheckErr(err)
+
+			migrationBuildUpdates, err := project.BuildMigrationImages(fs, builder, migrationImageContexts, !noBuilder)
 			tui.CheckErr(err)
 
 			if isNonInteractive() {
@@ -154,31 +240,172 @@ var specCmd = &cobra.Command{
 			outputFile = "./nitric-spec.json"
 		}
 
-		marshaler := protojson.MarshalOptions{
-			Multiline: true,
-			Indent:    "  ",
+		// output the spec
+		err = writeSpecFile(fs, outputFile, spec)
+		tui.CheckErr(err)
+
+		fmt.Printf("Successfully outputted deployment spec to %s\n", outputFile)
+	},
+	Aliases: []string{"spec"},
+}
+
+var specDiffCmd = &cobra.Command{
+	Use:   "diff [baseline] [spec]",
+	Short: "Compare nitric application cloud specs.",
+	Long: `Compare nitric application cloud specs, listing the resources, policies and environment variables that were added, removed or changed.
+
+With two arguments the spec files are compared, with one the baseline file is compared to the current project.
+With no arguments the current project is compared to the spec last deployed to the stack with nitric up.
+
+Exits with a non-zero status when there are changes.`,
+	Example: `# Compare two spec files
+nitric debug spec diff old-spec.json nitric-spec.json
+
+# Compare the current project to the last deployment of the dev stack
+nitric debug spec diff -s dev
+
+# Output the changes as JSON
+nitric debug spec diff -s dev --format json`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		if len(args) == 0 && debugStack == "" {
+			tui.CheckErr(fmt.Errorf("a stack is required to compare against when no spec files are provided"))
+		}
+
+		if debugSaveBaseline && (debugStack == "" || len(args) == 2) {
+			tui.CheckErr(fmt.Errorf("--save-baseline requires a stack and compares the current project"))
+		}
+
+		var baselineFile string
+
+		switch {
+		case len(args) > 0:
+			baselineFile = args[0]
+		default:
+			baselineFile = paths.NitricSpecBaselineFile(".", debugStack)
+		}
+
+		baseline, err := readSpecFile(fs, baselineFile)
+		if errors.Is(err, os.ErrNotExist) && debugSaveBaseline {
+			// compare against an empty spec when creating the first baseline
+			baseline, err = &deploymentspb.Spec{}, nil
+		} else if errors.Is(err, os.ErrNotExist) && len(args) == 0 {
+			err = fmt.Errorf("no baseline found for stack %s, deploy it with nitric up or create one with --save-baseline", debugStack)
 		}
 
-		specJson, err := marshaler.Marshal(spec)
 		tui.CheckErr(err)
 
-		// output the spec
-		err = os.WriteFile(outputFile, specJson, 0o644)
+		var spec *deploymentspb.Spec
+
+		if len(args) == 2 {
+			spec, err = readSpecFile(fs, args[1])
+			tui.CheckErr(err)
+		} else {
+			// build progress is kept off stdout, which the diff is written to
+			_, _, _, spec = collectDebugSpec(fs, os.Stderr)
+		}
+
+		diff, err := collector.DiffSpecs(baseline, spec)
 		tui.CheckErr(err)
 
-		fmt.Printf("Successfully outputted deployment spec to %s\n", outputFile)
+		switch debugDiffFormat {
+		case "text":
+			fmt.Print(diff.String())
+		case "json":
+			diffJson, err := json.MarshalIndent(diff, "", "  ")
+			tui.CheckErr(err)
+
+			fmt.Println(string(diffJson))
+		default:
+			tui.CheckErr(fmt.Errorf("unsupported diff format %s, must be one of text or json", debugDiffFormat))
+		}
+
+		if debugSaveBaseline {
+			err = writeSpecFile(fs, paths.NitricSpecBaselineFile(".", debugStack), spec)
+			tui.CheckErr(err)
+		}
+
+		if diff.HasChanges() {
+			os.Exit(1)
+		}
+	},
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Output a graph of the nitric application's resources.",
+	Long:  `Output a graph of the nitric application's resources, the triggers between them and the policies granting services access to them, in DOT, Mermaid or JSON format.`,
+	Example: `# Render an architecture diagram with Graphviz
+nitric debug graph --format dot -o graph.dot && dot -Tsvg graph.dot -o graph.svg
+
+# Output a Mermaid flowchart for a pull request description
+nitric debug graph --format mermaid`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		fs := afero.NewOsFs()
+
+		// build progress is kept off stdout, which the graph is written to by default
+		_, _, _, spec := collectDebugSpec(fs, os.Stderr)
+
+		graph, err := collector.SpecToGraph(spec)
+		tui.CheckErr(err)
+
+		var output string
+
+		switch debugGraphFormat {
+		case "dot":
+			output = graph.Dot()
+		case "mermaid":
+			output = graph.Mermaid()
+		case "json":
+			graphJson, err := json.MarshalIndent(graph, "", "  ")
+			tui.CheckErr(err)
+
+			output = string(graphJson) + "\n"
+		default:
+			tui.CheckErr(fmt.Errorf("unsupported graph format %s, must be one of dot, mermaid or json", debugGraphFormat))
+		}
+
+		if debugFile == "" {
+			fmt.Print(output)
+			return
+		}
+
+		err = os.WriteFile(debugFile, []byte(output), 0o644)
+		tui.CheckErr(err)
+
+		fmt.Printf("Successfully outputted resource graph to %s\n", debugFile)
 	},
-	Aliases: []string{"spec"},
 }
 
 func init() {
 	specCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
 	specCmd.Flags().StringVarP(&debugFile, "output", "o", "", "--file my-example-spec.json")
 	specCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	specCmd.Flags().BoolVar(&debugCollectOnHost, "collect-on-host", false, "collect requirements by running services with their start command from nitric.yaml, instead of building containers")
+
+	specDiffCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	specDiffCmd.Flags().StringVarP(&debugStack, "stack", "s", "", "the stack to compare the current project against")
+	specDiffCmd.Flags().StringVarP(&debugDiffFormat, "format", "f", "text", "the output format, one of text or json")
+	specDiffCmd.Flags().BoolVar(&debugSaveBaseline, "save-baseline", false, "save the current project's spec as the stack's baseline")
+	specDiffCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	specDiffCmd.Flags().BoolVar(&debugCollectOnHost, "collect-on-host", false, "collect requirements by running services with their start command from nitric.yaml, instead of building containers")
+	specCmd.AddCommand(specDiffCmd)
 
 	// Debug spec
 	debugCmd.AddCommand(specCmd)
 
+	graphCmd.Flags().StringVarP(&debugEnvFile, "env-file", "e", "", "--env-file config/.my-env")
+	graphCmd.Flags().StringVarP(&debugFile, "output", "o", "", "--output graph.dot, defaults to stdout")
+	graphCmd.Flags().StringVarP(&debugGraphFormat, "format", "f", "dot", "the graph format, one of dot, mermaid or json")
+	graphCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	graphCmd.Flags().BoolVar(&debugCollectOnHost, "collect-on-host", false, "collect requirements by running services with their start command from nitric.yaml, instead of building containers")
+
+	// Debug graph
+	debugCmd.AddCommand(graphCmd)
+
 	// Add Stack Commands
 	rootCmd.AddCommand(debugCmd)
 
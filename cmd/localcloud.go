@@ -0,0 +1,113 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/cloud/sql"
+	"github.com/nitrictech/cli/pkg/project"
+	"github.com/nitrictech/cli/pkg/system"
+	"github.com/nitrictech/cli/pkg/view/tui"
+)
+
+// externalSqlServer returns the postgres server set by NITRIC_SQL_HOST and NITRIC_SQL_PORT, if any
+func externalSqlServer() (*sql.ExternalServer, error) {
+	host := os.Getenv("NITRIC_SQL_HOST")
+	if host == "" {
+		return nil, nil
+	}
+
+	port := 5432
+
+	if portEnv := os.Getenv("NITRIC_SQL_PORT"); portEnv != "" {
+		var err error
+
+		port, err = strconv.Atoi(portEnv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NITRIC_SQL_PORT %s: %w", portEnv, err)
+		}
+	}
+
+	return &sql.ExternalServer{Host: host, Port: port}, nil
+}
+
+var localCloudCmd = &cobra.Command{
+	Use:    "local-cloud",
+	Short:  "Run the local cloud without running your project's services",
+	Long:   `Run the local cloud headless, serving each service and batch on a fixed port so they can be run separately, e.g. by the file from nitric run --export-compose`,
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fs := afero.NewOsFs()
+
+		proj, err := project.FromFile(fs, "")
+		tui.CheckErr(err)
+
+		externalSql, err := externalSqlServer()
+		tui.CheckErr(err)
+
+		system.SubscribeToLogs(func(msg string) {
+			fmt.Print(msg)
+		})
+
+		localCloud, err := cloud.New(proj.Name, cloud.LocalCloudOptions{
+			LogWriter:       os.Stdout,
+			LocalConfig:     proj.LocalConfig,
+			MigrationRunner: project.RejectMigrations,
+			LocalCloudMode:  cloud.LocalCloudModeRun,
+			ExternalSql:     externalSql,
+		})
+		tui.CheckErr(err)
+
+		defer localCloud.Stop()
+
+		err = proj.RegisterHeadlessServers(localCloud)
+		tui.CheckErr(err)
+
+		ports := proj.HeadlessServerPorts()
+		paths := lo.Keys(ports)
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			fmt.Printf("serving %s on port %d\n", path, ports[path])
+		}
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+		<-sigChan
+
+		fmt.Println("Stopping local cloud")
+
+		return nil
+	},
+	Args: cobra.ExactArgs(0),
+}
+
+func init() {
+	rootCmd.AddCommand(localCloudCmd)
+}
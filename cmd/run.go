@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -44,13 +46,73 @@ import (
 	"github.com/nitrictech/cli/pkg/view/tui/teax"
 )
 
-var runNoBrowser bool
+var (
+	runNoBrowser     bool
+	runExportCompose string
+)
+
+// defaultComposeFile is written by --export-compose when no path is given
+const defaultComposeFile = "compose.nitric.yaml"
+
+// buildRunImages builds the project's service and batch images for the local container engine
+func buildRunImages(fs afero.Fs, proj *project.Project) {
+	builder, err := proj.ImageBuilder()
+	tui.CheckErr(err)
+
+	// services are run in containers, so their images must be loaded into the local container engine
+	if builder.Daemonless() {
+		tui.CheckErr(fmt.Errorf("services are run in containers, which daemonless builders don't load images into, use the %s builder to run the project locally", docker.BuilderDocker))
+	}
+
+	updates, err := proj.BuildServices(fs, builder, !noBuilder, []string{docker.NativePlatform()})
+	tui.CheckErr(err)
+
+	batchBuildUpdates, err := proj.BuildBatches(fs, builder, !noBuilder, []string{docker.NativePlatform()})
+	tui.CheckErr(err)
+
+	allBuildUpdates := lo.FanIn(10, updates, batchBuildUpdates)
+
+	if isNonInteractive() {
+		fmt.Println("building project services")
+		for _, service := range proj.GetServices() {
+			fmt.Printf("service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
+		}
+
+		buildFailed := false
+
+		// non-interactive environment
+		for update := range allBuildUpdates {
+			if update.Status == project.ServiceBuildStatus_Error {
+				buildFailed = true
+			}
+
+			for _, line := range strings.Split(strings.TrimSuffix(update.Message, "\n"), "\n") {
+				fmt.Printf("%s [%s]: %s\n", update.ServiceName, update.Status, line)
+			}
+		}
+
+		if buildFailed {
+			tui.CheckErr(fmt.Errorf("error building services"))
+		}
+	} else {
+		prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"))
+		// blocks but quits once the above updates channel is closed by the build process
+		buildModel, err := prog.Run()
+		tui.CheckErr(err)
+		if buildModel.(build.Model).Err != nil {
+			tui.CheckErr(fmt.Errorf("error building services"))
+		}
+	}
+}
 
 var runCmd = &cobra.Command{
-	Use:         "run",
-	Short:       "Run your project locally for development and testing",
-	Long:        `Run your project locally for development and testing`,
-	Example:     `nitric run`,
+	Use:   "run",
+	Short: "Run your project locally for development and testing",
+	Long:  `Run your project locally for development and testing`,
+	Example: `nitric run
+
+# Write a docker compose file for the project instead of running it
+nitric run --export-compose`,
 	Annotations: map[string]string{"commonCommand": "yes"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		err := docker.VerifyDockerIsAvailable()
@@ -72,6 +134,27 @@ var runCmd = &cobra.Command{
 			tui.CheckErr(err)
 		}
 
+		if runExportCompose != "" {
+			buildRunImages(fs, proj)
+
+			compose, err := proj.Compose(filepath.Dir(runExportCompose), loadEnv)
+			tui.CheckErr(err)
+
+			err = project.WriteComposeFile(fs, compose, runExportCompose)
+			tui.CheckErr(err)
+
+			fmt.Printf("wrote %s, run it with: docker compose -f %s up\n", runExportCompose, runExportCompose)
+
+			// gateways are started on random ports unless they're pinned, so compose can't publish them to the host
+			if ports := proj.GatewayPorts(); len(ports) == 0 {
+				tui.Warning.Println("no gateway ports are set in local.nitric.yaml, so APIs, websockets, http proxies and the ingress won't be reachable from the host, set their ports to publish them")
+			} else {
+				tui.Warning.Println(fmt.Sprintf("only the gateway ports set in local.nitric.yaml are published (%s), APIs, websockets and http proxies without a port won't be reachable from the host", strings.Join(lo.Map(ports, func(port int, _ int) string { return strconv.Itoa(port) }), ", ")))
+			}
+
+			return nil
+		}
+
 		var tlsCredentials *gateway.TLSCredentials
 		if enableHttps {
 			createTlsCredentialsIfNotPresent(fs, proj.Directory)
@@ -105,7 +188,7 @@ var runCmd = &cobra.Command{
 				TLSCredentials:  tlsCredentials,
 				LogWriter:       logWriter,
 				LocalConfig:     proj.LocalConfig,
-				MigrationRunner: project.BuildAndRunMigrations,
+				MigrationRunner: proj.MigrationRunner(),
 				LocalCloudMode:  cloud.LocalCloudModeRun,
 			})
 			tui.CheckErr(err)
@@ -122,32 +205,7 @@ var runCmd = &cobra.Command{
 		err = dash.Start()
 		tui.CheckErr(err)
 
-		updates, err := proj.BuildServices(fs, !noBuilder)
-		tui.CheckErr(err)
-
-		batchBuildUpdates, err := proj.BuildBatches(fs, !noBuilder)
-		tui.CheckErr(err)
-
-		allBuildUpdates := lo.FanIn(10, updates, batchBuildUpdates)
-
-		if isNonInteractive() {
-			fmt.Println("building project services")
-			for _, service := range proj.GetServices() {
-				fmt.Printf("service matched '%s', auto-naming this service '%s'\n", service.GetFilePath(), service.Name)
-			}
-
-			// non-interactive environment
-			for update := range allBuildUpdates {
-				for _, line := range strings.Split(strings.TrimSuffix(update.Message, "\n"), "\n") {
-					fmt.Printf("%s [%s]: %s\n", update.ServiceName, update.Status, line)
-				}
-			}
-		} else {
-			prog := teax.NewProgram(build.NewModel(allBuildUpdates, "Building Services"))
-			// blocks but quits once the above updates channel is closed by the build process
-			_, err = prog.Run()
-			tui.CheckErr(err)
-		}
+		buildRunImages(fs, proj)
 
 		// Run the app code (project services)
 		stopChan := make(chan bool)
@@ -220,7 +278,7 @@ var runCmd = &cobra.Command{
 					// Write log to file
 					level := logrus.InfoLevel
 
-					if update.Status == project.ServiceRunStatus_Error {
+					if update.Status.Failed() {
 						level = logrus.ErrorLevel
 					}
 
@@ -231,7 +289,7 @@ var runCmd = &cobra.Command{
 				}
 			}
 		} else {
-			runView := teax.NewProgram(services.NewModel(stopChan, allUpdates, localCloud, dash.GetDashboardUrl()))
+			runView := teax.NewProgram(services.NewModel(stopChan, allUpdates, localCloud, proj.RunStates(), dash.GetDashboardUrl()))
 
 			_, _ = runView.Run()
 
@@ -247,6 +305,8 @@ func init() {
 	runCmd.Flags().StringVarP(&envFile, "env-file", "e", "", "--env-file config/.my-env")
 	runCmd.Flags().BoolVar(&enableHttps, "https-preview", false, "enable https support for local APIs (preview feature)")
 	runCmd.Flags().BoolVar(&noBuilder, "no-builder", false, "don't create a buildx container")
+	runCmd.Flags().StringVar(&runExportCompose, "export-compose", "", "build the services and write a docker compose file that runs them against a headless local cloud, instead of running them. Databases with migrations aren't supported, as the headless local cloud can't build or run migration images")
+	runCmd.Flags().Lookup("export-compose").NoOptDefVal = defaultComposeFile
 	runCmd.PersistentFlags().BoolVar(
 		&runNoBrowser,
 		"no-browser",
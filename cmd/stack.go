@@ -29,7 +29,9 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/docker"
 	"github.com/nitrictech/cli/pkg/env"
+	"github.com/nitrictech/cli/pkg/paths"
 	"github.com/nitrictech/cli/pkg/pflagx"
 	"github.com/nitrictech/cli/pkg/preview"
 	"github.com/nitrictech/cli/pkg/project"
@@ -56,6 +58,7 @@ var (
 	noBuilder     bool
 	forceNewStack bool
 	envFile       string
+	collectOnHost bool
 )
 
 var stackCmd = &cobra.Command{
@@ -182,10 +185,21 @@ var stackUpdateCmd = &cobra.Command{
 		tui.CheckErr(err)
 
 		// Build the Project's Services (Containers)
-		buildUpdates, err := proj.BuildServices(fs, !noBuilder)
+		platforms, err := stack.Platforms(stackConfig.Config)
 		tui.CheckErr(err)
 
-		batchBuildUpdates, err := proj.BuildBatches(fs, !noBuilder)
+		builder, err := proj.ImageBuilder()
+		tui.CheckErr(err)
+
+		// providers deploy the images from the local container engine, so images only written to OCI archives would never be deployed
+		if builder.Daemonless() {
+			tui.CheckErr(fmt.Errorf("stack update deploys images from the local container engine, which daemonless builders don't load images into, use the %s builder to update stacks", docker.BuilderDocker))
+		}
+
+		buildUpdates, err := proj.BuildServices(fs, builder, !noBuilder, platforms)
+		tui.CheckErr(err)
+
+		batchBuildUpdates, err := proj.BuildBatches(fs, builder, !noBuilder, platforms)
 		tui.CheckErr(err)
 
 		allBuildUpdates := lo.FanIn(10, buildUpdates, batchBuildUpdates)
@@ -214,11 +228,24 @@ var stackUpdateCmd = &cobra.Command{
 
 		// Step 2. Start the collectors and containers (respectively in pairs)
 		// Step 3. Merge requirements from collectors into a specification
-		serviceRequirements, err := proj.CollectServicesRequirements()
-		tui.CheckErr(err)
+		var (
+			serviceRequirements []*collector.ServiceRequirements
+			batchRequirements   []*collector.BatchRequirements
+		)
 
-		batchRequirements, err := proj.CollectBatchRequirements()
-		tui.CheckErr(err)
+		if collectOnHost {
+			serviceRequirements, err = proj.CollectServicesRequirementsWithCommand()
+			tui.CheckErr(err)
+
+			batchRequirements, err = proj.CollectBatchRequirementsWithCommand()
+			tui.CheckErr(err)
+		} else {
+			serviceRequirements, err = proj.CollectServicesRequirements()
+			tui.CheckErr(err)
+
+			batchRequirements, err = proj.CollectBatchRequirements()
+			tui.CheckErr(err)
+		}
 
 		additionalEnvFiles := []string{}
 
@@ -248,7 +275,7 @@ var stackUpdateCmd = &cobra.Command{
 		// Build images from contexts and provide updates on the builds
 
 		if len(migrationImageContexts) > 0 {
-			migrationBuildUpdates, err := project.BuildMigrationImages(fs, migrationImageContexts, !noBuilder)
+			migrationBuildUpdates, err := project.BuildMigrationImages(fs, builder, migrationImageContexts, !noBuilder)
 			tui.CheckErr(err)
 
 			if isNonInteractive() {
@@ -364,8 +391,18 @@ var stackUpdateCmd = &cobra.Command{
 			// interactive environment
 			// Step 5c. Start the stack up view
 			stackUp := stack_up.New(stackConfig.Provider, stackConfig.Name, eventChan, providerStdout, errorChan)
-			_, err = teax.NewProgram(stackUp).Run()
+			upModel, err := teax.NewProgram(stackUp).Run()
 			tui.CheckErr(err)
+
+			if !upModel.(stack_up.Model).Deployed() {
+				return
+			}
+		}
+
+		// Step 6. Keep the deployed spec as a baseline for nitric debug spec diff
+		err = writeSpecFile(fs, paths.NitricSpecBaselineFile(proj.Directory, stackConfig.Name), spec)
+		if err != nil {
+			tui.Error.Printfln("unable to save deployed spec baseline: %s", err)
 		}
 	},
 	Args:    cobra.MinimumNArgs(0),
@@ -635,6 +672,7 @@ func init() {
 	stackUpdateCmd.Flags().BoolVarP(&noBuilder, "no-builder", "", false, "don't create a buildx container")
 	stackUpdateCmd.Flags().StringVarP(&envFile, "env-file", "e", "", "--env-file config/.my-env")
 	stackUpdateCmd.Flags().BoolVarP(&forceStack, "force", "f", false, "force override previous deployment")
+	stackUpdateCmd.Flags().BoolVar(&collectOnHost, "collect-on-host", false, "collect requirements by running services with their start command from nitric.yaml, instead of running their built containers")
 	tui.CheckErr(AddOptions(stackUpdateCmd, false))
 
 	// Delete Stack (Down)
@@ -185,7 +185,7 @@ var startCmd = &cobra.Command{
 				TLSCredentials:  tlsCredentials,
 				LogWriter:       logWriter,
 				LocalConfig:     proj.LocalConfig,
-				MigrationRunner: project.BuildAndRunMigrations,
+				MigrationRunner: proj.MigrationRunner(),
 				LocalCloudMode:  cloud.LocalCloudModeStart,
 			})
 			tui.CheckErr(err)
@@ -278,7 +278,7 @@ var startCmd = &cobra.Command{
 					// Write log to file
 					level := logrus.InfoLevel
 
-					if update.Status == project.ServiceRunStatus_Error {
+					if update.Status.Failed() {
 						level = logrus.ErrorLevel
 					}
 
@@ -290,7 +290,7 @@ var startCmd = &cobra.Command{
 			}
 		} else {
 			// interactive environment
-			runView := teax.NewProgram(services.NewModel(stopChan, allUpdates, localCloud, dash.GetDashboardUrl()))
+			runView := teax.NewProgram(services.NewModel(stopChan, allUpdates, localCloud, proj.RunStates(), dash.GetDashboardUrl()))
 
 			_, err = runView.Run()
 			tui.CheckErr(err)
@@ -40,9 +40,12 @@ type (
 type GetApiAddress = func(apiName string) string
 
 type ApiRequestState struct {
-	Api      string
-	ReqCtx   *fasthttp.RequestCtx
-	HttpResp *apispb.HttpResponse
+	Api        string
+	ReqCtx     *fasthttp.RequestCtx
+	HttpResp   *apispb.HttpResponse
+	PathParams map[string]string
+	// ValidationErrors lists violations of the API's OpenAPI document, when validation is enabled
+	ValidationErrors []string
 }
 type LocalApiGatewayService struct {
 	*apis.RouteWorkerManager
@@ -0,0 +1,145 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apis
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+)
+
+var (
+	ErrRouteNotFound    = errors.New("route not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+type RouteMatch struct {
+	Registration *apispb.RegistrationRequest
+	PathParams   map[string]string
+	// AllowedMethods lists the methods registered for the matched path, used for 405 responses
+	AllowedMethods []string
+}
+
+func splitPath(path string) []string {
+	return lo.Filter(strings.Split(path, "/"), func(segment string, _ int) bool {
+		return segment != ""
+	})
+}
+
+// matchPath extracts the path params of a request path if it matches the registered route path, path params are prefixed with ':'.
+// The request path must still be percent-encoded, each segment is decoded once after splitting so encoded slashes remain part of a param.
+func matchPath(routePath string, requestPath string) (map[string]string, bool) {
+	routeSegments := splitPath(routePath)
+	requestSegments := splitPath(requestPath)
+
+	if len(routeSegments) != len(requestSegments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+
+	for i, segment := range routeSegments {
+		value, err := url.PathUnescape(requestSegments[i])
+		if err != nil {
+			value = requestSegments[i]
+		}
+
+		if paramName, isParam := strings.CutPrefix(segment, ":"); isParam {
+			params[paramName] = value
+
+			continue
+		}
+
+		if segment != value {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// compareSpecificity orders routes so that static segments take precedence over path params, left to right.
+// e.g. /users/me is preferred over /users/:id, matching the behavior of deployed API gateways.
+func compareSpecificity(a string, b string) int {
+	aSegments := splitPath(a)
+	bSegments := splitPath(b)
+
+	for i := range aSegments {
+		aParam := strings.HasPrefix(aSegments[i], ":")
+		bParam := strings.HasPrefix(bSegments[i], ":")
+
+		if aParam != bParam {
+			if aParam {
+				return 1
+			}
+
+			return -1
+		}
+	}
+
+	return 0
+}
+
+// MatchRoute finds the registered route that will handle the given method and path for an API, path is the original percent-encoded request path.
+// Returns ErrRouteNotFound if no route matches the path and ErrMethodNotAllowed if the path matches but the method doesn't.
+func MatchRoute(registrations map[ServiceName][]*apispb.RegistrationRequest, method string, path string) (*RouteMatch, error) {
+	candidates := []*RouteMatch{}
+
+	for _, serviceRegistrations := range registrations {
+		for _, registration := range serviceRegistrations {
+			params, ok := matchPath(registration.Path, path)
+			if !ok {
+				continue
+			}
+
+			candidates = append(candidates, &RouteMatch{
+				Registration: registration,
+				PathParams:   params,
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrRouteNotFound
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *RouteMatch) int {
+		return compareSpecificity(a.Registration.Path, b.Registration.Path)
+	})
+
+	allowedMethods := lo.Uniq(lo.FlatMap(candidates, func(candidate *RouteMatch, _ int) []string {
+		return candidate.Registration.Methods
+	}))
+
+	slices.Sort(allowedMethods)
+
+	for _, candidate := range candidates {
+		if lo.Contains(candidate.Registration.Methods, strings.ToUpper(method)) {
+			candidate.AllowedMethods = allowedMethods
+
+			return candidate, nil
+		}
+	}
+
+	// the path params of the most specific route are still returned, so the request can be forwarded if the caller chooses to
+	return &RouteMatch{PathParams: candidates[0].PathParams, AllowedMethods: allowedMethods}, ErrMethodNotAllowed
+}
@@ -0,0 +1,142 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+)
+
+func TestMatchRoute(t *testing.T) {
+	registrations := map[ServiceName][]*apispb.RegistrationRequest{
+		"services-users": {
+			{Api: "main", Path: "/users/:id", Methods: []string{"GET", "DELETE"}},
+			{Api: "main", Path: "/users/me", Methods: []string{"GET"}},
+			{Api: "main", Path: "/users", Methods: []string{"POST"}},
+		},
+		"services-orders": {
+			{Api: "main", Path: "/users/:userId/orders/:orderId", Methods: []string{"GET"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		method         string
+		path           string
+		expectedPath   string
+		expectedParams map[string]string
+		expectedErr    error
+		expectedAllow  []string
+	}{
+		{
+			name:           "static route",
+			method:         "POST",
+			path:           "/users",
+			expectedPath:   "/users",
+			expectedParams: map[string]string{},
+		},
+		{
+			name:           "path params",
+			method:         "GET",
+			path:           "/users/123",
+			expectedPath:   "/users/:id",
+			expectedParams: map[string]string{"id": "123"},
+		},
+		{
+			name:           "static segments take precedence over params",
+			method:         "GET",
+			path:           "/users/me",
+			expectedPath:   "/users/me",
+			expectedParams: map[string]string{},
+		},
+		{
+			name:           "falls back to param route for unsupported method on static route",
+			method:         "DELETE",
+			path:           "/users/me",
+			expectedPath:   "/users/:id",
+			expectedParams: map[string]string{"id": "me"},
+		},
+		{
+			name:           "multiple params are decoded",
+			method:         "GET",
+			path:           "/users/a%20b/orders/42/",
+			expectedPath:   "/users/:userId/orders/:orderId",
+			expectedParams: map[string]string{"userId": "a b", "orderId": "42"},
+		},
+		{
+			name:           "params are decoded exactly once",
+			method:         "GET",
+			path:           "/users/100%2525",
+			expectedPath:   "/users/:id",
+			expectedParams: map[string]string{"id": "100%25"},
+		},
+		{
+			name:           "encoded slashes remain part of a param",
+			method:         "GET",
+			path:           "/users/a%2Fb",
+			expectedPath:   "/users/:id",
+			expectedParams: map[string]string{"id": "a/b"},
+		},
+		{
+			name:        "unknown path",
+			method:      "GET",
+			path:        "/products",
+			expectedErr: ErrRouteNotFound,
+		},
+		{
+			name:          "unsupported method",
+			method:        "PUT",
+			path:          "/users/123",
+			expectedErr:   ErrMethodNotAllowed,
+			expectedAllow: []string{"DELETE", "GET"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := MatchRoute(registrations, tt.method, tt.path)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+
+				if tt.expectedAllow != nil {
+					if diff := cmp.Diff(tt.expectedAllow, match.AllowedMethods); diff != "" {
+						t.Errorf("unexpected allowed methods (-want +got):\n%s", diff)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if match.Registration.Path != tt.expectedPath {
+				t.Errorf("expected route %s, got %s", tt.expectedPath, match.Registration.Path)
+			}
+
+			if diff := cmp.Diff(tt.expectedParams, match.PathParams); diff != "" {
+				t.Errorf("unexpected path params (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
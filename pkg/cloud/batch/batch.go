@@ -20,11 +20,17 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"runtime"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/asaskevich/EventBus"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
 
 	"github.com/nitrictech/cli/pkg/grpcx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
 	"github.com/nitrictech/nitric/core/pkg/logger"
 	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
 	"github.com/nitrictech/nitric/core/pkg/workers/jobs"
@@ -37,10 +43,65 @@ type (
 	serviceName = string
 )
 
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// ActionState is published each time a job run changes status
 type ActionState struct {
-	JobName string
-	Payload string
-	Success bool
+	JobId      string
+	JobName    string
+	Payload    string
+	Status     JobStatus
+	Success    bool
+	Error      string
+	QueuedAt   time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// ExitCode, StopStatus and Logs are only captured for isolated job runs
+	ExitCode   *int
+	StopStatus *int
+	Logs       string
+}
+
+// Duration returns how long the job has been running for, or ran for if it has finished
+func (a ActionState) Duration() time.Duration {
+	if a.StartedAt.IsZero() {
+		return 0
+	}
+
+	if a.FinishedAt.IsZero() {
+		return time.Since(a.StartedAt)
+	}
+
+	return a.FinishedAt.Sub(a.StartedAt)
+}
+
+type queuedJob struct {
+	state ActionState
+	data  *batchpb.JobData
+}
+
+// IsolatedJobResult is the outcome of a job run in its own container
+type IsolatedJobResult struct {
+	// ExitCode is the job's own exit status, 0 when its handler succeeded
+	ExitCode int
+	// StopStatus is the container's exit status once it was stopped after the job completed, if it had to be stopped
+	StopStatus *int
+	Logs       string
+}
+
+// IsolatedJobRunner runs a job in a fresh environment, rather than with the long running worker of the batch service
+type IsolatedJobRunner func(serviceName string, req *batchpb.JobRequest, requirements *batchpb.JobResourceRequirements) (*IsolatedJobResult, error)
+
+type jobQueue struct {
+	pending []*queuedJob
+	running int
 }
 
 type (
@@ -49,8 +110,15 @@ type (
 		*jobs.JobManager
 		batchpb.UnimplementedBatchServer
 
-		state     State
-		batchLock sync.RWMutex
+		state        State
+		requirements map[jobName]*batchpb.JobResourceRequirements
+		batchLock    sync.RWMutex
+
+		config    localconfig.LocalBatchConfiguration
+		queues    map[jobName]*jobQueue
+		queueLock sync.Mutex
+
+		isolatedRunner IsolatedJobRunner
 
 		bus EventBus.Bus
 	}
@@ -98,6 +166,10 @@ func (l *LocalBatchService) registerJob(serviceName string, registration *batchp
 
 	l.state[registration.JobName][serviceName]++
 
+	if registration.GetRequirements() != nil {
+		l.requirements[registration.JobName] = registration.GetRequirements()
+	}
+
 	l.publishState()
 }
 
@@ -152,47 +224,179 @@ func (l *LocalBatchService) HandleJob(stream batchpb.Job_HandleJobServer) error
 	return l.JobManager.HandleJob(peekableStream)
 }
 
-func (l *LocalBatchService) SubmitJob(ctx context.Context, req *batchpb.JobSubmitRequest) (*batchpb.JobSubmitResponse, error) {
-	go func() {
-		json, err := req.Data.GetStruct().MarshalJSON()
-		if err != nil {
-			logger.Errorf("Error marshalling job request data: %s", err.Error())
-		}
+// parallelism returns the number of runs of a job allowed at once, limited by the CPUs the job requires
+func (l *LocalBatchService) parallelism(jobName string) int {
+	l.batchLock.RLock()
+	requirements := l.requirements[jobName]
+	l.batchLock.RUnlock()
+
+	capacity := runtime.NumCPU()
+	if requirements.GetCpus() > 0 {
+		capacity = max(1, int(float32(runtime.NumCPU())/requirements.GetCpus()))
+	}
+
+	configured := l.config.Parallelism
+	if job, ok := l.config.Jobs[jobName]; ok && job.Parallelism > 0 {
+		configured = job.Parallelism
+	}
+
+	if configured <= 0 {
+		return capacity
+	}
+
+	return min(configured, capacity)
+}
+
+// Submit queues a job run, returning its ID
+func (l *LocalBatchService) Submit(req *batchpb.JobSubmitRequest) (string, error) {
+	json, err := req.Data.GetStruct().MarshalJSON()
+	if err != nil {
+		return "", fmt.Errorf("error marshalling job request data: %w", err)
+	}
+
+	job := &queuedJob{
+		state: ActionState{
+			JobId:    uuid.NewString(),
+			JobName:  req.GetJobName(),
+			Payload:  string(json),
+			Status:   JobQueued,
+			QueuedAt: time.Now(),
+		},
+		data: req.GetData(),
+	}
+
+	l.queueLock.Lock()
+
+	if l.queues[req.GetJobName()] == nil {
+		l.queues[req.GetJobName()] = &jobQueue{}
+	}
+
+	l.queues[req.GetJobName()].pending = append(l.queues[req.GetJobName()].pending, job)
+	l.queueLock.Unlock()
+
+	l.publishAction(job.state)
+
+	l.dispatch(req.GetJobName())
+
+	return job.state.JobId, nil
+}
 
+// dispatch starts queued runs of a job until its parallelism is reached
+func (l *LocalBatchService) dispatch(jobName string) {
+	parallelism := l.parallelism(jobName)
+
+	l.queueLock.Lock()
+	defer l.queueLock.Unlock()
+
+	queue := l.queues[jobName]
+
+	for queue.running < parallelism && len(queue.pending) > 0 {
+		job := queue.pending[0]
+		queue.pending = queue.pending[1:]
+		queue.running++
+
+		go l.run(job)
+	}
+}
+
+func (l *LocalBatchService) run(queued *queuedJob) {
+	job := &queued.state
+
+	job.Status = JobRunning
+	job.StartedAt = time.Now()
+
+	l.publishAction(*job)
+
+	jobRequest := &batchpb.JobRequest{
+		JobName: job.JobName,
+		Data:    queued.data,
+	}
+
+	var err error
+
+	if runner := l.getIsolatedRunner(); runner != nil {
+		err = l.runIsolated(runner, job, jobRequest)
+	} else {
 		_, err = l.HandleJobRequest(&batchpb.ServerMessage{
 			Content: &batchpb.ServerMessage_JobRequest{
-				JobRequest: &batchpb.JobRequest{
-					JobName: req.GetJobName(),
-					Data:    req.Data,
-				},
+				JobRequest: jobRequest,
 			},
 		})
-		if err != nil {
-			logger.Errorf("Error handling job request: %s", err.Error())
-
-			l.publishAction(ActionState{
-				JobName: req.GetJobName(),
-				Success: false,
-				Payload: string(json),
-			})
-
-			return
-		}
-
-		l.publishAction(ActionState{
-			JobName: req.GetJobName(),
-			Success: true,
-			Payload: string(json),
-		})
-	}()
+	}
+
+	job.FinishedAt = time.Now()
+	job.Status = JobSucceeded
+	job.Success = true
+
+	if err != nil {
+		logger.Errorf("Error handling job request: %s", err.Error())
+
+		job.Status = JobFailed
+		job.Success = false
+		job.Error = err.Error()
+	}
+
+	l.publishAction(*job)
+
+	l.queueLock.Lock()
+	l.queues[job.JobName].running--
+	l.queueLock.Unlock()
+
+	l.dispatch(job.JobName)
+}
+
+// SetIsolatedJobRunner runs all future jobs with the given runner, the long running workers are still used to discover which service handles a job
+func (l *LocalBatchService) SetIsolatedJobRunner(runner IsolatedJobRunner) {
+	l.batchLock.Lock()
+	defer l.batchLock.Unlock()
+
+	l.isolatedRunner = runner
+}
+
+func (l *LocalBatchService) getIsolatedRunner() IsolatedJobRunner {
+	l.batchLock.RLock()
+	defer l.batchLock.RUnlock()
+
+	return l.isolatedRunner
+}
+
+func (l *LocalBatchService) runIsolated(runner IsolatedJobRunner, job *ActionState, req *batchpb.JobRequest) error {
+	l.batchLock.RLock()
+	services := lo.Keys(l.state[job.JobName])
+	slices.Sort(services)
+	requirements := l.requirements[job.JobName]
+	l.batchLock.RUnlock()
+
+	if len(services) == 0 {
+		return fmt.Errorf("no worker registered for job: %s", job.JobName)
+	}
+
+	result, err := runner(services[0], req, requirements)
+	if result != nil {
+		job.ExitCode = &result.ExitCode
+		job.StopStatus = result.StopStatus
+		job.Logs = result.Logs
+	}
+
+	return err
+}
+
+func (l *LocalBatchService) SubmitJob(ctx context.Context, req *batchpb.JobSubmitRequest) (*batchpb.JobSubmitResponse, error) {
+	_, err := l.Submit(req)
+	if err != nil {
+		return nil, err
+	}
 
 	return &batchpb.JobSubmitResponse{}, nil
 }
 
-func NewLocalBatchService() *LocalBatchService {
+func NewLocalBatchService(config localconfig.LocalBatchConfiguration) *LocalBatchService {
 	return &LocalBatchService{
-		JobManager: jobs.New(),
-		state:      make(map[string]map[string]int),
-		bus:        EventBus.New(),
+		JobManager:   jobs.New(),
+		state:        make(map[string]map[string]int),
+		requirements: make(map[string]*batchpb.JobResourceRequirements),
+		config:       config,
+		queues:       make(map[string]*jobQueue),
+		bus:          EventBus.New(),
 	}
 }
@@ -0,0 +1,344 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
+)
+
+func TestParallelism(t *testing.T) {
+	cpus := runtime.NumCPU()
+
+	tests := []struct {
+		name         string
+		config       localconfig.LocalBatchConfiguration
+		requirements *batchpb.JobResourceRequirements
+		want         int
+	}{
+		{
+			name: "defaults to the number of CPUs",
+			want: cpus,
+		},
+		{
+			name:   "uses the configured parallelism",
+			config: localconfig.LocalBatchConfiguration{Parallelism: 1},
+			want:   1,
+		},
+		{
+			name: "prefers the parallelism configured for the job",
+			config: localconfig.LocalBatchConfiguration{
+				Parallelism: 4,
+				Jobs:        map[string]localconfig.LocalJobConfiguration{"resize": {Parallelism: 1}},
+			},
+			want: 1,
+		},
+		{
+			name:         "limits runs to the CPUs available for the job's requirements",
+			config:       localconfig.LocalBatchConfiguration{Parallelism: cpus * 4},
+			requirements: &batchpb.JobResourceRequirements{Cpus: float32(cpus) / 2},
+			want:         2,
+		},
+		{
+			name:         "allows one run of jobs requiring more CPUs than are available",
+			requirements: &batchpb.JobResourceRequirements{Cpus: float32(cpus) * 2},
+			want:         1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLocalBatchService(tt.config)
+			l.registerJob("services/resize.ts", &batchpb.RegistrationRequest{JobName: "resize", Requirements: tt.requirements})
+
+			if got := l.parallelism("resize"); got != tt.want {
+				t.Errorf("parallelism() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingRunner runs jobs in isolation, each run waits for a value on release before completing with the error received
+type blockingRunner struct {
+	started chan string
+	release chan error
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{
+		started: make(chan string, 10),
+		release: make(chan error),
+	}
+}
+
+func (b *blockingRunner) run(serviceName string, req *batchpb.JobRequest, requirements *batchpb.JobResourceRequirements) (*IsolatedJobResult, error) {
+	b.started <- req.GetData().GetStruct().GetFields()["id"].GetStringValue()
+
+	err := <-b.release
+	if err != nil {
+		return &IsolatedJobResult{ExitCode: 1, Logs: "failed"}, err
+	}
+
+	return &IsolatedJobResult{Logs: "done"}, nil
+}
+
+func (b *blockingRunner) nextStarted(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case id := <-b.started:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no job was started")
+		return ""
+	}
+}
+
+func (b *blockingRunner) assertNoneStarted(t *testing.T) {
+	t.Helper()
+
+	select {
+	case id := <-b.started:
+		t.Fatalf("job %s started beyond the parallelism limit", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// actionRecorder records the actions published for each job run
+type actionRecorder struct {
+	lock    sync.Mutex
+	actions map[string][]ActionState
+}
+
+func (a *actionRecorder) record(action ActionState) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.actions[action.JobId] = append(a.actions[action.JobId], action)
+}
+
+func (a *actionRecorder) statuses(jobId string) []JobStatus {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	statuses := []JobStatus{}
+
+	for _, action := range a.actions[jobId] {
+		statuses = append(statuses, action.Status)
+	}
+
+	return statuses
+}
+
+func (a *actionRecorder) last(jobId string) ActionState {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	actions := a.actions[jobId]
+
+	return actions[len(actions)-1]
+}
+
+func newTestBatchService(t *testing.T, config localconfig.LocalBatchConfiguration) (*LocalBatchService, *blockingRunner, *actionRecorder) {
+	t.Helper()
+
+	l := NewLocalBatchService(config)
+	// require a fraction of the CPUs so the configured parallelism applies on any machine
+	l.registerJob("services/resize.ts", &batchpb.RegistrationRequest{
+		JobName:      "resize",
+		Requirements: &batchpb.JobResourceRequirements{Cpus: float32(runtime.NumCPU()) / 4},
+	})
+
+	runner := newBlockingRunner()
+	l.SetIsolatedJobRunner(runner.run)
+
+	recorder := &actionRecorder{actions: map[string][]ActionState{}}
+	l.SubscribeToAction(recorder.record)
+
+	return l, runner, recorder
+}
+
+func submit(t *testing.T, l *LocalBatchService, id string) string {
+	t.Helper()
+
+	data, err := structpb.NewStruct(map[string]any{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobId, err := l.Submit(&batchpb.JobSubmitRequest{
+		JobName: "resize",
+		Data:    &batchpb.JobData{Data: &batchpb.JobData_Struct{Struct: data}},
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	return jobId
+}
+
+func TestSubmitQueuesRunsBeyondParallelism(t *testing.T) {
+	l, runner, recorder := newTestBatchService(t, localconfig.LocalBatchConfiguration{Parallelism: 2})
+
+	jobIds := []string{}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		jobIds = append(jobIds, submit(t, l, id))
+	}
+
+	started := []string{runner.nextStarted(t), runner.nextStarted(t)}
+	runner.assertNoneStarted(t)
+
+	// the first two runs start in either order, the rest wait in the order they were submitted
+	if diff := cmp.Diff([]string{"a", "b"}, started, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("started runs mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, jobId := range jobIds[2:] {
+		if diff := cmp.Diff([]JobStatus{JobQueued}, recorder.statuses(jobId)); diff != "" {
+			t.Errorf("queued run statuses mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	// each completed run starts the next queued run
+	runner.release <- nil
+	if id := runner.nextStarted(t); id != "c" {
+		t.Errorf("started %s, want the next queued run c", id)
+	}
+
+	runner.assertNoneStarted(t)
+
+	runner.release <- nil
+	if id := runner.nextStarted(t); id != "d" {
+		t.Errorf("started %s, want the next queued run d", id)
+	}
+
+	runner.release <- nil
+	runner.release <- nil
+}
+
+func TestSubmitReportsRunStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		runErr       error
+		wantStatuses []JobStatus
+		wantSuccess  bool
+		wantError    string
+		wantExitCode int
+	}{
+		{
+			name:         "reports a successful run",
+			wantStatuses: []JobStatus{JobQueued, JobRunning, JobSucceeded},
+			wantSuccess:  true,
+		},
+		{
+			name:         "reports a failed run",
+			runErr:       errors.New("out of memory"),
+			wantStatuses: []JobStatus{JobQueued, JobRunning, JobFailed},
+			wantError:    "out of memory",
+			wantExitCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, runner, recorder := newTestBatchService(t, localconfig.LocalBatchConfiguration{Parallelism: 1})
+
+			jobId := submit(t, l, "a")
+			otherJobId := submit(t, l, "b")
+
+			if jobId == "" || jobId == otherJobId {
+				t.Fatalf("Submit() ids = %q and %q, want unique ids for each run", jobId, otherJobId)
+			}
+
+			runner.nextStarted(t)
+			runner.release <- tt.runErr
+
+			// the second run only starts once the first has finished and published its final status
+			runner.nextStarted(t)
+
+			if diff := cmp.Diff(tt.wantStatuses, recorder.statuses(jobId)); diff != "" {
+				t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+			}
+
+			final := recorder.last(jobId)
+
+			if final.JobName != "resize" || final.Payload != `{"id":"a"}` {
+				t.Errorf("final state job = %s payload = %s, want resize {\"id\":\"a\"}", final.JobName, final.Payload)
+			}
+
+			if final.Success != tt.wantSuccess || final.Error != tt.wantError {
+				t.Errorf("final state success = %t error = %q, want %t %q", final.Success, final.Error, tt.wantSuccess, tt.wantError)
+			}
+
+			if final.ExitCode == nil || *final.ExitCode != tt.wantExitCode {
+				t.Errorf("final state exit code = %v, want %d", final.ExitCode, tt.wantExitCode)
+			}
+
+			if final.QueuedAt.IsZero() || final.StartedAt.Before(final.QueuedAt) || final.FinishedAt.Before(final.StartedAt) {
+				t.Errorf("final state times queued = %s started = %s finished = %s, want them in order", final.QueuedAt, final.StartedAt, final.FinishedAt)
+			}
+
+			runner.release <- nil
+		})
+	}
+}
+
+func TestSubmitFailsWithoutWorker(t *testing.T) {
+	l := NewLocalBatchService(localconfig.LocalBatchConfiguration{})
+	l.SetIsolatedJobRunner(newBlockingRunner().run)
+
+	done := make(chan ActionState, 3)
+	l.SubscribeToAction(func(action ActionState) {
+		done <- action
+	})
+
+	jobId, err := l.Submit(&batchpb.JobSubmitRequest{JobName: "resize", Data: &batchpb.JobData{Data: &batchpb.JobData_Struct{Struct: &structpb.Struct{}}}})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	for {
+		select {
+		case action := <-done:
+			if action.JobId != jobId {
+				t.Fatalf("action for job %s, want %s", action.JobId, jobId)
+			}
+
+			if action.Status != JobFailed {
+				continue
+			}
+
+			if action.Error != "no worker registered for job: resize" {
+				t.Errorf("error = %q, want the missing worker to be reported", action.Error)
+			}
+
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run without a worker didn't fail")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"sync"
+
+	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
+	"github.com/nitrictech/nitric/core/pkg/workers/jobs"
+)
+
+// IsolatedJobManager handles the jobs of a single isolated job container, tracking which jobs it has registered handlers for
+type IsolatedJobManager struct {
+	*jobs.JobManager
+
+	lock       sync.Mutex
+	registered map[jobName]chan struct{}
+}
+
+var _ jobs.JobRequestHandler = (*IsolatedJobManager)(nil)
+
+// registrationStream notifies the job manager once the handler read from the stream is ready for requests
+type registrationStream struct {
+	batchpb.Job_HandleJobServer
+
+	manager *IsolatedJobManager
+	jobName string
+}
+
+func (s *registrationStream) Recv() (*batchpb.ClientMessage, error) {
+	// the job manager only reads again once the handler is registered and its broker is running,
+	// requests sent any earlier are rejected by the broker
+	if s.jobName != "" {
+		s.manager.markRegistered(s.jobName)
+	}
+
+	msg, err := s.Job_HandleJobServer.Recv()
+	if err == nil && msg.GetRegistrationRequest() != nil {
+		s.jobName = msg.GetRegistrationRequest().GetJobName()
+	}
+
+	return msg, err
+}
+
+// registration returns a channel that's closed once a handler for the job has registered
+func (m *IsolatedJobManager) registration(jobName string) chan struct{} {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.registered[jobName] == nil {
+		m.registered[jobName] = make(chan struct{})
+	}
+
+	return m.registered[jobName]
+}
+
+func (m *IsolatedJobManager) markRegistered(jobName string) {
+	registered := m.registration(jobName)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// a handler may register again after reconnecting
+	select {
+	case <-registered:
+	default:
+		close(registered)
+	}
+}
+
+func (m *IsolatedJobManager) HandleJob(stream batchpb.Job_HandleJobServer) error {
+	return m.JobManager.HandleJob(&registrationStream{Job_HandleJobServer: stream, manager: m})
+}
+
+// WaitForHandler blocks until a handler for the job has registered, or the context is done
+func (m *IsolatedJobManager) WaitForHandler(ctx context.Context, jobName string) error {
+	select {
+	case <-m.registration(jobName):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func NewIsolatedJobManager() *IsolatedJobManager {
+	return &IsolatedJobManager{
+		JobManager: jobs.New(),
+		registered: map[jobName]chan struct{}{},
+	}
+}
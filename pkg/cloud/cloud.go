@@ -89,6 +89,17 @@ func (lc *LocalCloud) Stop() {
 }
 
 func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
+	// get an available port
+	ports, err := netx.TakePort(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return lc.AddBatchOnPort(batchName, ports[0])
+}
+
+// AddBatchOnPort starts a nitric server for the batch on a fixed port
+func (lc *LocalCloud) AddBatchOnPort(batchName string, port int) (int, error) {
 	lc.serverLock.Lock()
 	defer lc.serverLock.Unlock()
 
@@ -96,12 +107,6 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 		return 0, fmt.Errorf("batch %s already added", batchName)
 	}
 
-	// get an available port
-	ports, err := netx.TakePort(1)
-	if err != nil {
-		return 0, err
-	}
-
 	nitricRuntimeServer, _ := server.New(
 		server.WithJobHandlerPlugin(lc.Batch),
 		server.WithBatchPlugin(lc.Batch),
@@ -109,7 +114,7 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 		server.WithApiPlugin(lc.Apis),
 		server.WithHttpPlugin(lc.Http),
 		server.WithSqlPlugin(lc.Databases),
-		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", ports[0])),
+		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", port)),
 		server.WithSecretManagerPlugin(lc.Secrets),
 		server.WithStoragePlugin(lc.Storage),
 		server.WithKeyValuePlugin(lc.KeyValue),
@@ -120,7 +125,7 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 		server.WithChildCommand([]string{}))
 
 	// Create a watcher that clears old resources when the service is restarted
-	_, err = resources.NewServiceResourceRefresher(batchName, resources.NewServiceResourceRefresherArgs{
+	_, err := resources.NewServiceResourceRefresher(batchName, resources.NewServiceResourceRefresherArgs{
 		Resources:  lc.Resources,
 		Apis:       lc.Apis,
 		Schedules:  lc.Schedules,
@@ -137,10 +142,11 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 
 	go func() {
 		interceptor, streamInterceptor := grpcx.CreateServiceNameInterceptor(batchName)
+		policyInterceptor, policyStreamInterceptor := lc.Resources.PolicyInterceptors()
 
 		srv := grpc.NewServer(
-			grpc.UnaryInterceptor(interceptor),
-			grpc.StreamInterceptor(streamInterceptor),
+			grpc.ChainUnaryInterceptor(interceptor, policyInterceptor),
+			grpc.ChainStreamInterceptor(streamInterceptor, policyStreamInterceptor),
 		)
 
 		// Enable reflection on the gRPC server for local testing
@@ -154,23 +160,72 @@ func (lc *LocalCloud) AddBatch(batchName string) (int, error) {
 
 	lc.servers[batchName] = nitricRuntimeServer
 
-	return ports[0], nil
+	return port, nil
 }
 
-func (lc *LocalCloud) AddService(serviceName string) (int, error) {
-	lc.serverLock.Lock()
-	defer lc.serverLock.Unlock()
-
-	if _, ok := lc.servers[serviceName]; ok {
-		return 0, fmt.Errorf("service %s already started", serviceName)
+// AddIsolatedBatch starts a nitric server for a single job run of a batch, jobs are handled by the given job manager rather than the shared batch service.
+// The returned function stops the server.
+func (lc *LocalCloud) AddIsolatedBatch(batchName string, jobManager *batch.IsolatedJobManager) (int, func(), error) {
+	// get an available port
+	ports, err := netx.TakePort(1)
+	if err != nil {
+		return 0, nil, err
 	}
 
+	nitricRuntimeServer, _ := server.New(
+		server.WithJobHandlerPlugin(jobManager),
+		server.WithBatchPlugin(lc.Batch),
+		server.WithResourcesPlugin(lc.Resources),
+		server.WithApiPlugin(lc.Apis),
+		server.WithHttpPlugin(lc.Http),
+		server.WithSqlPlugin(lc.Databases),
+		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", ports[0])),
+		server.WithSecretManagerPlugin(lc.Secrets),
+		server.WithStoragePlugin(lc.Storage),
+		server.WithKeyValuePlugin(lc.KeyValue),
+		server.WithGatewayPlugin(lc.Gateway),
+		server.WithWebsocketPlugin(lc.Websockets),
+		server.WithQueuesPlugin(lc.Queues),
+		server.WithMinWorkers(0),
+		server.WithChildCommand([]string{}))
+
+	go func() {
+		interceptor, streamInterceptor := grpcx.CreateServiceNameInterceptor(batchName)
+		policyInterceptor, policyStreamInterceptor := lc.Resources.PolicyInterceptors()
+
+		srv := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(interceptor, policyInterceptor),
+			grpc.ChainStreamInterceptor(streamInterceptor, policyStreamInterceptor),
+		)
+
+		err := nitricRuntimeServer.Start(server.WithGrpcServer(srv))
+		if err != nil {
+			logger.Errorf("Error starting nitric server: %s", err.Error())
+		}
+	}()
+
+	return ports[0], nitricRuntimeServer.Stop, nil
+}
+
+func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 	// get an available port
 	ports, err := netx.TakePort(1)
 	if err != nil {
 		return 0, err
 	}
 
+	return lc.AddServiceOnPort(serviceName, ports[0])
+}
+
+// AddServiceOnPort starts a nitric server for the service on a fixed port
+func (lc *LocalCloud) AddServiceOnPort(serviceName string, port int) (int, error) {
+	lc.serverLock.Lock()
+	defer lc.serverLock.Unlock()
+
+	if _, ok := lc.servers[serviceName]; ok {
+		return 0, fmt.Errorf("service %s already started", serviceName)
+	}
+
 	nitricRuntimeServer, _ := server.New(
 		server.WithBatchPlugin(lc.Batch),
 		server.WithResourcesPlugin(lc.Resources),
@@ -182,7 +237,7 @@ func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 		server.WithStorageListenerPlugin(lc.Storage),
 		server.WithWebsocketListenerPlugin(lc.Websockets),
 		server.WithSqlPlugin(lc.Databases),
-		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", ports[0])),
+		server.WithServiceAddress(fmt.Sprintf("0.0.0.0:%d", port)),
 		server.WithSecretManagerPlugin(lc.Secrets),
 		server.WithStoragePlugin(lc.Storage),
 		server.WithKeyValuePlugin(lc.KeyValue),
@@ -193,7 +248,7 @@ func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 		server.WithChildCommand([]string{}))
 
 	// Create a watcher that clears old resources when the service is restarted
-	_, err = resources.NewServiceResourceRefresher(serviceName, resources.NewServiceResourceRefresherArgs{
+	_, err := resources.NewServiceResourceRefresher(serviceName, resources.NewServiceResourceRefresherArgs{
 		Resources:  lc.Resources,
 		Apis:       lc.Apis,
 		Schedules:  lc.Schedules,
@@ -210,10 +265,11 @@ func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 
 	go func() {
 		interceptor, streamInterceptor := grpcx.CreateServiceNameInterceptor(serviceName)
+		policyInterceptor, policyStreamInterceptor := lc.Resources.PolicyInterceptors()
 
 		srv := grpc.NewServer(
-			grpc.UnaryInterceptor(interceptor),
-			grpc.StreamInterceptor(streamInterceptor),
+			grpc.ChainUnaryInterceptor(interceptor, policyInterceptor),
+			grpc.ChainStreamInterceptor(streamInterceptor, policyStreamInterceptor),
 		)
 
 		// Enable reflection on the gRPC server for local testing
@@ -227,7 +283,7 @@ func (lc *LocalCloud) AddService(serviceName string) (int, error) {
 
 	lc.servers[serviceName] = nitricRuntimeServer
 
-	return ports[0], nil
+	return port, nil
 }
 
 // LocalCloudMode type run or start
@@ -246,6 +302,8 @@ type LocalCloudOptions struct {
 	LocalConfig     localconfig.LocalConfiguration
 	MigrationRunner sql.MigrationRunner
 	LocalCloudMode  LocalCloudMode
+	// ExternalSql is used instead of starting a local database container when set
+	ExternalSql *sql.ExternalServer
 }
 
 func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
@@ -254,7 +312,7 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		return nil, err
 	}
 
-	localWebsockets, err := websockets.NewLocalWebsocketService()
+	localWebsockets, err := websockets.NewLocalWebsocketService(opts.LocalConfig.Websockets)
 	if err != nil {
 		return nil, err
 	}
@@ -267,8 +325,8 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		return nil, err
 	}
 
-	localResources := resources.NewLocalResourcesService()
-	localBatch := batch.NewLocalBatchService()
+	localResources := resources.NewLocalResourcesService(opts.LocalConfig.Policies)
+	localBatch := batch.NewLocalBatchService(opts.LocalConfig.Batch)
 	localSchedules := schedules.NewLocalSchedulesService(localResources.LogServiceError)
 	localHttpProxy := http.NewLocalHttpProxyService()
 
@@ -277,6 +335,7 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		LogWriter:      opts.LogWriter,
 		LocalConfig:    opts.LocalConfig,
 		BatchPlugin:    localBatch,
+		Resources:      localResources,
 	})
 	if err != nil {
 		return nil, err
@@ -310,7 +369,7 @@ func New(projectName string, opts LocalCloudOptions) (*LocalCloud, error) {
 		connectionStringHost = dockerhost.GetInternalDockerHost()
 	}
 
-	localDatabaseService, err := sql.NewLocalSqlServer(projectName, localResources, opts.MigrationRunner, connectionStringHost)
+	localDatabaseService, err := sql.NewLocalSqlServer(projectName, localResources, opts.MigrationRunner, connectionStringHost, opts.ExternalSql)
 	if err != nil {
 		return nil, err
 	}
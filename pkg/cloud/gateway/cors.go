@@ -0,0 +1,126 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/valyala/fasthttp"
+
+	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for a request origin, or an empty string if the origin isn't allowed
+func allowedOrigin(config *localconfig.LocalCorsConfiguration, origin string) string {
+	if slices.Contains(config.AllowOrigins, "*") {
+		// browsers reject a wildcard origin on credentialed requests, so echo the origin instead
+		if config.AllowCredentials {
+			return origin
+		}
+
+		return "*"
+	}
+
+	for _, allowed := range config.AllowOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// withCors applies the CORS configuration of an API from local.nitric.yaml, answering preflight requests without forwarding them to a worker
+func (s *LocalGatewayService) withCors(apiName string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		config := s.localConfig.Apis[apiName].Cors
+		origin := string(ctx.Request.Header.Peek("Origin"))
+
+		if config == nil || origin == "" {
+			next(ctx)
+			return
+		}
+
+		allowOrigin := allowedOrigin(config, origin)
+
+		ctx.Response.Header.Add("Vary", "Origin")
+
+		requestMethod := string(ctx.Request.Header.Peek("Access-Control-Request-Method"))
+
+		if ctx.IsOptions() && requestMethod != "" {
+			if allowOrigin == "" {
+				s.rejectApiRequest(ctx, apiName, fasthttp.StatusForbidden, fmt.Sprintf("Forbidden: origin %s is not allowed by the CORS configuration for api %s", origin, apiName))
+				return
+			}
+
+			allowMethods := config.AllowMethods
+			if len(allowMethods) == 0 {
+				// default to the methods the route supports, as the deployed gateway would
+				match, _ := apis.MatchRoute(s.apisPlugin.GetState()[apiName], requestMethod, string(ctx.URI().PathOriginal()))
+				if match != nil {
+					allowMethods = match.AllowedMethods
+				}
+			}
+
+			allowHeaders := strings.Join(config.AllowHeaders, ", ")
+			if len(config.AllowHeaders) == 0 {
+				allowHeaders = string(ctx.Request.Header.Peek("Access-Control-Request-Headers"))
+			}
+
+			ctx.Response.Header.Set("Access-Control-Allow-Origin", allowOrigin)
+			ctx.Response.Header.Set("Access-Control-Allow-Methods", strings.Join(lo.Uniq(append(slices.Clone(allowMethods), fasthttp.MethodOptions)), ", "))
+
+			if allowHeaders != "" {
+				ctx.Response.Header.Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+
+			if config.AllowCredentials {
+				ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if config.MaxAge > 0 {
+				ctx.Response.Header.Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+			}
+
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+
+			return
+		}
+
+		next(ctx)
+
+		if allowOrigin == "" {
+			return
+		}
+
+		// the gateway's configuration takes precedence over any CORS headers set by the worker
+		ctx.Response.Header.Set("Access-Control-Allow-Origin", allowOrigin)
+
+		if config.AllowCredentials {
+			ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if len(config.ExposeHeaders) > 0 {
+			ctx.Response.Header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposeHeaders, ", "))
+		}
+	}
+}
@@ -19,6 +19,7 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -32,6 +33,7 @@ import (
 	"github.com/asaskevich/EventBus"
 	"github.com/fasthttp/router"
 	"github.com/fasthttp/websocket"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"github.com/valyala/fasthttp"
@@ -40,6 +42,7 @@ import (
 	"github.com/nitrictech/cli/pkg/cloud/apis"
 	"github.com/nitrictech/cli/pkg/cloud/batch"
 	"github.com/nitrictech/cli/pkg/cloud/http"
+	"github.com/nitrictech/cli/pkg/cloud/resources"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
 	"github.com/nitrictech/cli/pkg/cloud/topics"
 	"github.com/nitrictech/cli/pkg/cloud/websockets"
@@ -84,19 +87,25 @@ var upgrader = websocket.FastHTTPUpgrader{}
 type LocalGatewayService struct {
 	apiServers       []*apiServer
 	httpServers      []*apiServer
+	ingressServer    *apiServer
 	apis             []string
 	httpWorkers      []string
+	httpWorkerNames  map[string]string // service names of the http workers, by host
 	websocketWorkers []string
 	socketServer     map[string]*socketServer
 	serviceServer    *fasthttp.Server
 	apisPlugin       *apis.LocalApiGatewayService
 	websocketPlugin  *websockets.LocalWebsocketService
+	httpPlugin       *http.LocalHttpProxy
 	topicsPlugin     *topics.LocalTopicsAndSubscribersService
 	schedulesPlugin  *schedules.LocalSchedulesService
 	batchPlugin      *batch.LocalBatchService
 	serviceListener  net.Listener
 
 	localConfig localconfig.LocalConfiguration
+	security    *apiSecurity
+	validator   *apiValidator
+	tokenIssuer *LocalTokenIssuer
 
 	logWriter io.Writer
 
@@ -195,16 +204,13 @@ func (s *LocalGatewayService) GetWebsocketAddresses() map[string]string {
 	return addresses
 }
 
-func (s *LocalGatewayService) handleHttpProxyRequest(idx int) fasthttp.RequestHandler {
+func (s *LocalGatewayService) handleHttpProxyRequest(host string) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
-		port := s.httpWorkers[idx]
-
-		// set port so http plugin can find server from state
+		// set host so http plugin can find server from state
 		requestCopy := &fasthttp.Request{}
 		ctx.Request.CopyTo(requestCopy)
-		requestCopy.URI().SetHost(port)
-		// TODO: Need to support multiple HTTP handlers
-		// so a plugin wrapper will be required for this
+		requestCopy.URI().SetHost(host)
+		// each proxy is also reachable from the shared ingress, see handleIngressRequest
 		resp, err := s.options.HttpPlugin.HandleRequest(requestCopy)
 		if err != nil {
 			ctx.Error(fmt.Sprintf("Error handling HTTP Request: %v", err), 500)
@@ -249,14 +255,52 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 			return
 		}
 
+		method := string(ctx.Request.Header.Method())
+
+		// resolve the route the same way the deployed gateway would, rather than leaving it to the worker.
+		// the original path is used as the decoded path has lost the distinction between encoded and literal characters
+		match, err := apis.MatchRoute(s.apisPlugin.GetState()[apiName], method, string(ctx.URI().PathOriginal()))
+		if errors.Is(err, apis.ErrMethodNotAllowed) {
+			// without a CORS configuration OPTIONS requests are left for the worker to answer, as they were before routes were matched locally
+			if !ctx.IsOptions() || s.localConfig.Apis[apiName].Cors != nil {
+				ctx.Response.Header.Set("Allow", strings.Join(match.AllowedMethods, ", "))
+				s.rejectApiRequest(ctx, apiName, 405, fmt.Sprintf("Method Not Allowed: %s %s", method, path))
+
+				return
+			}
+		} else if err != nil {
+			s.rejectApiRequest(ctx, apiName, 404, fmt.Sprintf("Not Found: no route registered for %s %s", method, path))
+			return
+		}
+
+		if s.security.enabled() {
+			secErr := s.security.authorize(apiName, match.Registration, string(ctx.Request.Header.Peek("Authorization")))
+			if secErr != nil {
+				s.rejectApiRequest(ctx, apiName, secErr.Status, secErr.Message)
+				return
+			}
+		}
+
+		var validationInput *openapi3filter.RequestValidationInput
+
+		violations := []string{}
+
+		if s.validator.enabled() && match.Registration != nil {
+			validationInput, violations = s.validator.validateRequest(apiName, match, ctx)
+			if len(violations) > 0 {
+				s.rejectApiRequest(ctx, apiName, 400, fmt.Sprintf("Bad Request: request does not match the OpenAPI document for api %s:\n%s", apiName, strings.Join(violations, "\n")), violations...)
+				return
+			}
+		}
+
 		apiEvent := &apispb.ServerMessage{
 			Content: &apispb.ServerMessage_HttpRequest{
 				HttpRequest: &apispb.HttpRequest{
-					Method:      string(ctx.Request.Header.Method()),
+					Method:      method,
 					Path:        path,
 					Headers:     headers,
 					QueryParams: query,
-					PathParams:  map[string]string{},
+					PathParams:  match.PathParams,
 					Body:        ctx.Request.Body(),
 				},
 			},
@@ -281,11 +325,20 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 			ctx.Response.SetStatusCode(int(http.Status))
 			ctx.Response.SetBody(resp.GetHttpResponse().GetBody())
 
+			if s.validator.enabled() {
+				violations = s.validator.validateResponse(validationInput, http)
+				for _, violation := range violations {
+					system.Log(fmt.Sprintf("api %s: %s %s: %s", apiName, method, path, violation))
+				}
+			}
+
 			// publish ctx for history
 			s.apisPlugin.PublishActionState(apis.ApiRequestState{
-				Api:      apiName,
-				ReqCtx:   ctx,
-				HttpResp: http,
+				Api:              apiName,
+				ReqCtx:           ctx,
+				HttpResp:         http,
+				PathParams:       match.PathParams,
+				ValidationErrors: violations,
 			})
 
 			return
@@ -295,8 +348,68 @@ func (s *LocalGatewayService) handleApiHttpRequest(apiName string) fasthttp.Requ
 	}
 }
 
+// rejectApiRequest responds to an API request on behalf of the gateway, without forwarding it to a worker
+func (s *LocalGatewayService) rejectApiRequest(ctx *fasthttp.RequestCtx, apiName string, status int, message string, validationErrors ...string) {
+	ctx.Response.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	if status == 401 {
+		ctx.Response.Header.Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	ctx.Response.SetStatusCode(status)
+	ctx.Response.SetBodyString(message)
+
+	// record every header of the response, including those set by the caller such as Allow
+	headers := map[string]*apispb.HeaderValue{}
+
+	ctx.Response.Header.VisitAll(func(key []byte, value []byte) {
+		k := string(key)
+
+		if headers[k] == nil {
+			headers[k] = &apispb.HeaderValue{}
+		}
+
+		headers[k].Value = append(headers[k].Value, string(value))
+	})
+
+	// publish ctx for history
+	s.apisPlugin.PublishActionState(apis.ApiRequestState{
+		Api:    apiName,
+		ReqCtx: ctx,
+		HttpResp: &apispb.HttpResponse{
+			Status:  int32(status),
+			Headers: headers,
+			Body:    []byte(message),
+		},
+		ValidationErrors: validationErrors,
+	})
+}
+
+// MintToken creates a test token for an API security definition, signed by the local token issuer
+func (s *LocalGatewayService) MintToken(apiName string, schemeName string, opts MintTokenOptions) (string, error) {
+	if len(opts.Audiences) == 0 {
+		audiences, err := s.security.audiences(apiName, schemeName)
+		if err != nil {
+			return "", err
+		}
+
+		opts.Audiences = audiences
+	}
+
+	return s.tokenIssuer.Mint(opts)
+}
+
+func (s *LocalGatewayService) handleJwks(ctx *fasthttp.RequestCtx) {
+	body, err := json.Marshal(s.tokenIssuer.Jwks())
+	if err != nil {
+		ctx.Error(fmt.Sprintf("Error serializing jwks: %v", err), 500)
+		return
+	}
+
+	ctx.Success("application/json", body)
+}
+
 // websocket request handler
-// TODO: Add broadcast capability
 func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		upgrader.CheckOrigin = func(ctx *fasthttp.RequestCtx) bool {
@@ -306,6 +419,7 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 		connectionId := uuid.New().String()
 
 		query := map[string]*websocketspb.QueryValue{}
+		queryParams := map[string][]string{}
 
 		ctx.QueryArgs().VisitAll(func(key []byte, val []byte) {
 			k := string(key)
@@ -315,6 +429,7 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 			}
 
 			query[k].Value = append(query[k].Value, string(val))
+			queryParams[k] = append(queryParams[k], string(val))
 		})
 
 		resp, err := s.options.WebsocketListenerPlugin.HandleRequest(&websocketspb.ServerMessage{
@@ -348,35 +463,22 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 					ConnectionId: connectionId,
 					SocketName:   socketName,
 				})
-				if err != nil {
+				// the connection may have already been closed by a worker or the dashboard
+				if err != nil && !errors.Is(err, websockets.ErrConnectionNotFound) {
 					tui.Error.Println(err.Error())
 					return
 				}
 			}()
 
-			err = s.websocketPlugin.RegisterConnection(socketName, connectionId, ws)
+			err = s.websocketPlugin.RegisterConnection(socketName, connectionId, ws, queryParams)
 			if err != nil {
 				tui.Error.Println(err.Error())
 				return
 			}
 
-			// Handshake successful send a registration message with connection ID to the socket worker
-			for {
-				// We have successfully connected a new client
-				// We can read/write messages to/from this client
-				// Need to create a unique ID for this connection and store in a central location
-				// This will allow connected clients to message eachother and broadcast to all clients as well
-				// We'll only read new messages on this connection here, writing will be done by a separate runtime API
-				// Won't print errors that arise if the socket is closed and are "going away" or "no status" errors
-				_, message, err := ws.ReadMessage()
-				if err != nil && websocket.IsCloseError(err, 1001, 1005) {
-					break
-				} else if err != nil {
-					log.Println("read:", err)
-					break
-				}
-
-				_, err = s.options.WebsocketListenerPlugin.HandleRequest(&websocketspb.ServerMessage{
+			// We'll only read new messages on this connection here, writing will be done by a separate runtime API
+			err = s.websocketTimeouts(socketName).readMessages(ws, func(message []byte) error {
+				_, err := s.options.WebsocketListenerPlugin.HandleRequest(&websocketspb.ServerMessage{
 					Content: &websocketspb.ServerMessage_WebsocketEventRequest{
 						WebsocketEventRequest: &websocketspb.WebsocketEventRequest{
 							SocketName:   socketName,
@@ -389,10 +491,12 @@ func (s *LocalGatewayService) handleWebsocketRequest(socketName string) func(ctx
 						},
 					},
 				})
-				if err != nil {
-					tui.Error.Println(err.Error())
-					return
-				}
+
+				return err
+			})
+			if err != nil {
+				tui.Error.Println(err.Error())
+				return
 			}
 
 			_, err = s.options.WebsocketListenerPlugin.HandleRequest(&websocketspb.ServerMessage{
@@ -498,12 +602,13 @@ func (s *LocalGatewayService) handleBatchJobTrigger(ctx *fasthttp.RequestCtx) {
 		Data:    &batchpb.JobData{Data: &batchpb.JobData_Struct{Struct: st}},
 	}
 
-	_, err = s.batchPlugin.SubmitJob(context.Background(), jobSubmitRequest)
+	jobId, err := s.batchPlugin.Submit(jobSubmitRequest)
 	if err != nil {
 		ctx.Error(fmt.Sprintf("Error handling batch job trigger: %v", err), 500)
 		return
 	}
 
+	ctx.Response.Header.Set("X-Nitric-Job-Id", jobId)
 	ctx.SuccessString("text/plain", "Successfully triggered job")
 }
 
@@ -551,20 +656,25 @@ func (s *LocalGatewayService) refreshHttpWorkers(state http.State) {
 	defer s.lock.Unlock()
 
 	s.httpWorkers = make([]string, 0)
+	s.httpWorkerNames = make(map[string]string)
 
-	// http server has been removed
-	if len(state) < len(s.httpServers) {
-		// shutdown the http servers that have been removed
-		s.httpServers = lo.Filter(s.httpServers, func(item *apiServer, index int) bool {
-			_, exists := state[item.name]
+	for host, proxy := range state {
+		s.httpWorkerNames[host] = proxy.ServiceName
+	}
 
-			if !exists {
-				shutdownServer(item.srv)
-			}
+	// shutdown the http servers of proxies that have been removed or moved to a new host,
+	// before new servers are created so a pinned port is free to be reused
+	s.httpServers = lo.Filter(s.httpServers, func(item *apiServer, index int) bool {
+		_, exists := state[item.name]
 
-			return exists
-		})
-	}
+		if !exists {
+			shutdownServer(item.srv)
+			// the server only closes listeners it has started serving, ensure the port is released
+			_ = item.lis.Close()
+		}
+
+		return exists
+	})
 
 	uniqHttpWorkers := lo.Reduce(lo.Keys(state), func(agg []string, host string, idx int) []string {
 		if !lo.Contains(agg, host) {
@@ -641,7 +751,7 @@ func (s *LocalGatewayService) createApiServers() error {
 			continue
 		}
 
-		lis, err := getListener(s.localConfig.Apis, apiName)
+		lis, err := getListener(apiName, s.localConfig.Apis[apiName].Port)
 		if err != nil {
 			return err
 		}
@@ -651,7 +761,7 @@ func (s *LocalGatewayService) createApiServers() error {
 			IdleTimeout:     time.Second * 1,
 			CloseOnShutdown: true,
 			ReadBufferSize:  8192,
-			Handler:         s.handleApiHttpRequest(apiName),
+			Handler:         s.withCors(apiName, s.handleApiHttpRequest(apiName)),
 			Logger:          log.New(s.logWriter, fmt.Sprintf("%s: ", lis.Addr().String()), 0),
 		}
 
@@ -688,16 +798,15 @@ func (s *LocalGatewayService) apiServerExists(apiName string) bool {
 	})
 }
 
-func getListener(mapping map[string]localconfig.LocalResourceConfiguration, name string) (net.Listener, error) {
-	if config, exists := mapping[name]; exists {
-		if config.Port != 0 {
-			list, err := net.Listen("tcp", fmt.Sprintf(":%d", config.Port))
-			if err != nil {
-				return nil, fmt.Errorf("error mapping %s to port %d, %s", name, config.Port, err.Error())
-			}
-
-			return list, nil
+// getListener listens on the configured port for a resource, or the next free port if none is configured
+func getListener(name string, port int) (net.Listener, error) {
+	if port != 0 {
+		list, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			return nil, fmt.Errorf("error mapping %s to port %d, %s", name, port, err.Error())
 		}
+
+		return list, nil
 	}
 
 	return netx.GetNextListener()
@@ -719,7 +828,7 @@ func (s *LocalGatewayService) createWebsocketServers() error {
 				Handler:         s.handleWebsocketRequest(sock),
 			}
 
-			lis, err := getListener(s.localConfig.Websockets, sock)
+			lis, err := getListener(sock, s.localConfig.Websockets[sock].Port)
 			if err != nil {
 				return err
 			}
@@ -756,20 +865,25 @@ func (s *LocalGatewayService) createWebsocketServers() error {
 }
 
 func (s *LocalGatewayService) createHttpServers() error {
-	// Expand servers to account for apis
-	lis, err := netx.GetNextListener()
-	if err != nil {
-		return err
-	}
+	// create an http server for every HTTP proxy worker
+	for _, host := range s.httpWorkers {
+		if s.httpServerExists(host) {
+			continue
+		}
+
+		serviceName := s.httpWorkerNames[host]
+
+		lis, err := getListener(serviceName, s.localConfig.Http[serviceName].Port)
+		if err != nil {
+			return err
+		}
 
-	// create an api server for every API worker
-	for len(s.httpServers) < len(s.httpWorkers) {
 		fhttp := &fasthttp.Server{
 			ReadTimeout:     time.Second * 1,
 			IdleTimeout:     time.Second * 1,
 			CloseOnShutdown: true,
 			ReadBufferSize:  8192,
-			Handler:         s.handleHttpProxyRequest(len(s.httpServers)),
+			Handler:         s.handleHttpProxyRequest(host),
 			Logger:          log.New(s.logWriter, fmt.Sprintf("%s: ", lis.Addr().String()), 0),
 		}
 
@@ -777,10 +891,9 @@ func (s *LocalGatewayService) createHttpServers() error {
 			lis:            lis,
 			srv:            fhttp,
 			tlsCredentials: s.ApiTlsCredentials,
-			name:           s.httpWorkers[len(s.httpServers)],
+			name:           host,
 		}
 
-		// get a free port and listen on that for this API
 		go func(srv *apiServer) {
 			var err error
 			if srv.tlsCredentials != nil {
@@ -800,12 +913,19 @@ func (s *LocalGatewayService) createHttpServers() error {
 	return nil
 }
 
+func (s *LocalGatewayService) httpServerExists(host string) bool {
+	return lo.SomeBy(s.httpServers, func(hs *apiServer) bool {
+		return hs.name == host
+	})
+}
+
 const nameParam = "{name}"
 
 const (
 	topicPath    = "/topics/" + nameParam
 	schedulePath = "/schedules/" + nameParam
 	batchPath    = "/jobs/" + nameParam
+	jwksPath     = "/.well-known/jwks.json"
 )
 
 func (s *LocalGatewayService) GetTopicTriggerUrl(topicName string) string {
@@ -836,6 +956,8 @@ func (s *LocalGatewayService) Start(opts *gateway.GatewayStartOpts) error {
 	r.POST(topicPath, s.handleTopicRequest)
 	r.POST(schedulePath, s.handleSchedulesTrigger)
 	r.POST(batchPath, s.handleBatchJobTrigger)
+	// Public keys of the local token issuer
+	r.GET(jwksPath, s.handleJwks)
 
 	s.serviceServer = &fasthttp.Server{
 		ReadTimeout:     time.Second * 1,
@@ -853,6 +975,7 @@ func (s *LocalGatewayService) Start(opts *gateway.GatewayStartOpts) error {
 	if apiPlugin, ok := s.options.ApiPlugin.(*apis.LocalApiGatewayService); ok {
 		apiPlugin.SubscribeToState(func(state apis.State) {
 			s.refreshApis(state)
+			s.validator.refresh(state)
 		})
 
 		s.apisPlugin = apiPlugin
@@ -878,6 +1001,13 @@ func (s *LocalGatewayService) Start(opts *gateway.GatewayStartOpts) error {
 		httpProxyPlugin.SubscribeToState(func(state map[string]*http.HttpProxyService) {
 			s.refreshHttpWorkers(state)
 		})
+
+		s.httpPlugin = httpProxyPlugin
+	}
+
+	err = s.createIngressServer()
+	if err != nil {
+		return err
 	}
 
 	return s.serviceServer.Serve(s.serviceListener)
@@ -903,6 +1033,10 @@ func (s *LocalGatewayService) Stop() error {
 		shutdownServer(hs.srv)
 	}
 
+	if s.ingressServer != nil {
+		shutdownServer(s.ingressServer.srv)
+	}
+
 	// Shutdown all the websocket servers
 	for _, ss := range s.socketServer {
 		shutdownServer(ss.srv)
@@ -920,16 +1054,31 @@ type NewGatewayOpts struct {
 	LogWriter      io.Writer
 	LocalConfig    localconfig.LocalConfiguration
 	BatchPlugin    *batch.LocalBatchService
+	Resources      *resources.LocalResourcesService
 }
 
 // Create new HTTP gateway
 // XXX: No External Args for function atm (currently the plugin loader does not pass any argument information)
 func NewGateway(opts NewGatewayOpts) (*LocalGatewayService, error) {
+	tokenIssuer, err := NewLocalTokenIssuer()
+	if err != nil {
+		return nil, err
+	}
+
+	security := newApiSecurity(opts.LocalConfig.ApiSecurity, tokenIssuer)
+
+	if opts.Resources != nil {
+		opts.Resources.SubscribeToState(security.refresh)
+	}
+
 	return &LocalGatewayService{
 		ApiTlsCredentials: opts.TLSCredentials,
 		bus:               EventBus.New(),
 		logWriter:         opts.LogWriter,
 		localConfig:       opts.LocalConfig,
 		batchPlugin:       opts.BatchPlugin,
+		security:          security,
+		validator:         newApiValidator(opts.LocalConfig.ApiValidation),
+		tokenIssuer:       tokenIssuer,
 	}, nil
 }
@@ -0,0 +1,65 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"net"
+	"testing"
+
+	"github.com/nitrictech/cli/pkg/cloud/http"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestRefreshHttpWorkersReplacesMovedHost(t *testing.T) {
+	// find a free port to pin the proxy to
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := lis.Addr().(*net.TCPAddr).Port
+	_ = lis.Close()
+
+	s := &LocalGatewayService{
+		localConfig: localconfig.LocalConfiguration{
+			Http: map[string]localconfig.LocalResourceConfiguration{
+				"web": {Port: port},
+			},
+		},
+	}
+
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.refreshHttpWorkers(http.State{"localhost:3000": {ServiceName: "web"}})
+
+	// the proxy restarts on a new host, the number of proxies is unchanged
+	s.refreshHttpWorkers(http.State{"localhost:3001": {ServiceName: "web"}})
+
+	if len(s.httpServers) != 1 {
+		t.Fatalf("expected 1 http server, got %d", len(s.httpServers))
+	}
+
+	if s.httpServers[0].name != "localhost:3001" {
+		t.Errorf("expected http server for localhost:3001, got %s", s.httpServers[0].name)
+	}
+
+	if s.httpServers[0].lis.Addr().(*net.TCPAddr).Port != port {
+		t.Errorf("expected http server on pinned port %d, got %s", port, s.httpServers[0].lis.Addr())
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+// hostSpecificity ranks host patterns, exact hosts are preferred over wildcards which are preferred over any host
+func hostSpecificity(pattern string) int {
+	switch {
+	case pattern == "":
+		return 0
+	case strings.HasPrefix(pattern, "*."):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func hostMatches(pattern string, host string) bool {
+	// the port isn't considered, all routes are served from the same listener
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if pattern == "" {
+		return true
+	}
+
+	if suffix, isWildcard := strings.CutPrefix(pattern, "*"); isWildcard {
+		return strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix))
+	}
+
+	return strings.EqualFold(pattern, host)
+}
+
+func normalizePrefix(prefix string) string {
+	return strings.TrimSuffix(prefix, "/")
+}
+
+// prefixMatches matches whole path segments, so /api matches /api and /api/users but not /apis
+func prefixMatches(prefix string, path string) bool {
+	prefix = normalizePrefix(prefix)
+
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// matchIngressRoute returns the route for a request, preferring the most specific host and then the longest path prefix
+func matchIngressRoute(routes []localconfig.LocalIngressRouteConfiguration, host string, path string) (localconfig.LocalIngressRouteConfiguration, bool) {
+	candidates := []localconfig.LocalIngressRouteConfiguration{}
+
+	for _, route := range routes {
+		if hostMatches(route.Host, host) && prefixMatches(route.PathPrefix, path) {
+			candidates = append(candidates, route)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return localconfig.LocalIngressRouteConfiguration{}, false
+	}
+
+	slices.SortStableFunc(candidates, func(a, b localconfig.LocalIngressRouteConfiguration) int {
+		if diff := hostSpecificity(b.Host) - hostSpecificity(a.Host); diff != 0 {
+			return diff
+		}
+
+		return len(normalizePrefix(b.PathPrefix)) - len(normalizePrefix(a.PathPrefix))
+	})
+
+	return candidates[0], true
+}
+
+// httpWorkerHost returns the host of the http proxy registered by a service
+func (s *LocalGatewayService) httpWorkerHost(serviceName string) (string, bool) {
+	if s.httpPlugin == nil {
+		return "", false
+	}
+
+	hosts := []string{}
+
+	for host, proxy := range s.httpPlugin.GetState() {
+		if proxy.ServiceName == serviceName {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return "", false
+	}
+
+	// keep routing stable if a service has registered more than one proxy
+	slices.Sort(hosts)
+
+	return hosts[0], true
+}
+
+// handleIngressRequest routes requests from the shared ingress to http proxy workers based on the routes in local.nitric.yaml
+func (s *LocalGatewayService) handleIngressRequest(ctx *fasthttp.RequestCtx) {
+	host := string(ctx.Host())
+	path := string(ctx.URI().Path())
+
+	route, ok := matchIngressRoute(s.localConfig.Ingress.Routes, host, path)
+	if !ok {
+		ctx.Error(fmt.Sprintf("Not Found: no ingress route matches host %s and path %s", host, path), fasthttp.StatusNotFound)
+		return
+	}
+
+	workerHost, ok := s.httpWorkerHost(route.Service)
+	if !ok {
+		ctx.Error(fmt.Sprintf("Service Unavailable: service %s is not running an http proxy", route.Service), fasthttp.StatusServiceUnavailable)
+		return
+	}
+
+	requestCopy := &fasthttp.Request{}
+	ctx.Request.CopyTo(requestCopy)
+
+	if route.StripPrefix {
+		prefix := normalizePrefix(route.PathPrefix)
+
+		strippedPath := strings.TrimPrefix(path, prefix)
+		if strippedPath == "" {
+			strippedPath = "/"
+		}
+
+		requestCopy.URI().SetPath(strippedPath)
+		requestCopy.Header.Set("X-Forwarded-Prefix", prefix)
+	}
+
+	// the host is replaced to identify the worker, so pass the original on
+	requestCopy.Header.Set("X-Forwarded-Host", host)
+	requestCopy.URI().SetHost(workerHost)
+
+	resp, err := s.options.HttpPlugin.HandleRequest(requestCopy)
+	if err != nil {
+		ctx.Error(fmt.Sprintf("Error handling HTTP Request: %v", err), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	resp.CopyTo(&ctx.Response)
+}
+
+// GetIngressAddress - Returns the address of the shared http ingress, or an empty string if no ingress routes are configured
+func (s *LocalGatewayService) GetIngressAddress() string {
+	if s.ingressServer == nil {
+		return ""
+	}
+
+	protocol := "http"
+	if s.ingressServer.tlsCredentials != nil {
+		protocol = "https"
+	}
+
+	return fmt.Sprintf("%s://%s", protocol, strings.Replace(s.ingressServer.lis.Addr().String(), "[::]", "localhost", 1))
+}
+
+func (s *LocalGatewayService) createIngressServer() error {
+	if len(s.localConfig.Ingress.Routes) == 0 {
+		return nil
+	}
+
+	lis, err := getListener("ingress", s.localConfig.Ingress.Port)
+	if err != nil {
+		return err
+	}
+
+	fhttp := &fasthttp.Server{
+		ReadTimeout:     time.Second * 1,
+		IdleTimeout:     time.Second * 1,
+		CloseOnShutdown: true,
+		ReadBufferSize:  8192,
+		Handler:         s.handleIngressRequest,
+		Logger:          log.New(s.logWriter, fmt.Sprintf("%s: ", lis.Addr().String()), 0),
+	}
+
+	s.ingressServer = &apiServer{
+		lis:            lis,
+		srv:            fhttp,
+		tlsCredentials: s.ApiTlsCredentials,
+		name:           "ingress",
+	}
+
+	go func(srv *apiServer) {
+		var err error
+		if srv.tlsCredentials != nil {
+			err = srv.srv.ServeTLS(srv.lis, srv.tlsCredentials.CertFile, srv.tlsCredentials.KeyFile)
+		} else {
+			err = srv.srv.Serve(srv.lis)
+		}
+
+		if err != nil {
+			fmt.Println(err)
+		}
+	}(s.ingressServer)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestMatchIngressRoute(t *testing.T) {
+	routes := []localconfig.LocalIngressRouteConfiguration{
+		{Service: "frontend"},
+		{PathPrefix: "/api/", Service: "api"},
+		{PathPrefix: "/api/admin", Service: "admin"},
+		{Host: "*.example.com", Service: "tenants"},
+		{Host: "docs.example.com", Service: "docs"},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		host     string
+		path     string
+		expected string
+	}{
+		{name: "falls back to catch all route", host: "localhost:4000", path: "/index.html", expected: "frontend"},
+		{name: "path prefix", host: "localhost:4000", path: "/api/users", expected: "api"},
+		{name: "path prefix without trailing slash", host: "localhost", path: "/api", expected: "api"},
+		{name: "longest path prefix", host: "localhost", path: "/api/admin/users", expected: "admin"},
+		{name: "prefix matches whole segments", host: "localhost", path: "/apis", expected: "frontend"},
+		{name: "wildcard host", host: "acme.example.com:4000", path: "/api/users", expected: "tenants"},
+		{name: "exact host preferred over wildcard", host: "DOCS.example.com", path: "/", expected: "docs"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			route, ok := matchIngressRoute(routes, tt.host, tt.path)
+			if !ok {
+				t.Fatalf("expected a route to match %s%s", tt.host, tt.path)
+			}
+
+			if route.Service != tt.expected {
+				t.Errorf("expected service %s, got %s", tt.expected, route.Service)
+			}
+		})
+	}
+
+	if _, ok := matchIngressRoute(routes[1:3], "localhost", "/"); ok {
+		t.Errorf("expected no route to match")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+
+	"github.com/nitrictech/cli/pkg/view/tui"
+)
+
+const defaultWebsocketPingInterval = 30 * time.Second
+
+// websocketPongTimeout is how long after a ping is due that a client may take to answer it before it's considered dead
+const websocketPongTimeout = 10 * time.Second
+
+const websocketControlTimeout = 5 * time.Second
+
+type websocketTimeouts struct {
+	idle         time.Duration
+	maxLifetime  time.Duration
+	pingInterval time.Duration
+}
+
+func (s *LocalGatewayService) websocketTimeouts(socketName string) websocketTimeouts {
+	config := s.localConfig.Websockets[socketName]
+
+	pingInterval := config.PingInterval
+	if pingInterval == 0 {
+		pingInterval = defaultWebsocketPingInterval
+	}
+
+	// idle and lifetime timeouts are opt-in, negative values are treated as disabled
+	return websocketTimeouts{
+		idle:         max(config.IdleTimeout, 0),
+		maxLifetime:  max(config.MaxLifetime, 0),
+		pingInterval: max(pingInterval, 0),
+	}
+}
+
+// websocketActivity tracks the activity of a connection, it's updated by the connection's read loop and the pong handler it calls
+type websocketActivity struct {
+	lock sync.Mutex
+
+	connectedAt time.Time
+	lastMessage time.Time
+	lastPong    time.Time
+}
+
+func newWebsocketActivity() *websocketActivity {
+	now := time.Now()
+
+	return &websocketActivity{
+		connectedAt: now,
+		lastMessage: now,
+		lastPong:    now,
+	}
+}
+
+func (a *websocketActivity) messageReceived() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.lastMessage = time.Now()
+}
+
+func (a *websocketActivity) pongReceived() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.lastPong = time.Now()
+}
+
+// readDeadline returns when the connection should next be closed if nothing is received, along with the reason it will be closed
+func (t websocketTimeouts) readDeadline(activity *websocketActivity) (time.Time, string) {
+	activity.lock.Lock()
+	defer activity.lock.Unlock()
+
+	deadline := time.Time{}
+	reason := ""
+
+	earliest := func(candidate time.Time, candidateReason string) {
+		if deadline.IsZero() || candidate.Before(deadline) {
+			deadline = candidate
+			reason = candidateReason
+		}
+	}
+
+	if t.pingInterval > 0 {
+		earliest(activity.lastPong.Add(t.pingInterval+websocketPongTimeout), "keepalive ping was not answered")
+	}
+
+	if t.idle > 0 {
+		earliest(activity.lastMessage.Add(t.idle), "idle timeout")
+	}
+
+	if t.maxLifetime > 0 {
+		earliest(activity.connectedAt.Add(t.maxLifetime), "maximum connection lifetime reached")
+	}
+
+	return deadline, reason
+}
+
+// readMessages reads messages from the client until it disconnects or is closed by a timeout, calling handle with each message.
+// An error returned by handle stops reading and is returned.
+func (t websocketTimeouts) readMessages(ws *websocket.Conn, handle func(message []byte) error) error {
+	activity := newWebsocketActivity()
+
+	// the pong handler is installed before any ping is sent, it's called by ReadMessage on this goroutine.
+	// pongs extend the read deadline but don't count as messages so they don't reset the idle timeout
+	ws.SetPongHandler(func(string) error {
+		activity.pongReceived()
+
+		deadline, _ := t.readDeadline(activity)
+
+		return ws.SetReadDeadline(deadline)
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go t.keepalive(ws, done)
+
+	for {
+		deadline, _ := t.readDeadline(activity)
+
+		err := ws.SetReadDeadline(deadline)
+		if err != nil {
+			tui.Error.Println(err.Error())
+			return nil
+		}
+
+		var netErr net.Error
+
+		// Won't print errors that arise if the socket is closed and are "going away" or "no status" errors
+		_, message, err := ws.ReadMessage()
+		if err != nil && websocket.IsCloseError(err, 1001, 1005) {
+			return nil
+		} else if errors.As(err, &netErr) && netErr.Timeout() {
+			// the deadline may have been moved by a pong since it was set, so work out which one was missed
+			_, reason := t.readDeadline(activity)
+
+			// emulate the deployed gateway disconnecting the client, workers receive a normal disconnect event
+			forceClose(ws, reason)
+
+			return nil
+		} else if err != nil {
+			log.Println("read:", err)
+			return nil
+		}
+
+		activity.messageReceived()
+
+		err = handle(message)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// keepalive pings the client until done is closed
+func (t websocketTimeouts) keepalive(ws *websocket.Conn, done <-chan struct{}) {
+	if t.pingInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(t.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			// WriteControl is safe to call concurrently with other writes
+			err := ws.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(websocketControlTimeout))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// forceClose sends a going away close frame to the client, the read loop will then deliver a disconnect event to workers
+func forceClose(ws *websocket.Conn, reason string) {
+	_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, reason), time.Now().Add(websocketControlTimeout))
+}
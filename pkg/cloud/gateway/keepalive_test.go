@@ -0,0 +1,197 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestWebsocketTimeoutsDefaultToDisabled(t *testing.T) {
+	s := &LocalGatewayService{}
+
+	timeouts := s.websocketTimeouts("socket")
+
+	if timeouts.idle != 0 || timeouts.maxLifetime != 0 {
+		t.Errorf("expected idle and lifetime timeouts to be disabled by default, got %v and %v", timeouts.idle, timeouts.maxLifetime)
+	}
+
+	if timeouts.pingInterval != defaultWebsocketPingInterval {
+		t.Errorf("expected default ping interval %v, got %v", defaultWebsocketPingInterval, timeouts.pingInterval)
+	}
+}
+
+func TestWebsocketReadDeadline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	activity := &websocketActivity{
+		connectedAt: start,
+		lastMessage: start.Add(time.Minute),
+		lastPong:    start.Add(2 * time.Minute),
+	}
+
+	for _, tt := range []struct {
+		name             string
+		config           localconfig.LocalWebsocketConfiguration
+		expectedDeadline time.Time
+		expectedReason   string
+	}{
+		{
+			name:             "pong deadline by default",
+			expectedDeadline: activity.lastPong.Add(defaultWebsocketPingInterval + websocketPongTimeout),
+			expectedReason:   "keepalive ping was not answered",
+		},
+		{
+			name:   "no deadline when everything is disabled",
+			config: localconfig.LocalWebsocketConfiguration{PingInterval: -1},
+		},
+		{
+			name:             "idle timeout",
+			config:           localconfig.LocalWebsocketConfiguration{IdleTimeout: 10 * time.Second},
+			expectedDeadline: activity.lastMessage.Add(10 * time.Second),
+			expectedReason:   "idle timeout",
+		},
+		{
+			name:             "lifetime is earliest",
+			config:           localconfig.LocalWebsocketConfiguration{IdleTimeout: time.Hour, MaxLifetime: time.Minute},
+			expectedDeadline: start.Add(time.Minute),
+			expectedReason:   "maximum connection lifetime reached",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &LocalGatewayService{
+				localConfig: localconfig.LocalConfiguration{
+					Websockets: map[string]localconfig.LocalWebsocketConfiguration{"socket": tt.config},
+				},
+			}
+
+			deadline, reason := s.websocketTimeouts("socket").readDeadline(activity)
+
+			if !deadline.Equal(tt.expectedDeadline) {
+				t.Errorf("expected deadline %v, got %v", tt.expectedDeadline, deadline)
+			}
+
+			if reason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestWebsocketReadMessagesKeepaliveAndIdleTimeout(t *testing.T) {
+	timeouts := websocketTimeouts{
+		idle:         200 * time.Millisecond,
+		pingInterval: 20 * time.Millisecond,
+	}
+
+	received := make(chan string, 10)
+	readErr := make(chan error, 1)
+
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			readErr <- err
+			return
+		}
+		defer ws.Close()
+
+		readErr <- timeouts.readMessages(ws, func(message []byte) error {
+			received <- string(message)
+			return nil
+		})
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	var pings atomic.Int32
+
+	client.SetPingHandler(func(data string) error {
+		pings.Add(1)
+
+		return client.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	closed := make(chan error, 1)
+
+	// control frames are only handled while reading, the client answers pings until the gateway closes the connection
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	var lastMessage time.Time
+
+	// messages reset the idle timeout, so the connection outlives it while they're sent
+	for i := range 4 {
+		if i > 0 {
+			time.Sleep(timeouts.idle / 2)
+		}
+
+		if err := client.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+			t.Fatalf("WriteMessage() error = %v", err)
+		}
+
+		lastMessage = time.Now()
+	}
+
+	// pongs don't count as messages, so the idle timeout still closes the connection
+	select {
+	case err := <-closed:
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway || closeErr.Text != "idle timeout" {
+			t.Fatalf("client read error = %v, want a going away close for the idle timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("connection wasn't closed after the idle timeout")
+	}
+
+	if elapsed := time.Since(lastMessage); elapsed < timeouts.idle {
+		t.Errorf("connection closed %s after the last message, want the idle timeout to have elapsed", elapsed)
+	}
+
+	if err := <-readErr; err != nil {
+		t.Errorf("readMessages() error = %v", err)
+	}
+
+	if len(received) != 4 {
+		t.Errorf("received %d messages, want 4", len(received))
+	}
+
+	if pings.Load() < 2 {
+		t.Errorf("client answered %d pings, want the gateway to keep pinging", pings.Load())
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/cloud/resources"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+// how long fetched key sets are trusted before being refreshed
+const keySetTtl = 10 * time.Minute
+
+// how long to wait before refetching a key set to find an unknown key id, so rotated keys are picked up without hammering the issuer
+const keySetMinRefresh = 30 * time.Second
+
+type openIdConfig struct {
+	Issuer  string `json:"issuer"`
+	JwksUri string `json:"jwks_uri"`
+}
+
+type keySet struct {
+	issuer    string
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// securityError is returned when a request fails the security rules for a route, Status is either 401 or 403
+type securityError struct {
+	Status  int
+	Message string
+}
+
+func (e *securityError) Error() string {
+	return e.Message
+}
+
+// apiSecurity enforces the declared security definitions of APIs on incoming requests
+type apiSecurity struct {
+	config localconfig.LocalApiSecurityConfiguration
+	issuer *LocalTokenIssuer
+	client *http.Client
+
+	lock        sync.RWMutex
+	apis        map[string]*resourcespb.ApiResource
+	definitions map[string]map[string]*resourcespb.ApiSecurityDefinitionResource
+	keySets     map[string]*keySet
+}
+
+func (a *apiSecurity) enabled() bool {
+	return a.config.Enabled
+}
+
+// refresh updates the known security definitions from the declared resources
+func (a *apiSecurity) refresh(lrs resources.LocalResourcesState) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.apis = map[string]*resourcespb.ApiResource{}
+	a.definitions = map[string]map[string]*resourcespb.ApiSecurityDefinitionResource{}
+
+	for apiName, api := range lrs.Apis.GetAll() {
+		a.apis[apiName] = api.Resource
+	}
+
+	for key, definition := range lrs.ApiSecurityDefinitions.GetAll() {
+		apiName := definition.Resource.GetApiName()
+
+		if a.definitions[apiName] == nil {
+			a.definitions[apiName] = map[string]*resourcespb.ApiSecurityDefinitionResource{}
+		}
+
+		a.definitions[apiName][resources.ApiSecurityDefinitionName(key, definition.Resource)] = definition.Resource
+	}
+}
+
+// requirements returns the security schemes and scopes required by a route, any one of the schemes must be satisfied
+func (a *apiSecurity) requirements(apiName string, route *apispb.RegistrationRequest) map[string][]string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	required := map[string][]string{}
+
+	if route.GetOptions().GetSecurityDisabled() {
+		return required
+	}
+
+	if len(route.GetOptions().GetSecurity()) > 0 {
+		for schemeName, scopes := range route.GetOptions().GetSecurity() {
+			required[schemeName] = scopes.GetScopes()
+		}
+
+		return required
+	}
+
+	for schemeName, scopes := range a.apis[apiName].GetSecurity() {
+		required[schemeName] = scopes.GetScopes()
+	}
+
+	return required
+}
+
+func (a *apiSecurity) definition(apiName string, schemeName string) *resourcespb.ApiSecurityDefinitionResource {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.definitions[apiName][schemeName]
+}
+
+// authorize validates the bearer token of a request against the security requirements of the route it matched
+func (a *apiSecurity) authorize(apiName string, route *apispb.RegistrationRequest, authorization string) *securityError {
+	required := a.requirements(apiName, route)
+	if len(required) == 0 {
+		return nil
+	}
+
+	rawToken, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || strings.TrimSpace(rawToken) == "" {
+		return &securityError{Status: http.StatusUnauthorized, Message: "Unauthorized: missing bearer token"}
+	}
+
+	var lastErr *securityError
+
+	for schemeName, scopes := range required {
+		claims, err := a.validateToken(apiName, schemeName, strings.TrimSpace(rawToken))
+		if err != nil {
+			if lastErr == nil || lastErr.Status != http.StatusForbidden {
+				lastErr = &securityError{Status: http.StatusUnauthorized, Message: fmt.Sprintf("Unauthorized: %s: %v", schemeName, err)}
+			}
+
+			continue
+		}
+
+		if hasAnyScope(claims, scopes) {
+			return nil
+		}
+
+		lastErr = &securityError{Status: http.StatusForbidden, Message: fmt.Sprintf("Forbidden: token is missing one of the required scopes [%s]", strings.Join(scopes, ", "))}
+	}
+
+	return lastErr
+}
+
+func (a *apiSecurity) validateToken(apiName string, schemeName string, rawToken string) (jwt.MapClaims, error) {
+	definition := a.definition(apiName, schemeName)
+	if definition == nil {
+		return nil, fmt.Errorf("security definition %s has not been declared for api %s", schemeName, apiName)
+	}
+
+	oidc := definition.GetOidc()
+	if oidc == nil {
+		return nil, fmt.Errorf("unsupported security definition type %T", definition.GetDefinition())
+	}
+
+	override := a.config.Override(apiName, schemeName)
+
+	claims := jwt.MapClaims{}
+
+	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		set, err := a.keySet(oidc.GetIssuer(), override, false)
+		if err != nil {
+			return nil, err
+		}
+
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := set.key(kid)
+		if !ok && time.Since(set.fetchedAt) >= keySetMinRefresh {
+			// the issuer may have rotated its keys since they were fetched
+			set, err = a.keySet(oidc.GetIssuer(), override, true)
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok = set.key(kid)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("no key found matching kid '%s'", kid)
+		}
+
+		issuer, _ := claims.GetIssuer()
+
+		if set.issuer != "" && strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(set.issuer, "/") {
+			return nil, fmt.Errorf("token issuer '%s' does not match expected issuer '%s'", issuer, set.issuer)
+		}
+
+		return key, nil
+	}, jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(oidc.GetAudiences()) > 0 && len(lo.Intersect(audiences, oidc.GetAudiences())) == 0 {
+		return nil, fmt.Errorf("token audience %v does not match any of the expected audiences %v", []string(audiences), oidc.GetAudiences())
+	}
+
+	return claims, nil
+}
+
+// key returns the signing key with the given id, tokens without an id can use the only key of a set
+func (k *keySet) key(kid string) (any, bool) {
+	key, ok := k.keys[kid]
+	if !ok && kid == "" && len(k.keys) == 1 {
+		return lo.Values(k.keys)[0], true
+	}
+
+	return key, ok
+}
+
+// keySet returns the cached signing keys for a security definition, fetching them if required or refresh is true
+func (a *apiSecurity) keySet(issuerUrl string, override localconfig.LocalSecurityDefinitionConfiguration, refresh bool) (*keySet, error) {
+	cacheKey := issuerUrl
+	if override.JwksUrl != "" {
+		cacheKey = override.JwksUrl
+	}
+
+	a.lock.RLock()
+	cached, ok := a.keySets[cacheKey]
+	a.lock.RUnlock()
+
+	if ok && !refresh && time.Since(cached.fetchedAt) < keySetTtl {
+		return cached, nil
+	}
+
+	var set *keySet
+
+	var err error
+
+	switch override.JwksUrl {
+	case LocalJwksUrl:
+		set, err = keySetFromJwks(LocalTokenIssuerName, a.issuer.Jwks())
+	case "":
+		set, err = a.fetchOpenIdKeySet(issuerUrl)
+	default:
+		set, err = a.fetchKeySet(override.JwksUrl, override.Issuer)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.lock.Lock()
+	a.keySets[cacheKey] = set
+	a.lock.Unlock()
+
+	return set, nil
+}
+
+func (a *apiSecurity) getJson(url string, target any) error {
+	resp, err := a.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received %d status retrieving %s", resp.StatusCode, url)
+	}
+
+	return json.Unmarshal(body, target)
+}
+
+func (a *apiSecurity) fetchOpenIdKeySet(issuerUrl string) (*keySet, error) {
+	config := openIdConfig{}
+
+	err := a.getJson(issuerUrl, &config)
+	if err != nil || config.JwksUri == "" {
+		// the issuer may have been declared without the discovery path
+		discoveryUrl := strings.TrimSuffix(issuerUrl, "/") + "/.well-known/openid-configuration"
+
+		err = a.getJson(discoveryUrl, &config)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve openid configuration for %s: %w", issuerUrl, err)
+		}
+	}
+
+	return a.fetchKeySet(config.JwksUri, config.Issuer)
+}
+
+func (a *apiSecurity) fetchKeySet(jwksUrl string, issuer string) (*keySet, error) {
+	jwks := jsonWebKeySet{}
+
+	err := a.getJson(jwksUrl, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve jwks from %s: %w", jwksUrl, err)
+	}
+
+	return keySetFromJwks(issuer, jwks)
+}
+
+func keySetFromJwks(issuer string, jwks jsonWebKeySet) (*keySet, error) {
+	set := &keySet{
+		issuer:    issuer,
+		keys:      map[string]any{},
+		fetchedAt: time.Now(),
+	}
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			// skip keys we can't use rather than failing the whole set
+			continue
+		}
+
+		set.keys[jwk.Kid] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("no usable signing keys found in jwks")
+	}
+
+	return set, nil
+}
+
+// audiences returns the declared audiences of a security definition, used when minting test tokens
+func (a *apiSecurity) audiences(apiName string, schemeName string) ([]string, error) {
+	definition := a.definition(apiName, schemeName)
+	if definition == nil {
+		return nil, fmt.Errorf("security definition %s has not been declared for api %s", schemeName, apiName)
+	}
+
+	return definition.GetOidc().GetAudiences(), nil
+}
+
+// hasAnyScope returns true if no scopes are required or the token contains at least one of them
+func hasAnyScope(claims jwt.MapClaims, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+
+	granted := []string{}
+
+	for _, claim := range []string{"scope", "scp", "scopes"} {
+		switch v := claims[claim].(type) {
+		case string:
+			granted = append(granted, strings.Fields(v)...)
+		case []interface{}:
+			for _, s := range v {
+				if str, ok := s.(string); ok {
+					granted = append(granted, str)
+				}
+			}
+		}
+	}
+
+	return len(lo.Intersect(granted, required)) > 0
+}
+
+func newApiSecurity(config localconfig.LocalApiSecurityConfiguration, issuer *LocalTokenIssuer) *apiSecurity {
+	return &apiSecurity{
+		config:      config,
+		issuer:      issuer,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		apis:        map[string]*resourcespb.ApiResource{},
+		definitions: map[string]map[string]*resourcespb.ApiSecurityDefinitionResource{},
+		keySets:     map[string]*keySet{},
+	}
+}
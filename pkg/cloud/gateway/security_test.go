@@ -0,0 +1,306 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/nitrictech/cli/pkg/cloud/resources"
+	"github.com/nitrictech/cli/pkg/grpcx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+// declareSecuredApi declares an api secured by an oidc rule named user, requiring the given scope
+func declareSecuredApi(t *testing.T, lrs *resources.LocalResourcesService, apiName string, audience string, scope string) {
+	t.Helper()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcx.ServiceNameKey, "services/"+apiName+".ts"))
+
+	requests := []*resourcespb.ResourceDeclareRequest{
+		{
+			Id: &resourcespb.ResourceIdentifier{Name: "user", Type: resourcespb.ResourceType_ApiSecurityDefinition},
+			Config: &resourcespb.ResourceDeclareRequest_ApiSecurityDefinition{
+				ApiSecurityDefinition: &resourcespb.ApiSecurityDefinitionResource{
+					ApiName: apiName,
+					Definition: &resourcespb.ApiSecurityDefinitionResource_Oidc{
+						Oidc: &resourcespb.ApiOpenIdConnectionDefinition{
+							Issuer:    testIssuer,
+							Audiences: []string{audience},
+						},
+					},
+				},
+			},
+		},
+		{
+			Id: &resourcespb.ResourceIdentifier{Name: apiName, Type: resourcespb.ResourceType_Api},
+			Config: &resourcespb.ResourceDeclareRequest_Api{
+				Api: &resourcespb.ApiResource{
+					Security: map[string]*resourcespb.ApiScopes{
+						"user": {Scopes: []string{scope}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, req := range requests {
+		if _, err := lrs.Declare(ctx, req); err != nil {
+			t.Fatalf("Declare() error = %v", err)
+		}
+	}
+}
+
+func newTestApiSecurity(t *testing.T, config localconfig.LocalApiSecurityConfiguration) (*apiSecurity, *LocalTokenIssuer) {
+	t.Helper()
+
+	issuer, err := NewLocalTokenIssuer()
+	if err != nil {
+		t.Fatalf("NewLocalTokenIssuer() error = %v", err)
+	}
+
+	security := newApiSecurity(config, issuer)
+
+	lrs := resources.NewLocalResourcesService(localconfig.LocalPoliciesConfiguration{})
+	lrs.SubscribeToState(security.refresh)
+
+	// both apis declare a rule with the same name, like separate services securing their own apis
+	declareSecuredApi(t, lrs, "orders", "orders-api", "orders.read")
+	declareSecuredApi(t, lrs, "users", "users-api", "users.read")
+
+	return security, issuer
+}
+
+// signToken signs claims with the issuer's key, for tokens Mint can't create such as expired ones
+func signToken(t *testing.T, issuer *LocalTokenIssuer, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = issuer.keyId
+
+	signed, err := token.SignedString(issuer.key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	return signed
+}
+
+func mintToken(t *testing.T, issuer *LocalTokenIssuer, opts MintTokenOptions) string {
+	t.Helper()
+
+	token, err := issuer.Mint(opts)
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+
+	return token
+}
+
+func TestApiSecurityAuthorize(t *testing.T) {
+	security, issuer := newTestApiSecurity(t, localconfig.LocalApiSecurityConfiguration{
+		Enabled: true,
+		Definitions: map[string]localconfig.LocalSecurityDefinitionConfiguration{
+			"user": {JwksUrl: LocalJwksUrl},
+		},
+	})
+
+	tests := []struct {
+		name          string
+		api           string
+		authorization string
+		route         *apispb.RegistrationRequest
+		wantStatus    int
+	}{
+		{
+			name:          "allows a valid token",
+			api:           "orders",
+			authorization: "Bearer " + mintToken(t, issuer, MintTokenOptions{Audiences: []string{"orders-api"}, Scopes: []string{"orders.read"}}),
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:          "allows a valid token for another api with a rule of the same name",
+			api:           "users",
+			authorization: "Bearer " + mintToken(t, issuer, MintTokenOptions{Audiences: []string{"users-api"}, Scopes: []string{"users.read"}}),
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "rejects a missing token",
+			api:        "orders",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "rejects an expired token",
+			api:  "orders",
+			authorization: "Bearer " + signToken(t, issuer, jwt.MapClaims{
+				"iss":   LocalTokenIssuerName,
+				"aud":   []string{"orders-api"},
+				"exp":   time.Now().Add(-time.Minute).Unix(),
+				"scope": "orders.read",
+			}),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "rejects a token for the wrong audience",
+			api:           "orders",
+			authorization: "Bearer " + mintToken(t, issuer, MintTokenOptions{Audiences: []string{"users-api"}, Scopes: []string{"orders.read"}}),
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "forbids a token missing the required scope",
+			api:           "orders",
+			authorization: "Bearer " + mintToken(t, issuer, MintTokenOptions{Audiences: []string{"orders-api"}, Scopes: []string{"users.read"}}),
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			name: "allows routes with security disabled",
+			api:  "orders",
+			route: &apispb.RegistrationRequest{
+				Options: &apispb.ApiWorkerOptions{SecurityDisabled: true},
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route
+			if route == nil {
+				route = &apispb.RegistrationRequest{}
+			}
+
+			status := http.StatusOK
+			if err := security.authorize(tt.api, route, tt.authorization); err != nil {
+				status = err.Status
+			}
+
+			if status != tt.wantStatus {
+				t.Errorf("authorize() status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestApiSecurityOverridesPerApi(t *testing.T) {
+	security, issuer := newTestApiSecurity(t, localconfig.LocalApiSecurityConfiguration{
+		Enabled: true,
+		Apis: map[string]map[string]localconfig.LocalSecurityDefinitionConfiguration{
+			"orders": {"user": {JwksUrl: LocalJwksUrl}},
+		},
+	})
+
+	// the users api isn't overridden, so it still expects tokens from its declared issuer which is unreachable
+	security.client = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+	})}
+
+	ordersToken := mintToken(t, issuer, MintTokenOptions{Audiences: []string{"orders-api"}, Scopes: []string{"orders.read"}})
+	if err := security.authorize("orders", &apispb.RegistrationRequest{}, "Bearer "+ordersToken); err != nil {
+		t.Errorf("authorize() orders error = %v, want the override to accept local tokens", err)
+	}
+
+	usersToken := mintToken(t, issuer, MintTokenOptions{Audiences: []string{"users-api"}, Scopes: []string{"users.read"}})
+	if err := security.authorize("users", &apispb.RegistrationRequest{}, "Bearer "+usersToken); err == nil {
+		t.Errorf("authorize() users error = nil, want the override of another api not to apply")
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestApiSecurityRefetchesRotatedKeys(t *testing.T) {
+	var lock sync.Mutex
+
+	current, err := NewLocalTokenIssuer()
+	if err != nil {
+		t.Fatalf("NewLocalTokenIssuer() error = %v", err)
+	}
+
+	fetches := 0
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		fetches++
+
+		_ = json.NewEncoder(w).Encode(current.Jwks())
+	}))
+	defer jwksServer.Close()
+
+	security, _ := newTestApiSecurity(t, localconfig.LocalApiSecurityConfiguration{
+		Enabled: true,
+		Definitions: map[string]localconfig.LocalSecurityDefinitionConfiguration{
+			"user": {JwksUrl: jwksServer.URL, Issuer: LocalTokenIssuerName},
+		},
+	})
+
+	authorize := func(issuer *LocalTokenIssuer) *securityError {
+		token := mintToken(t, issuer, MintTokenOptions{Audiences: []string{"orders-api"}, Scopes: []string{"orders.read"}})
+		return security.authorize("orders", &apispb.RegistrationRequest{}, "Bearer "+token)
+	}
+
+	if err := authorize(current); err != nil {
+		t.Fatalf("authorize() error = %v", err)
+	}
+
+	rotated, err := NewLocalTokenIssuer()
+	if err != nil {
+		t.Fatalf("NewLocalTokenIssuer() error = %v", err)
+	}
+
+	lock.Lock()
+	current = rotated
+	lock.Unlock()
+
+	// keys fetched moments ago aren't refetched, so an unknown key can't be used to hammer the issuer
+	if err := authorize(rotated); err == nil {
+		t.Fatalf("authorize() error = nil, want the rotated key to be unknown until the key set can be refreshed")
+	}
+
+	security.lock.Lock()
+	for _, set := range security.keySets {
+		set.fetchedAt = time.Now().Add(-keySetMinRefresh)
+	}
+	security.lock.Unlock()
+
+	if err := authorize(rotated); err != nil {
+		t.Fatalf("authorize() error = %v, want the rotated key to be fetched", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if fetches != 2 {
+		t.Errorf("jwks fetches = %d, want 2", fetches)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// LocalJwksUrl is used in local.nitric.yaml to validate tokens against the local token issuer
+const LocalJwksUrl = "local"
+
+// LocalTokenIssuerName is the issuer claim of tokens minted by the local token issuer
+const LocalTokenIssuerName = "https://nitric.local"
+
+const defaultTokenExpiry = time.Hour
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// publicKey converts the JWK to a public key usable to verify token signatures
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus for key %s: %w", k.Kid, err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent for key %s: %w", k.Kid, err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s for key %s", k.Crv, k.Kid)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate for key %s: %w", k.Kid, err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate for key %s: %w", k.Kid, err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s for key %s", k.Kty, k.Kid)
+	}
+}
+
+// LocalTokenIssuer is a stand in for an OIDC provider, it signs test tokens with a key generated for the lifetime of the local cloud
+type LocalTokenIssuer struct {
+	key   *rsa.PrivateKey
+	keyId string
+}
+
+type MintTokenOptions struct {
+	Subject   string
+	Audiences []string
+	Scopes    []string
+	ExpiresIn time.Duration
+}
+
+// Mint creates a signed token with the given claims
+func (i *LocalTokenIssuer) Mint(opts MintTokenOptions) (string, error) {
+	if opts.ExpiresIn <= 0 {
+		opts.ExpiresIn = defaultTokenExpiry
+	}
+
+	if opts.Subject == "" {
+		opts.Subject = "local-user"
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss":   LocalTokenIssuerName,
+		"sub":   opts.Subject,
+		"aud":   opts.Audiences,
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   now.Add(opts.ExpiresIn).Unix(),
+		"scope": strings.Join(opts.Scopes, " "),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.keyId
+
+	return token.SignedString(i.key)
+}
+
+// Jwks returns the public key set used to verify minted tokens
+func (i *LocalTokenIssuer) Jwks() jsonWebKeySet {
+	return jsonWebKeySet{
+		Keys: []jsonWebKey{
+			{
+				Kty: "RSA",
+				Kid: i.keyId,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(i.key.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.PublicKey.E)).Bytes()),
+			},
+		},
+	}
+}
+
+func NewLocalTokenIssuer() (*LocalTokenIssuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate local token signing key: %w", err)
+	}
+
+	return &LocalTokenIssuer{
+		key:   key,
+		keyId: uuid.NewString(),
+	}, nil
+}
@@ -0,0 +1,167 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLocalTokenIssuerMint(t *testing.T) {
+	issuer, err := NewLocalTokenIssuer()
+	if err != nil {
+		t.Fatalf("NewLocalTokenIssuer() error = %v", err)
+	}
+
+	set, err := keySetFromJwks(LocalTokenIssuerName, issuer.Jwks())
+	if err != nil {
+		t.Fatalf("keySetFromJwks() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    MintTokenOptions
+		wantSub string
+	}{
+		{
+			name:    "mints a token for the local user by default",
+			opts:    MintTokenOptions{Audiences: []string{"orders-api"}, Scopes: []string{"orders.read", "orders.write"}},
+			wantSub: "local-user",
+		},
+		{
+			name:    "mints a token for a subject",
+			opts:    MintTokenOptions{Subject: "user-1", Audiences: []string{"orders-api"}},
+			wantSub: "user-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := issuer.Mint(tt.opts)
+			if err != nil {
+				t.Fatalf("Mint() error = %v", err)
+			}
+
+			claims := jwt.MapClaims{}
+
+			_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+				kid, _ := token.Header["kid"].(string)
+				key, _ := set.key(kid)
+
+				return key, nil
+			}, jwt.WithExpirationRequired(), jwt.WithIssuer(LocalTokenIssuerName), jwt.WithAudience("orders-api"))
+			if err != nil {
+				t.Fatalf("minted token failed to validate against the jwks: %v", err)
+			}
+
+			if sub, _ := claims.GetSubject(); sub != tt.wantSub {
+				t.Errorf("sub = %q, want %q", sub, tt.wantSub)
+			}
+
+			if !hasAnyScope(claims, tt.opts.Scopes) {
+				t.Errorf("token is missing scopes %v", tt.opts.Scopes)
+			}
+
+			exp, _ := claims.GetExpirationTime()
+			if until := time.Until(exp.Time); until <= 0 || until > defaultTokenExpiry {
+				t.Errorf("token expires in %s, want within %s", until, defaultTokenExpiry)
+			}
+		})
+	}
+}
+
+func TestJsonWebKeyPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	tests := []struct {
+		name    string
+		jwk     jsonWebKey
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "converts rsa keys",
+			jwk:  jsonWebKey{Kty: "RSA", N: encode(rsaKey.N.Bytes()), E: "AQAB"},
+			want: &rsaKey.PublicKey,
+		},
+		{
+			name: "converts ec keys",
+			jwk:  jsonWebKey{Kty: "EC", Crv: "P-256", X: encode(ecKey.X.Bytes()), Y: encode(ecKey.Y.Bytes())},
+			want: &ecKey.PublicKey,
+		},
+		{
+			name:    "rejects unsupported curves",
+			jwk:     jsonWebKey{Kty: "EC", Crv: "P-192"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unsupported key types",
+			jwk:     jsonWebKey{Kty: "oct"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.jwk.publicKey()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("publicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			equal, ok := got.(interface{ Equal(x crypto.PublicKey) bool })
+			if !ok || !equal.Equal(tt.want) {
+				t.Errorf("publicKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeySetFromJwksSkipsEncryptionKeys(t *testing.T) {
+	issuer, err := NewLocalTokenIssuer()
+	if err != nil {
+		t.Fatalf("NewLocalTokenIssuer() error = %v", err)
+	}
+
+	jwks := issuer.Jwks()
+	jwks.Keys[0].Use = "enc"
+
+	if _, err := keySetFromJwks(LocalTokenIssuerName, jwks); err == nil {
+		t.Errorf("keySetFromJwks() error = nil, want an error when there are no signing keys")
+	}
+}
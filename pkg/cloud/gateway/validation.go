@@ -0,0 +1,243 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/samber/lo"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/cli/pkg/system"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+// apiValidator validates API requests and responses against the OpenAPI document of each API
+type apiValidator struct {
+	config localconfig.LocalApiValidationConfiguration
+
+	lock sync.RWMutex
+	docs map[string]*openapi3.T
+
+	// generatedLogged tracks the APIs already warned about validating against a generated document
+	generatedLogged map[string]struct{}
+}
+
+func (v *apiValidator) enabled() bool {
+	return v.config.Enabled
+}
+
+var validationOptions = &openapi3filter.Options{
+	MultiError: true,
+	// security is enforced separately by the gateway
+	AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+}
+
+// refresh regenerates the OpenAPI documents from the registered API routes.
+//
+// Routes registered by workers carry no schemas, so a generated document only constrains paths, methods and path params.
+// Request bodies, query params and responses are only validated against a document supplied in local.nitric.yaml.
+func (v *apiValidator) refresh(state apis.State) {
+	if !v.enabled() {
+		return
+	}
+
+	docs := map[string]*openapi3.T{}
+
+	for apiName, registrations := range state {
+		var doc *openapi3.T
+
+		var err error
+
+		if specFile, ok := v.config.Specs[apiName]; ok {
+			doc, err = openapi3.NewLoader().LoadFromFile(specFile)
+		} else {
+			v.warnGenerated(apiName)
+
+			// security definitions are omitted, they're validated by the gateway rather than the document
+			doc, err = collector.ApiToOpenApiSpec(registrations, map[string]map[string]*resourcespb.ApiSecurityDefinitionResource{}, &collector.ProjectErrors{})
+		}
+
+		if err != nil {
+			system.Log(fmt.Sprintf("unable to load OpenAPI document for api %s, requests will not be validated: %s", apiName, err.Error()))
+			continue
+		}
+
+		stripSecurity(doc)
+
+		docs[apiName] = doc
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.docs = docs
+}
+
+// warnGenerated logs once per API that it is validated against a generated document
+func (v *apiValidator) warnGenerated(apiName string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if _, logged := v.generatedLogged[apiName]; logged {
+		return
+	}
+
+	v.generatedLogged[apiName] = struct{}{}
+
+	msg := fmt.Sprintf("api %s has no OpenAPI document in apiValidation.specs of local.nitric.yaml, so only paths, methods and path params are validated. Provide a document to validate query params, request bodies and responses", apiName)
+	if v.config.Responses {
+		msg += fmt.Sprintf(", responses from api %s will not be validated until then", apiName)
+	}
+
+	system.Log(msg)
+}
+
+func stripSecurity(doc *openapi3.T) {
+	doc.Security = nil
+
+	for _, pathItem := range doc.Paths {
+		for _, operation := range pathItem.Operations() {
+			operation.Security = nil
+		}
+	}
+}
+
+func (v *apiValidator) route(apiName string, match *apis.RouteMatch, method string) *routers.Route {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	doc, ok := v.docs[apiName]
+	if !ok {
+		return nil
+	}
+
+	path := collector.OpenApiPath(match.Registration.Path)
+
+	pathItem := doc.Paths.Find(path)
+	if pathItem == nil {
+		return nil
+	}
+
+	operation := pathItem.GetOperation(strings.ToUpper(method))
+	if operation == nil {
+		return nil
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  pathItem,
+		Method:    strings.ToUpper(method),
+		Operation: operation,
+	}
+}
+
+// validateRequest returns the request validation input, for response validation, along with any violations found
+func (v *apiValidator) validateRequest(apiName string, match *apis.RouteMatch, ctx *fasthttp.RequestCtx) (*openapi3filter.RequestValidationInput, []string) {
+	route := v.route(apiName, match, string(ctx.Request.Header.Method()))
+	if route == nil {
+		return nil, []string{}
+	}
+
+	req := &http.Request{}
+
+	err := fasthttpadaptor.ConvertRequest(ctx, req, true)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("unable to read request: %s", err.Error())}
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: match.PathParams,
+		Route:      route,
+		Options:    validationOptions,
+	}
+
+	err = openapi3filter.ValidateRequest(context.Background(), input)
+
+	return input, validationErrors(err)
+}
+
+// validateResponse returns the violations found in a worker response
+func (v *apiValidator) validateResponse(input *openapi3filter.RequestValidationInput, resp *apispb.HttpResponse) []string {
+	if !v.config.Responses || input == nil {
+		return []string{}
+	}
+
+	header := http.Header{}
+
+	for k, headerValue := range resp.GetHeaders() {
+		for _, val := range headerValue.GetValue() {
+			header.Add(k, val)
+		}
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 int(resp.GetStatus()),
+		Header:                 header,
+		Options:                validationOptions,
+	}
+	responseInput.SetBodyBytes(resp.GetBody())
+
+	err := openapi3filter.ValidateResponse(context.Background(), responseInput)
+
+	return lo.Map(validationErrors(err), func(violation string, _ int) string {
+		return "response: " + violation
+	})
+}
+
+func validationErrors(err error) []string {
+	if err == nil {
+		return []string{}
+	}
+
+	var multiErr openapi3.MultiError
+	if errors.As(err, &multiErr) {
+		violations := []string{}
+
+		for _, e := range multiErr {
+			violations = append(violations, validationErrors(e)...)
+		}
+
+		return violations
+	}
+
+	return []string{err.Error()}
+}
+
+func newApiValidator(config localconfig.LocalApiValidationConfiguration) *apiValidator {
+	return &apiValidator{
+		config:          config,
+		docs:            map[string]*openapi3.T{},
+		generatedLogged: map[string]struct{}{},
+	}
+}
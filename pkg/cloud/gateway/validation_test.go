@@ -0,0 +1,294 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/valyala/fasthttp"
+
+	"github.com/nitrictech/cli/pkg/cloud/apis"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	apispb "github.com/nitrictech/nitric/core/pkg/proto/apis/v1"
+)
+
+const ordersSpec = `openapi: 3.0.1
+info:
+  title: orders
+  version: v1
+paths:
+  /orders/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: an order
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+  /orders:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          description: created
+`
+
+var ordersRegistrations = []*apispb.RegistrationRequest{
+	{Api: "orders", Path: "/orders/:id", Methods: []string{"GET"}},
+	{Api: "orders", Path: "/orders", Methods: []string{"POST"}},
+}
+
+func newTestApiValidator(t *testing.T, withSpec bool, responses bool) *apiValidator {
+	t.Helper()
+
+	config := localconfig.LocalApiValidationConfiguration{
+		Enabled:   true,
+		Responses: responses,
+	}
+
+	if withSpec {
+		specFile := filepath.Join(t.TempDir(), "orders.yaml")
+
+		if err := os.WriteFile(specFile, []byte(ordersSpec), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		config.Specs = map[string]string{"orders": specFile}
+	}
+
+	validator := newApiValidator(config)
+	validator.refresh(apis.State{"orders": {"services/orders.ts": ordersRegistrations}})
+
+	return validator
+}
+
+func newTestRequestCtx(method string, uri string, body string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+
+	if body != "" {
+		ctx.Request.Header.SetContentType("application/json")
+		ctx.Request.SetBodyString(body)
+	}
+
+	return ctx
+}
+
+func TestApiValidatorValidateRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		withSpec       bool
+		method         string
+		uri            string
+		match          *apis.RouteMatch
+		body           string
+		wantViolations []string
+	}{
+		{
+			name:     "accepts a request matching the document",
+			withSpec: true,
+			method:   "GET",
+			uri:      "/orders/1?limit=5",
+			match:    &apis.RouteMatch{Registration: ordersRegistrations[0], PathParams: map[string]string{"id": "1"}},
+		},
+		{
+			name:           "rejects an invalid path param",
+			withSpec:       true,
+			method:         "GET",
+			uri:            "/orders/abc",
+			match:          &apis.RouteMatch{Registration: ordersRegistrations[0], PathParams: map[string]string{"id": "abc"}},
+			wantViolations: []string{`parameter "id" in path has an error`},
+		},
+		{
+			name:           "rejects an invalid query param",
+			withSpec:       true,
+			method:         "GET",
+			uri:            "/orders/1?limit=many",
+			match:          &apis.RouteMatch{Registration: ordersRegistrations[0], PathParams: map[string]string{"id": "1"}},
+			wantViolations: []string{`parameter "limit" in query has an error`},
+		},
+		{
+			name:     "accepts a valid request body",
+			withSpec: true,
+			method:   "POST",
+			uri:      "/orders",
+			match:    &apis.RouteMatch{Registration: ordersRegistrations[1], PathParams: map[string]string{}},
+			body:     `{"name": "coffee"}`,
+		},
+		{
+			name:           "rejects a request body missing a required property",
+			withSpec:       true,
+			method:         "POST",
+			uri:            "/orders",
+			match:          &apis.RouteMatch{Registration: ordersRegistrations[1], PathParams: map[string]string{}},
+			body:           `{}`,
+			wantViolations: []string{`property "name" is missing`},
+		},
+		{
+			name:   "accepts any query param and body against a generated document",
+			method: "POST",
+			uri:    "/orders?limit=many",
+			match:  &apis.RouteMatch{Registration: ordersRegistrations[1], PathParams: map[string]string{}},
+			body:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := newTestApiValidator(t, tt.withSpec, false)
+
+			input, violations := validator.validateRequest("orders", tt.match, newTestRequestCtx(tt.method, tt.uri, tt.body))
+			if input == nil {
+				t.Fatalf("validateRequest() input = nil, want the route to be found in the document")
+			}
+
+			if len(violations) != len(tt.wantViolations) {
+				t.Fatalf("validateRequest() violations = %v, want %v", violations, tt.wantViolations)
+			}
+
+			for i, want := range tt.wantViolations {
+				if !strings.Contains(violations[i], want) {
+					t.Errorf("validateRequest() violation = %q, want it to contain %q", violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApiValidatorValidateResponse(t *testing.T) {
+	match := &apis.RouteMatch{Registration: ordersRegistrations[0], PathParams: map[string]string{"id": "1"}}
+
+	tests := []struct {
+		name           string
+		withSpec       bool
+		responses      bool
+		body           string
+		wantViolations int
+	}{
+		{
+			name:      "accepts a response matching the document",
+			withSpec:  true,
+			responses: true,
+			body:      `{"id": 1}`,
+		},
+		{
+			name:           "reports a response that doesn't match the document",
+			withSpec:       true,
+			responses:      true,
+			body:           `{"id": "one"}`,
+			wantViolations: 1,
+		},
+		{
+			name:     "ignores responses unless enabled",
+			withSpec: true,
+			body:     `{"id": "one"}`,
+		},
+		{
+			name:      "accepts any response against a generated document",
+			responses: true,
+			body:      `{"id": "one"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := newTestApiValidator(t, tt.withSpec, tt.responses)
+
+			input, violations := validator.validateRequest("orders", match, newTestRequestCtx("GET", "/orders/1", ""))
+			if len(violations) > 0 {
+				t.Fatalf("validateRequest() violations = %v, want none", violations)
+			}
+
+			violations = validator.validateResponse(input, &apispb.HttpResponse{
+				Status:  200,
+				Headers: map[string]*apispb.HeaderValue{"Content-Type": {Value: []string{"application/json"}}},
+				Body:    []byte(tt.body),
+			})
+
+			if len(violations) != tt.wantViolations {
+				t.Fatalf("validateResponse() violations = %v, want %d", violations, tt.wantViolations)
+			}
+
+			for _, violation := range violations {
+				if !strings.HasPrefix(violation, "response: ") {
+					t.Errorf("validateResponse() violation = %q, want it to be reported as a response violation", violation)
+				}
+			}
+		})
+	}
+}
+
+func TestRejectApiRequestRecordsValidationErrors(t *testing.T) {
+	s := &LocalGatewayService{apisPlugin: apis.NewLocalApiGatewayService(nil)}
+
+	var history []apis.ApiRequestState
+
+	s.apisPlugin.SubscribeToAction(func(state apis.ApiRequestState) {
+		history = append(history, state)
+	})
+
+	ctx := newTestRequestCtx("POST", "/orders", `{}`)
+	violations := []string{`request body has an error: property "name" is missing`}
+
+	s.rejectApiRequest(ctx, "orders", 400, "Bad Request: request does not match the OpenAPI document", violations...)
+
+	if status := ctx.Response.StatusCode(); status != 400 {
+		t.Errorf("status = %d, want 400", status)
+	}
+
+	if len(history) != 1 {
+		t.Fatalf("history entries = %d, want 1", len(history))
+	}
+
+	if diff := cmp.Diff(violations, history[0].ValidationErrors); diff != "" {
+		t.Errorf("history validation errors mismatch (-want +got):\n%s", diff)
+	}
+
+	if status := history[0].HttpResp.GetStatus(); status != 400 {
+		t.Errorf("history status = %d, want 400", status)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nitrictech/cli/pkg/grpcx"
+	kvstorepb "github.com/nitrictech/nitric/core/pkg/proto/kvstore/v1"
+	queuespb "github.com/nitrictech/nitric/core/pkg/proto/queues/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
+	sqlpb "github.com/nitrictech/nitric/core/pkg/proto/sql/v1"
+	storagepb "github.com/nitrictech/nitric/core/pkg/proto/storage/v1"
+	topicspb "github.com/nitrictech/nitric/core/pkg/proto/topics/v1"
+)
+
+// permission is the access a runtime request requires to a resource
+type permission struct {
+	resourceType resourcespb.ResourceType
+	resourceName string
+	// action is nil for resources that are accessed by declaring them, such as SQL databases
+	action *resourcespb.Action
+}
+
+func requires(resourceType resourcespb.ResourceType, resourceName string, action resourcespb.Action) *permission {
+	return &permission{resourceType: resourceType, resourceName: resourceName, action: &action}
+}
+
+// requiredPermission returns the permission a runtime request requires, or nil if the request isn't controlled by policies
+func requiredPermission(req any) *permission {
+	switch r := req.(type) {
+	case *storagepb.StorageReadRequest:
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFileGet)
+	case *storagepb.StorageExistsRequest:
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFileGet)
+	case *storagepb.StorageWriteRequest:
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFilePut)
+	case *storagepb.StorageDeleteRequest:
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFileDelete)
+	case *storagepb.StorageListBlobsRequest:
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFileList)
+	case *storagepb.StoragePreSignUrlRequest:
+		if r.GetOperation() == storagepb.StoragePreSignUrlRequest_WRITE {
+			return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFilePut)
+		}
+
+		return requires(resourcespb.ResourceType_Bucket, r.GetBucketName(), resourcespb.Action_BucketFileGet)
+	case *kvstorepb.KvStoreGetValueRequest:
+		return requires(resourcespb.ResourceType_KeyValueStore, r.GetRef().GetStore(), resourcespb.Action_KeyValueStoreRead)
+	case *kvstorepb.KvStoreScanKeysRequest:
+		return requires(resourcespb.ResourceType_KeyValueStore, r.GetStore().GetName(), resourcespb.Action_KeyValueStoreRead)
+	case *kvstorepb.KvStoreSetValueRequest:
+		return requires(resourcespb.ResourceType_KeyValueStore, r.GetRef().GetStore(), resourcespb.Action_KeyValueStoreWrite)
+	case *kvstorepb.KvStoreDeleteKeyRequest:
+		return requires(resourcespb.ResourceType_KeyValueStore, r.GetRef().GetStore(), resourcespb.Action_KeyValueStoreDelete)
+	case *topicspb.TopicPublishRequest:
+		return requires(resourcespb.ResourceType_Topic, r.GetTopicName(), resourcespb.Action_TopicPublish)
+	case *queuespb.QueueEnqueueRequest:
+		return requires(resourcespb.ResourceType_Queue, r.GetQueueName(), resourcespb.Action_QueueEnqueue)
+	case *queuespb.QueueDequeueRequest:
+		return requires(resourcespb.ResourceType_Queue, r.GetQueueName(), resourcespb.Action_QueueDequeue)
+	case *queuespb.QueueCompleteRequest:
+		return requires(resourcespb.ResourceType_Queue, r.GetQueueName(), resourcespb.Action_QueueDequeue)
+	case *secretspb.SecretPutRequest:
+		return requires(resourcespb.ResourceType_Secret, r.GetSecret().GetName(), resourcespb.Action_SecretPut)
+	case *secretspb.SecretAccessRequest:
+		return requires(resourcespb.ResourceType_Secret, r.GetSecretVersion().GetSecret().GetName(), resourcespb.Action_SecretAccess)
+	case *sqlpb.SqlConnectionStringRequest:
+		return &permission{resourceType: resourcespb.ResourceType_SqlDatabase, resourceName: r.GetDatabaseName()}
+	default:
+		return nil
+	}
+}
+
+func policyAllows(policy *resourcespb.PolicyResource, serviceName string, perm *permission) bool {
+	isPrincipal := slices.ContainsFunc(policy.GetPrincipals(), func(principal *resourcespb.ResourceIdentifier) bool {
+		return principal.GetType() == resourcespb.ResourceType_Service && principal.GetName() == serviceName
+	})
+
+	coversResource := slices.ContainsFunc(policy.GetResources(), func(resource *resourcespb.ResourceIdentifier) bool {
+		return resource.GetType() == perm.resourceType && resource.GetName() == perm.resourceName
+	})
+
+	return isPrincipal && coversResource && slices.Contains(policy.GetActions(), *perm.action)
+}
+
+// checkPermission returns a PermissionDenied error if the service hasn't been granted the permission by its declared policies
+func (l *LocalResourcesService) checkPermission(serviceName string, perm *permission) error {
+	resourceType := strings.ToLower(perm.resourceType.String())
+
+	// databases are accessible to any service that declares them
+	if perm.action == nil {
+		if slices.Contains(l.state.SqlDatabases.GetRequestingServices(perm.resourceName), serviceName) {
+			return nil
+		}
+
+		return status.Errorf(codes.PermissionDenied, "service %s has not declared the %s %s", serviceName, resourceType, perm.resourceName)
+	}
+
+	for _, policy := range l.state.Policies.GetAll() {
+		if policyAllows(policy.Resource, serviceName, perm) {
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.PermissionDenied, "service %s has not been granted %s on %s %s, request the permission when declaring the resource", serviceName, perm.action.String(), resourceType, perm.resourceName)
+}
+
+func (l *LocalResourcesService) authorizeRequest(ctx context.Context, req any) error {
+	perm := requiredPermission(req)
+	if perm == nil {
+		return nil
+	}
+
+	serviceName, err := grpcx.GetServiceNameFromIncomingContext(ctx)
+	if err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return l.checkPermission(serviceName, perm)
+}
+
+type policyEnforcedStream struct {
+	grpc.ServerStream
+	authorize func(ctx context.Context, req any) error
+}
+
+func (s *policyEnforcedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	return s.authorize(s.Context(), m)
+}
+
+// PolicyInterceptors enforce the declared resource policies on runtime requests when strict policies are enabled.
+// They must run after the service name interceptors, so the calling service can be identified.
+func (l *LocalResourcesService) PolicyInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if !l.strictPolicies {
+				return handler(ctx, req)
+			}
+
+			if err := l.authorizeRequest(ctx, req); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		}, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if !l.strictPolicies {
+				return handler(srv, ss)
+			}
+
+			return handler(srv, &policyEnforcedStream{ServerStream: ss, authorize: l.authorizeRequest})
+		}
+}
@@ -0,0 +1,300 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nitrictech/cli/pkg/grpcx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	kvstorepb "github.com/nitrictech/nitric/core/pkg/proto/kvstore/v1"
+	queuespb "github.com/nitrictech/nitric/core/pkg/proto/queues/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
+	sqlpb "github.com/nitrictech/nitric/core/pkg/proto/sql/v1"
+	storagepb "github.com/nitrictech/nitric/core/pkg/proto/storage/v1"
+	topicspb "github.com/nitrictech/nitric/core/pkg/proto/topics/v1"
+)
+
+const (
+	ordersService = "services/orders.ts"
+	auditService  = "services/audit.ts"
+)
+
+func serviceContext(serviceName string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcx.ServiceNameKey, serviceName))
+}
+
+func declare(t *testing.T, lrs *LocalResourcesService, serviceName string, req *resourcespb.ResourceDeclareRequest) {
+	t.Helper()
+
+	if _, err := lrs.Declare(serviceContext(serviceName), req); err != nil {
+		t.Fatalf("Declare() error = %v", err)
+	}
+}
+
+func policy(principals []*resourcespb.ResourceIdentifier, actions []resourcespb.Action, resourceType resourcespb.ResourceType, resourceName string) *resourcespb.ResourceDeclareRequest {
+	return &resourcespb.ResourceDeclareRequest{
+		Id: &resourcespb.ResourceIdentifier{Type: resourcespb.ResourceType_Policy},
+		Config: &resourcespb.ResourceDeclareRequest_Policy{
+			Policy: &resourcespb.PolicyResource{
+				Principals: principals,
+				Actions:    actions,
+				Resources:  []*resourcespb.ResourceIdentifier{{Type: resourceType, Name: resourceName}},
+			},
+		},
+	}
+}
+
+// newPoliciesTestService declares the resources used by the tests, the orders service is granted one action on each resource
+func newPoliciesTestService(t *testing.T, strict bool) *LocalResourcesService {
+	t.Helper()
+
+	lrs := NewLocalResourcesService(localconfig.LocalPoliciesConfiguration{Strict: strict})
+
+	self := []*resourcespb.ResourceIdentifier{{Type: resourcespb.ResourceType_Service}}
+
+	declare(t, lrs, ordersService, policy(self, []resourcespb.Action{resourcespb.Action_BucketFileGet}, resourcespb.ResourceType_Bucket, "receipts"))
+	declare(t, lrs, ordersService, policy(self, []resourcespb.Action{resourcespb.Action_KeyValueStoreWrite}, resourcespb.ResourceType_KeyValueStore, "carts"))
+	declare(t, lrs, ordersService, policy(self, []resourcespb.Action{resourcespb.Action_TopicPublish}, resourcespb.ResourceType_Topic, "orders"))
+	declare(t, lrs, ordersService, policy(self, []resourcespb.Action{resourcespb.Action_QueueEnqueue}, resourcespb.ResourceType_Queue, "shipments"))
+	declare(t, lrs, ordersService, policy(self, []resourcespb.Action{resourcespb.Action_SecretAccess}, resourcespb.ResourceType_Secret, "stripe-key"))
+	declare(t, lrs, ordersService, &resourcespb.ResourceDeclareRequest{
+		Id:     &resourcespb.ResourceIdentifier{Type: resourcespb.ResourceType_SqlDatabase, Name: "orders"},
+		Config: &resourcespb.ResourceDeclareRequest_SqlDatabase{SqlDatabase: &resourcespb.SqlDatabaseResource{}},
+	})
+
+	// a policy without principals applies to the service declaring it
+	declare(t, lrs, auditService, policy(nil, []resourcespb.Action{resourcespb.Action_TopicPublish}, resourcespb.ResourceType_Topic, "audit"))
+
+	return lrs
+}
+
+func TestPolicyInterceptorsUnary(t *testing.T) {
+	tests := []struct {
+		name        string
+		strict      bool
+		serviceName string
+		req         any
+		wantCode    codes.Code
+	}{
+		{
+			name:        "allows a granted bucket action",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &storagepb.StorageReadRequest{BucketName: "receipts"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies a bucket action that wasn't granted",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &storagepb.StorageWriteRequest{BucketName: "receipts"},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "denies a presigned write url with read access",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &storagepb.StoragePreSignUrlRequest{BucketName: "receipts", Operation: storagepb.StoragePreSignUrlRequest_WRITE},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows a granted key value store action",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &kvstorepb.KvStoreSetValueRequest{Ref: &kvstorepb.ValueRef{Store: "carts", Key: "cart-1"}},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies a key value store action that wasn't granted",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &kvstorepb.KvStoreDeleteKeyRequest{Ref: &kvstorepb.ValueRef{Store: "carts", Key: "cart-1"}},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows publishing to a granted topic",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &topicspb.TopicPublishRequest{TopicName: "orders"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies publishing to a topic granted to another service",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &topicspb.TopicPublishRequest{TopicName: "audit"},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows a policy without principals for the declaring service",
+			strict:      true,
+			serviceName: auditService,
+			req:         &topicspb.TopicPublishRequest{TopicName: "audit"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "allows a granted queue action",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &queuespb.QueueEnqueueRequest{QueueName: "shipments"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies a queue action that wasn't granted",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &queuespb.QueueDequeueRequest{QueueName: "shipments"},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows a granted secret action",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &secretspb.SecretAccessRequest{SecretVersion: &secretspb.SecretVersion{Secret: &secretspb.Secret{Name: "stripe-key"}, Version: "latest"}},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies a secret action that wasn't granted",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &secretspb.SecretPutRequest{Secret: &secretspb.Secret{Name: "stripe-key"}},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows connecting to a declared database",
+			strict:      true,
+			serviceName: ordersService,
+			req:         &sqlpb.SqlConnectionStringRequest{DatabaseName: "orders"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "denies connecting to a database the service didn't declare",
+			strict:      true,
+			serviceName: auditService,
+			req:         &sqlpb.SqlConnectionStringRequest{DatabaseName: "orders"},
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "allows requests that aren't controlled by policies",
+			strict:      true,
+			serviceName: auditService,
+			req:         &resourcespb.ResourceDeclareRequest{},
+			wantCode:    codes.OK,
+		},
+		{
+			name:     "denies requests from an unknown service",
+			strict:   true,
+			req:      &topicspb.TopicPublishRequest{TopicName: "orders"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:        "allows everything unless policies are strict",
+			serviceName: auditService,
+			req:         &storagepb.StorageDeleteRequest{BucketName: "receipts"},
+			wantCode:    codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unary, _ := newPoliciesTestService(t, tt.strict).PolicyInterceptors()
+
+			ctx := context.Background()
+			if tt.serviceName != "" {
+				ctx = serviceContext(tt.serviceName)
+			}
+
+			handled := false
+
+			_, err := unary(ctx, tt.req, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled = true
+				return nil, nil
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("interceptor code = %s, want %s: %v", code, tt.wantCode, err)
+			}
+
+			if handled != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %t, want it only called for allowed requests", handled)
+			}
+		})
+	}
+}
+
+// fakeServerStream receives a single message, as a streaming request would
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	msg *storagepb.StorageListBlobsRequest
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) RecvMsg(m any) error {
+	m.(*storagepb.StorageListBlobsRequest).BucketName = f.msg.GetBucketName()
+	return nil
+}
+
+func TestPolicyInterceptorsStream(t *testing.T) {
+	lrs := newPoliciesTestService(t, true)
+	declare(t, lrs, ordersService, policy([]*resourcespb.ResourceIdentifier{{Type: resourcespb.ResourceType_Service}}, []resourcespb.Action{resourcespb.Action_BucketFileList}, resourcespb.ResourceType_Bucket, "invoices"))
+
+	_, stream := lrs.PolicyInterceptors()
+
+	tests := []struct {
+		name     string
+		bucket   string
+		wantCode codes.Code
+	}{
+		{
+			name:     "allows receiving a granted request",
+			bucket:   "invoices",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "denies receiving a request that wasn't granted",
+			bucket:   "receipts",
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &fakeServerStream{ctx: serviceContext(ordersService), msg: &storagepb.StorageListBlobsRequest{BucketName: tt.bucket}}
+
+			err := stream(nil, ss, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+				return stream.RecvMsg(&storagepb.StorageListBlobsRequest{})
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("interceptor code = %s, want %s: %v", code, tt.wantCode, err)
+			}
+		})
+	}
+}
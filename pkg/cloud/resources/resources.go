@@ -22,11 +22,13 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"maps"
+	"strings"
 	"sync"
 
 	"github.com/asaskevich/EventBus"
 
 	"github.com/nitrictech/cli/pkg/grpcx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
 	"github.com/nitrictech/cli/pkg/validation"
 	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
 )
@@ -34,6 +36,7 @@ import (
 type ResourceName = string
 
 type LocalResourcesState struct {
+	Apis                   *ResourceRegistrar[resourcespb.ApiResource]
 	Buckets                *ResourceRegistrar[resourcespb.BucketResource]
 	BatchJobs              *ResourceRegistrar[resourcespb.JobResource]
 	KeyValueStores         *ResourceRegistrar[resourcespb.KeyValueStoreResource]
@@ -52,6 +55,8 @@ type LocalResourcesService struct {
 	errLock       sync.RWMutex
 	serviceErrors map[string][]error
 
+	strictPolicies bool
+
 	bus EventBus.Bus
 }
 
@@ -61,6 +66,17 @@ func (s *LocalResourcesService) SubscribeToState(fn func(lrs LocalResourcesState
 	_ = s.bus.Subscribe(localResourcesTopic, fn)
 }
 
+// ApiSecurityDefinitionKey returns the key of a security definition in the local resources state.
+// Definitions are scoped to their api, the same as deployments, so different apis can declare rules with the same name.
+func ApiSecurityDefinitionKey(apiName string, name string) string {
+	return apiName + "/" + name
+}
+
+// ApiSecurityDefinitionName returns the name of a security definition from its key
+func ApiSecurityDefinitionName(key string, definition *resourcespb.ApiSecurityDefinitionResource) string {
+	return strings.TrimPrefix(key, definition.GetApiName()+"/")
+}
+
 // policyResourceName generates a unique name for a policy resource by hashing the policy document
 func policyResourceName(policy *resourcespb.PolicyResource) (string, error) {
 	policyDoc, err := json.Marshal(policy)
@@ -86,13 +102,23 @@ func (l *LocalResourcesService) Declare(ctx context.Context, req *resourcespb.Re
 	}
 
 	switch req.Id.Type {
+	case resourcespb.ResourceType_Api:
+		err = l.state.Apis.Register(req.Id.Name, serviceName, req.GetApi())
 	case resourcespb.ResourceType_Bucket:
 		err = l.state.Buckets.Register(req.Id.Name, serviceName, req.GetBucket())
 	case resourcespb.ResourceType_KeyValueStore:
 		err = l.state.KeyValueStores.Register(req.Id.Name, serviceName, req.GetKeyValueStore())
 	case resourcespb.ResourceType_Policy:
 		// Services don't know their own name, so forgetful 🙄, that's ok, we'll add it here.
-		for _, principal := range req.GetPolicy().Principals {
+		// Policies without principals apply to the declaring service, matching the collector used for deployments.
+		if policy := req.GetPolicy(); policy != nil && len(policy.Principals) == 0 {
+			policy.Principals = []*resourcespb.ResourceIdentifier{{
+				Name: serviceName,
+				Type: resourcespb.ResourceType_Service,
+			}}
+		}
+
+		for _, principal := range req.GetPolicy().GetPrincipals() {
 			if principal.Type == resourcespb.ResourceType_Service {
 				principal.Name = serviceName
 			}
@@ -114,7 +140,7 @@ func (l *LocalResourcesService) Declare(ctx context.Context, req *resourcespb.Re
 	case resourcespb.ResourceType_Queue:
 		err = l.state.Queues.Register(req.Id.Name, serviceName, req.GetQueue())
 	case resourcespb.ResourceType_ApiSecurityDefinition:
-		err = l.state.ApiSecurityDefinitions.Register(req.Id.Name, serviceName, req.GetApiSecurityDefinition())
+		err = l.state.ApiSecurityDefinitions.Register(ApiSecurityDefinitionKey(req.GetApiSecurityDefinition().GetApiName(), req.Id.Name), serviceName, req.GetApiSecurityDefinition())
 	case resourcespb.ResourceType_SqlDatabase:
 		err = l.state.SqlDatabases.Register(req.Id.Name, serviceName, req.GetSqlDatabase())
 	}
@@ -142,6 +168,7 @@ func (l *LocalResourcesService) ClearServiceResources(serviceName string) {
 	l.errLock.Lock()
 	defer l.errLock.Unlock()
 
+	l.state.Apis.ClearRequestingService(serviceName)
 	l.state.Buckets.ClearRequestingService(serviceName)
 	l.state.KeyValueStores.ClearRequestingService(serviceName)
 	l.state.Policies.ClearRequestingService(serviceName)
@@ -156,9 +183,11 @@ func (l *LocalResourcesService) ClearServiceResources(serviceName string) {
 	delete(l.serviceErrors, serviceName)
 }
 
-func NewLocalResourcesService() *LocalResourcesService {
+func NewLocalResourcesService(policies localconfig.LocalPoliciesConfiguration) *LocalResourcesService {
 	return &LocalResourcesService{
+		strictPolicies: policies.Strict,
 		state: LocalResourcesState{
+			Apis:                   NewResourceRegistrar[resourcespb.ApiResource](),
 			BatchJobs:              NewResourceRegistrar[resourcespb.JobResource](),
 			Buckets:                NewResourceRegistrar[resourcespb.BucketResource](),
 			KeyValueStores:         NewResourceRegistrar[resourcespb.KeyValueStoreResource](),
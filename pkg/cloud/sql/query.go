@@ -0,0 +1,338 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+const (
+	// DefaultMaxRows is the server-side cap on rows returned from a single query
+	DefaultMaxRows = 1000
+	// MaxExportRows is the cap on rows returned when exporting query results
+	MaxExportRows = 100000
+	// DefaultQueryTimeout is the statement timeout applied to queries that don't provide one
+	DefaultQueryTimeout = 30 * time.Second
+)
+
+var (
+	ErrQueryCancelled = errors.New("query cancelled")
+	ErrQueryTimeout   = errors.New("query timed out")
+	// ErrOffsetNotSupported is returned when paginating a query that can't safely be executed more than once
+	ErrOffsetNotSupported = errors.New("offset is only supported for queries containing a single statement")
+)
+
+// pgQueryCanceled is the postgres error code for statements cancelled by the server, including statement timeouts
+const pgQueryCanceled = "57014"
+
+type QueryOptions struct {
+	// QueryId identifies the query so that it can be cancelled, one is generated if empty
+	QueryId string
+	// Offset is the number of rows to skip, only supported for single statement queries which are executed read-only
+	Offset int
+	// MaxRows is the maximum number of rows to return, defaults to DefaultMaxRows
+	MaxRows int
+	// Timeout is the statement timeout, defaults to DefaultQueryTimeout
+	Timeout time.Duration
+}
+
+type QueryResult struct {
+	QueryId string
+	Columns []string
+	// RowCount is the number of rows passed to the row handler
+	RowCount int
+	// Truncated is true when the final statement produced more rows than MaxRows allowed
+	Truncated bool
+}
+
+// RowHandler is called for each row returned from the final statement of a query
+type RowHandler = func(row *orderedmap.OrderedMap[string, any]) error
+
+// statementHandler is called with the rows of each statement in a query, the rows are closed once it returns
+type statementHandler = func(rows pgx.Rows, final bool) error
+
+// statementExecutor executes statements in a single transaction, passing the rows of each statement to the handler
+type statementExecutor = func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error
+
+func (o QueryOptions) withDefaults() QueryOptions {
+	if o.QueryId == "" {
+		o.QueryId = uuid.NewString()
+	}
+
+	if o.MaxRows <= 0 {
+		o.MaxRows = DefaultMaxRows
+	}
+
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultQueryTimeout
+	}
+
+	if o.Offset < 0 {
+		o.Offset = 0
+	}
+
+	return o
+}
+
+func (l *LocalSqlServer) trackQuery(queryId string, cancel context.CancelFunc) error {
+	l.queriesLock.Lock()
+	defer l.queriesLock.Unlock()
+
+	if _, ok := l.runningQueries[queryId]; ok {
+		return fmt.Errorf("query %s is already running", queryId)
+	}
+
+	l.runningQueries[queryId] = cancel
+
+	return nil
+}
+
+func (l *LocalSqlServer) untrackQuery(queryId string) {
+	l.queriesLock.Lock()
+	defer l.queriesLock.Unlock()
+
+	delete(l.runningQueries, queryId)
+}
+
+// CancelQuery cancels a running query, returning false if no query with the given id is running
+func (l *LocalSqlServer) CancelQuery(queryId string) bool {
+	l.queriesLock.Lock()
+	defer l.queriesLock.Unlock()
+
+	cancel, ok := l.runningQueries[queryId]
+	if !ok {
+		return false
+	}
+
+	cancel()
+	delete(l.runningQueries, queryId)
+
+	return true
+}
+
+// StreamQuery executes a query on the local database within a single transaction.
+// Rows of the final statement are passed to the handler as they are read, earlier statements are executed and their rows discarded.
+//
+// Rows are passed to the handler before the transaction commits, so when the query fails part way through the result
+// is still returned alongside the error, describing the rows that were handled before the transaction was rolled back.
+func (l *LocalSqlServer) StreamQuery(ctx context.Context, connectionString string, query string, opts QueryOptions, handler RowHandler) (*QueryResult, error) {
+	return l.streamQuery(ctx, query, opts, handler, func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, statementHandler statementHandler) error {
+		return execStatements(ctx, connectionString, statements, txOptions, timeout, statementHandler)
+	})
+}
+
+func (l *LocalSqlServer) streamQuery(ctx context.Context, query string, opts QueryOptions, handler RowHandler, exec statementExecutor) (*QueryResult, error) {
+	opts = opts.withDefaults()
+
+	statements := splitStatements(query)
+
+	txOptions := pgx.TxOptions{}
+
+	if opts.Offset > 0 {
+		// each page executes the query again, so only a single statement that can't modify the database may be paginated
+		if len(statements) > 1 {
+			return nil, ErrOffsetNotSupported
+		}
+
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	err := l.trackQuery(opts.QueryId, cancel)
+	if err != nil {
+		return nil, err
+	}
+
+	defer l.untrackQuery(opts.QueryId)
+
+	result := &QueryResult{
+		QueryId: opts.QueryId,
+		Columns: []string{},
+	}
+
+	err = exec(ctx, statements, txOptions, opts.Timeout, func(rows pgx.Rows, final bool) error {
+		if !final {
+			return nil
+		}
+
+		return streamRows(rows, opts, result, handler)
+	})
+	if err != nil {
+		return result, queryError(ctx, err)
+	}
+
+	return result, nil
+}
+
+// queryError replaces errors caused by cancellation or timeouts with ErrQueryCancelled or ErrQueryTimeout
+func queryError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ErrQueryCancelled
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled {
+		return ErrQueryTimeout
+	}
+
+	return err
+}
+
+func splitStatements(query string) []string {
+	statements := []string{}
+
+	for _, statement := range SQLSplit(query) {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+// execStatements executes each statement in a single transaction, passing the resulting rows to the handler.
+// A statement timeout is applied when timeout is greater than zero.
+func execStatements(ctx context.Context, connectionString string, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+	// Connect to the PostgreSQL instance using the provided connection string
+	conn, err := pgx.Connect(ctx, connectionString)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(context.Background())
+
+	// Begin transaction
+	tx, err := conn.BeginTx(ctx, txOptions)
+	if err != nil {
+		return err
+	}
+
+	if timeout > 0 {
+		// Ensure the database gives up on long running statements, even if the connection outlives the context
+		_, err = tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds()))
+		if err != nil {
+			_ = tx.Rollback(context.Background())
+
+			return err
+		}
+	}
+
+	// Execute each statement
+	for i, statement := range statements {
+		rows, err := tx.Query(ctx, statement)
+		if err != nil {
+			_ = tx.Rollback(context.Background())
+
+			return err
+		}
+
+		err = handler(rows, i == len(statements)-1)
+		rows.Close()
+
+		if err == nil {
+			err = rows.Err()
+		}
+
+		if err != nil {
+			_ = tx.Rollback(context.Background())
+
+			return err
+		}
+	}
+
+	// Commit the transaction
+	return tx.Commit(ctx)
+}
+
+func streamRows(rows pgx.Rows, opts QueryOptions, result *QueryResult, handler RowHandler) error {
+	fieldDescriptions := rows.FieldDescriptions()
+
+	for _, fd := range fieldDescriptions {
+		result.Columns = append(result.Columns, fd.Name)
+	}
+
+	skipped := 0
+
+	for rows.Next() {
+		if skipped < opts.Offset {
+			skipped++
+			continue
+		}
+
+		if result.RowCount >= opts.MaxRows {
+			result.Truncated = true
+			break
+		}
+
+		row, err := scanRow(rows)
+		if err != nil {
+			return err
+		}
+
+		err = handler(row)
+		if err != nil {
+			return err
+		}
+
+		result.RowCount++
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("row iteration failed: %w", rows.Err())
+	}
+
+	return nil
+}
+
+func scanRow(rows pgx.Rows) (*orderedmap.OrderedMap[string, any], error) {
+	fieldDescriptions := rows.FieldDescriptions()
+
+	values, err := rows.Values()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	row := orderedmap.New[string, any]()
+
+	for i, val := range values {
+		// format values if necessary
+		val, err = formatValue(val)
+		if err != nil {
+			return nil, err
+		}
+
+		row.Set(fieldDescriptions[i].Name, val)
+	}
+
+	return row, nil
+}
@@ -0,0 +1,269 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// fakeRows returns a single int column named id, failing with err once the rows run out
+type fakeRows struct {
+	values []int
+	next   int
+	err    error
+}
+
+var _ pgx.Rows = (*fakeRows)(nil)
+
+func (f *fakeRows) Close()                        {}
+func (f *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (f *fakeRows) RawValues() [][]byte           { return nil }
+func (f *fakeRows) Conn() *pgx.Conn               { return nil }
+func (f *fakeRows) Scan(dest ...any) error        { return nil }
+
+func (f *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	return []pgconn.FieldDescription{{Name: "id"}}
+}
+
+func (f *fakeRows) Next() bool {
+	if f.next >= len(f.values) {
+		return false
+	}
+
+	f.next++
+
+	return true
+}
+
+func (f *fakeRows) Values() ([]any, error) {
+	return []any{f.values[f.next-1]}, nil
+}
+
+// Err only reports the error once every row has been read, like a failure part way through a result set
+func (f *fakeRows) Err() error {
+	if f.next < len(f.values) {
+		return nil
+	}
+
+	return f.err
+}
+
+func newTestServer() *LocalSqlServer {
+	return &LocalSqlServer{
+		runningQueries: map[string]context.CancelFunc{},
+	}
+}
+
+// rowsExecutor passes the rows to the final statement, then returns err as if the transaction failed to commit
+func rowsExecutor(rows *fakeRows, err error) statementExecutor {
+	return func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+		if handlerErr := handler(rows, true); handlerErr != nil {
+			return handlerErr
+		}
+
+		return err
+	}
+}
+
+// blockingExecutor waits until the query's context is done, like a long running statement
+func blockingExecutor(running chan<- struct{}) statementExecutor {
+	return func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+		close(running)
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+}
+
+func collectIds(ids *[]int) RowHandler {
+	return func(row *orderedmap.OrderedMap[string, any]) error {
+		id, _ := row.Get("id")
+		*ids = append(*ids, id.(int))
+
+		return nil
+	}
+}
+
+func TestStreamQueryRows(t *testing.T) {
+	errCommit := errors.New("commit failed")
+
+	tests := []struct {
+		name          string
+		rows          []int
+		rowsErr       error
+		execErr       error
+		opts          QueryOptions
+		wantIds       []int
+		wantRowCount  int
+		wantTruncated bool
+		wantErr       error
+	}{
+		{
+			name:         "returns every row under the cap",
+			rows:         []int{1, 2, 3},
+			opts:         QueryOptions{MaxRows: 5},
+			wantIds:      []int{1, 2, 3},
+			wantRowCount: 3,
+		},
+		{
+			name:          "truncates rows over the cap",
+			rows:          []int{1, 2, 3, 4},
+			opts:          QueryOptions{MaxRows: 2},
+			wantIds:       []int{1, 2},
+			wantRowCount:  2,
+			wantTruncated: true,
+		},
+		{
+			name:         "skips rows before the offset",
+			rows:         []int{1, 2, 3},
+			opts:         QueryOptions{MaxRows: 2, Offset: 1},
+			wantIds:      []int{2, 3},
+			wantRowCount: 2,
+		},
+		{
+			name:         "returns the streamed rows with a failure part way through the rows",
+			rows:         []int{1, 2},
+			rowsErr:      errors.New("connection reset"),
+			wantIds:      []int{1, 2},
+			wantRowCount: 2,
+			wantErr:      errors.New("row iteration failed: connection reset"),
+		},
+		{
+			name:         "returns the streamed rows with a failure after the rows",
+			rows:         []int{1},
+			execErr:      errCommit,
+			wantIds:      []int{1},
+			wantRowCount: 1,
+			wantErr:      errCommit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int{}
+
+			result, err := newTestServer().streamQuery(context.Background(), "select id from things", tt.opts, collectIds(&ids), rowsExecutor(&fakeRows{values: tt.rows, err: tt.rowsErr}, tt.execErr))
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("streamQuery() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if result == nil {
+				t.Fatalf("streamQuery() result = nil, want the streamed rows to be described")
+			}
+
+			if diff := cmp.Diff(tt.wantIds, ids); diff != "" {
+				t.Errorf("streamQuery() rows mismatch (-want +got):\n%s", diff)
+			}
+
+			if result.RowCount != tt.wantRowCount || result.Truncated != tt.wantTruncated {
+				t.Errorf("streamQuery() rowCount = %d, truncated = %t, want %d, %t", result.RowCount, result.Truncated, tt.wantRowCount, tt.wantTruncated)
+			}
+
+			if diff := cmp.Diff([]string{"id"}, result.Columns); diff != "" {
+				t.Errorf("streamQuery() columns mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStreamQueryTimeout(t *testing.T) {
+	running := make(chan struct{})
+
+	_, err := newTestServer().streamQuery(context.Background(), "select pg_sleep(10)", QueryOptions{Timeout: 10 * time.Millisecond}, collectIds(&[]int{}), blockingExecutor(running))
+	if !errors.Is(err, ErrQueryTimeout) {
+		t.Fatalf("streamQuery() error = %v, want %v", err, ErrQueryTimeout)
+	}
+}
+
+func TestStreamQueryStatementTimeout(t *testing.T) {
+	exec := func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+		return &pgconn.PgError{Code: pgQueryCanceled, Message: "canceling statement due to statement timeout"}
+	}
+
+	_, err := newTestServer().streamQuery(context.Background(), "select pg_sleep(10)", QueryOptions{}, collectIds(&[]int{}), exec)
+	if !errors.Is(err, ErrQueryTimeout) {
+		t.Fatalf("streamQuery() error = %v, want %v", err, ErrQueryTimeout)
+	}
+}
+
+func TestStreamQueryCancel(t *testing.T) {
+	server := newTestServer()
+	running := make(chan struct{})
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := server.streamQuery(context.Background(), "select pg_sleep(10)", QueryOptions{QueryId: "query-1"}, collectIds(&[]int{}), blockingExecutor(running))
+		result <- err
+	}()
+
+	<-running
+
+	if !server.CancelQuery("query-1") {
+		t.Fatalf("CancelQuery() = false, want the running query to be cancelled")
+	}
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrQueryCancelled) {
+			t.Fatalf("streamQuery() error = %v, want %v", err, ErrQueryCancelled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("streamQuery() didn't return after being cancelled")
+	}
+
+	if server.CancelQuery("query-1") {
+		t.Errorf("CancelQuery() = true for a query that has finished")
+	}
+}
+
+func TestStreamQueryOffsetRequiresSingleStatement(t *testing.T) {
+	exec := func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+		t.Fatalf("statements executed for a query that can't be paginated")
+		return nil
+	}
+
+	_, err := newTestServer().streamQuery(context.Background(), "insert into things values (1); select id from things", QueryOptions{Offset: 10}, collectIds(&[]int{}), exec)
+	if !errors.Is(err, ErrOffsetNotSupported) {
+		t.Fatalf("streamQuery() error = %v, want %v", err, ErrOffsetNotSupported)
+	}
+}
+
+func TestStreamQueryOffsetIsReadOnly(t *testing.T) {
+	var accessMode pgx.TxAccessMode
+
+	exec := func(ctx context.Context, statements []string, txOptions pgx.TxOptions, timeout time.Duration, handler statementHandler) error {
+		accessMode = txOptions.AccessMode
+		return handler(&fakeRows{}, true)
+	}
+
+	_, err := newTestServer().streamQuery(context.Background(), "select id from things", QueryOptions{Offset: 10}, collectIds(&[]int{}), exec)
+	if err != nil {
+		t.Fatalf("streamQuery() error = %v", err)
+	}
+
+	if accessMode != pgx.ReadOnly {
+		t.Errorf("streamQuery() access mode = %q, want %q", accessMode, pgx.ReadOnly)
+	}
+}
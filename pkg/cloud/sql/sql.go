@@ -24,6 +24,7 @@ import (
 	"net"
 	"net/netip"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/asaskevich/EventBus"
@@ -72,12 +73,16 @@ type LocalSqlServer struct {
 	projectName          string
 	containerId          string
 	connectionStringHost string
+	host                 string
 	port                 int
 	State                State
 	sqlpb.UnimplementedSqlServer
 
 	migrationRunner MigrationRunner
 
+	queriesLock    sync.Mutex
+	runningQueries map[string]context.CancelFunc
+
 	bus EventBus.Bus
 }
 
@@ -103,7 +108,7 @@ func (l *LocalSqlServer) GetState() State {
 func (l *LocalSqlServer) ensureDatabaseExists(databaseName string) (string, error) {
 	// Ensure the database exists
 	// Connect to the PostgreSQL instance
-	conn, err := pgx.Connect(context.Background(), fmt.Sprintf("user=postgres password=localsecret host=localhost port=%d dbname=postgres sslmode=disable", l.port))
+	conn, err := pgx.Connect(context.Background(), fmt.Sprintf("user=postgres password=localsecret host=%s port=%d dbname=postgres sslmode=disable", l.host, l.port))
 	if err != nil {
 		return "", err
 	}
@@ -194,6 +199,11 @@ func (l *LocalSqlServer) start() error {
 }
 
 func (l *LocalSqlServer) Stop() error {
+	if l.containerId == "" {
+		// Using an external server, nothing to stop
+		return nil
+	}
+
 	dockerClient, err := docker.New()
 	if err != nil {
 		return err
@@ -221,58 +231,30 @@ func (l *LocalSqlServer) ConnectionString(ctx context.Context, req *sqlpb.SqlCon
 	}, nil
 }
 
-// create a function that will execute a query on the local database
+// Query executes a query on the local database, returning all rows of the last statement that produced any rows.
+// Unlike StreamQuery the results are neither capped nor subject to a timeout.
 func (l *LocalSqlServer) Query(ctx context.Context, connectionString string, query string) ([]*orderedmap.OrderedMap[string, any], error) {
-	// Connect to the PostgreSQL instance using the provided connection string
-	conn, err := pgx.Connect(ctx, connectionString)
-	if err != nil {
-		return nil, err
-	}
-
-	defer conn.Close(ctx)
-
-	// Begin transaction
-	tx, err := conn.Begin(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Split commands from string
-	commands := SQLSplit(query)
-
 	results := []*orderedmap.OrderedMap[string, any]{}
 
-	// Execute each command
-	for _, command := range commands {
-		command = strings.TrimSpace(command)
-		if command == "" {
-			continue
-		}
+	err := execStatements(ctx, connectionString, splitStatements(query), pgx.TxOptions{}, 0, func(rows pgx.Rows, final bool) error {
+		statementResults := []*orderedmap.OrderedMap[string, any]{}
 
-		rows, err := tx.Query(ctx, command)
-		if err != nil {
-			_ = tx.Rollback(ctx)
+		for rows.Next() {
+			row, err := scanRow(rows)
+			if err != nil {
+				return err
+			}
 
-			return nil, err
+			statementResults = append(statementResults, row)
 		}
 
-		if rows.Next() {
-			// Process the query results
-			results, err = processRows(rows)
-			rows.Close()
-
-			if err != nil {
-				_ = tx.Rollback(ctx)
-
-				return nil, err
-			}
-		} else {
-			rows.Close()
+		if len(statementResults) > 0 {
+			results = statementResults
 		}
-	}
 
-	// Commit the transaction
-	if err := tx.Commit(ctx); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -339,7 +321,13 @@ func (l *LocalSqlServer) RegisterDatabases(lrs resources.LocalResourcesState) {
 	l.Publish(l.State)
 }
 
-func NewLocalSqlServer(projectName string, localResources *resources.LocalResourcesService, migrationRunner MigrationRunner, connectionStringHost string) (*LocalSqlServer, error) {
+// ExternalServer is an already running postgres server used in place of the local database container
+type ExternalServer struct {
+	Host string
+	Port int
+}
+
+func NewLocalSqlServer(projectName string, localResources *resources.LocalResourcesService, migrationRunner MigrationRunner, connectionStringHost string, external *ExternalServer) (*LocalSqlServer, error) {
 	if connectionStringHost == "" {
 		// default to localhost
 		connectionStringHost = "localhost"
@@ -350,12 +338,21 @@ func NewLocalSqlServer(projectName string, localResources *resources.LocalResour
 		State:                make(State),
 		bus:                  EventBus.New(),
 		migrationRunner:      migrationRunner,
+		runningQueries:       map[string]context.CancelFunc{},
 		connectionStringHost: connectionStringHost,
+		host:                 "localhost",
 	}
 
-	err := localSql.start()
-	if err != nil {
-		return nil, err
+	if external != nil {
+		// the external server is reachable at the same address by the local cloud and the services
+		localSql.host = external.Host
+		localSql.port = external.Port
+		localSql.connectionStringHost = external.Host
+	} else {
+		err := localSql.start()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// subscribe to local resources for migrations
@@ -364,75 +361,39 @@ func NewLocalSqlServer(projectName string, localResources *resources.LocalResour
 	return localSql, nil
 }
 
-func processRows(rows pgx.Rows) ([]*orderedmap.OrderedMap[string, any], error) {
-	fieldDescriptions := rows.FieldDescriptions()
-	numColumns := len(fieldDescriptions)
-
-	results := []*orderedmap.OrderedMap[string, any]{}
-
-	for {
-		values := make([]interface{}, numColumns)
-		valuePointers := make([]interface{}, numColumns)
-
-		for i := range values {
-			valuePointers[i] = &values[i]
-		}
-
-		err := rows.Scan(valuePointers...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+func formatValue(val any) (any, error) {
+	switch v := val.(type) {
+	case time.Time:
+		if v.UTC().Hour() == 0 && v.UTC().Minute() == 0 && v.UTC().Second() == 0 {
+			return v.Format("2006-01-02"), nil
 		}
 
-		row := orderedmap.New[string, any]()
-
-		for i, val := range values {
-			// format values if necessary
-			switch v := val.(type) {
-			case time.Time:
-				if v.UTC().Hour() == 0 && v.UTC().Minute() == 0 && v.UTC().Second() == 0 {
-					val = v.Format("2006-01-02")
-				} else {
-					val = v.Format("2006-01-02 15:04:05")
-				}
-			case netip.Prefix:
-				val = v.Addr().String()
-			case net.HardwareAddr:
-				val = v.String()
-			case pgtype.Interval:
-				val = formatInterval(v)
-			case pgtype.Bits:
-				var result string
-				for _, b := range v.Bytes {
-					result += fmt.Sprintf("%08b", b)
-				}
-
-				val = result
-			case []uint8:
-				val = fmt.Sprintf("\\x%s", hex.EncodeToString(v))
-			case [16]uint8:
-				u, err := uuid.FromBytes(v[:])
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse UUID: %w", err)
-				}
-
-				val = u.String()
-			}
-
-			row.Set(fieldDescriptions[i].Name, val)
+		return v.Format("2006-01-02 15:04:05"), nil
+	case netip.Prefix:
+		return v.Addr().String(), nil
+	case net.HardwareAddr:
+		return v.String(), nil
+	case pgtype.Interval:
+		return formatInterval(v), nil
+	case pgtype.Bits:
+		var result string
+		for _, b := range v.Bytes {
+			result += fmt.Sprintf("%08b", b)
 		}
 
-		results = append(results, row)
-
-		if !rows.Next() {
-			break
+		return result, nil
+	case []uint8:
+		return fmt.Sprintf("\\x%s", hex.EncodeToString(v)), nil
+	case [16]uint8:
+		u, err := uuid.FromBytes(v[:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UUID: %w", err)
 		}
-	}
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("row iteration failed: %w", rows.Err())
+		return u.String(), nil
 	}
 
-	return results, nil
+	return val, nil
 }
 
 func formatInterval(interval pgtype.Interval) string {
@@ -18,7 +18,10 @@ package websockets
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -27,6 +30,8 @@ import (
 	"github.com/fasthttp/websocket"
 
 	"github.com/nitrictech/cli/pkg/grpcx"
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	"github.com/nitrictech/cli/pkg/system"
 
 	nitricws "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
 	"github.com/nitrictech/nitric/core/pkg/workers/websockets"
@@ -39,9 +44,29 @@ type (
 
 type State = map[socketName]map[serviceName][]nitricws.WebsocketEventType
 
+// ErrConnectionNotFound is returned when a connection has already been closed or never existed
+var ErrConnectionNotFound = errors.New("connection not found")
+
+type connection struct {
+	conn        *websocket.Conn
+	queryParams map[string][]string
+	connectedAt time.Time
+
+	// websocket connections only support one concurrent writer
+	writeLock sync.Mutex
+}
+
+func (c *connection) write(messageType int, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return c.conn.WriteMessage(messageType, data)
+}
+
 type LocalWebsocketService struct {
 	*websockets.WebsocketManager
-	connections map[string]map[string]*websocket.Conn
+	connections map[string]map[string]*connection
+	config      map[string]localconfig.LocalWebsocketConfiguration
 	state       State
 	lock        sync.RWMutex
 	serversLock sync.RWMutex
@@ -62,13 +87,23 @@ const (
 )
 
 type WebsocketMessage struct {
+	// Data is base64 encoded for binary messages
 	Data         string    `json:"data,omitempty"`
+	Binary       bool      `json:"binary,omitempty"`
+	Broadcast    bool      `json:"broadcast,omitempty"`
 	Time         time.Time `json:"time,omitempty"`
 	ConnectionID string    `json:"connectionId,omitempty"`
 }
 
+type ConnectionInfo struct {
+	ConnectionID string              `json:"connectionId"`
+	QueryParams  map[string][]string `json:"queryParams,omitempty"`
+	ConnectedAt  time.Time           `json:"connectedAt"`
+}
+
 type WebsocketInfo struct {
 	ConnectionCount int                `json:"connectionCount,omitempty"`
+	Connections     []ConnectionInfo   `json:"connections,omitempty"`
 	Messages        []WebsocketMessage `json:"messages,omitempty"`
 }
 
@@ -177,25 +212,107 @@ func (r *LocalWebsocketService) HandleEvents(stream nitricws.WebsocketHandler_Ha
 	return r.WebsocketManager.HandleEvents(peekableStream)
 }
 
-func (r *LocalWebsocketService) RegisterConnection(socket string, connectionId string, connection *websocket.Conn) error {
+func (r *LocalWebsocketService) RegisterConnection(socket string, connectionId string, conn *websocket.Conn, queryParams map[string][]string) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
 	if r.connections[socket] == nil {
-		r.connections[socket] = make(map[string]*websocket.Conn)
+		r.connections[socket] = make(map[string]*connection)
+	}
+
+	r.connections[socket][connectionId] = &connection{
+		conn:        conn,
+		queryParams: queryParams,
+		connectedAt: time.Now(),
 	}
 
-	r.connections[socket][connectionId] = connection
+	r.publishInfo(socket)
 
+	return nil
+}
+
+// connectionInfo must be called with the lock held
+func (r *LocalWebsocketService) connectionInfo(socket string) []ConnectionInfo {
+	connections := []ConnectionInfo{}
+
+	for connectionId, conn := range r.connections[socket] {
+		connections = append(connections, ConnectionInfo{
+			ConnectionID: connectionId,
+			QueryParams:  conn.queryParams,
+			ConnectedAt:  conn.connectedAt,
+		})
+	}
+
+	slices.SortFunc(connections, func(a, b ConnectionInfo) int {
+		return a.ConnectedAt.Compare(b.ConnectedAt)
+	})
+
+	return connections
+}
+
+// publishInfo must be called with the lock held
+func (r *LocalWebsocketService) publishInfo(socket string) {
 	r.publishAction(WebsocketAction[EventItem]{
 		Name: socket,
 		Type: INFO,
 		Event: WebsocketInfo{
 			ConnectionCount: len(r.connections[socket]),
+			Connections:     r.connectionInfo(socket),
 		},
 	})
+}
 
-	return nil
+// GetConnections returns the active connections of a socket, oldest first
+func (r *LocalWebsocketService) GetConnections(socket string) []ConnectionInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.connectionInfo(socket)
+}
+
+func (r *LocalWebsocketService) getConnection(socket string, connectionId string) (*connection, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	conn, ok := r.connections[socket][connectionId]
+	if !ok {
+		return nil, fmt.Errorf("could not get connection %s: %w", connectionId, ErrConnectionNotFound)
+	}
+
+	return conn, nil
+}
+
+// messageType returns the frame type to send data as
+func (r *LocalWebsocketService) messageType(socket string, binary bool) int {
+	if !binary {
+		return websocket.TextMessage
+	}
+
+	if r.config[socket].AwsCompatWarnings {
+		system.Log(fmt.Sprintf("websocket %s: binary messages are not currently supported by AWS API Gateway websockets", socket))
+	}
+
+	return websocket.BinaryMessage
+}
+
+func (r *LocalWebsocketService) publishMessage(socket string, connectionId string, data []byte, binary bool, broadcast bool) {
+	message := WebsocketMessage{
+		Data:         string(data),
+		Broadcast:    broadcast,
+		Time:         time.Now(),
+		ConnectionID: connectionId,
+	}
+
+	if binary {
+		message.Data = base64.StdEncoding.EncodeToString(data)
+		message.Binary = true
+	}
+
+	r.publishAction(WebsocketAction[EventItem]{
+		Name:  socket,
+		Type:  MESSAGE,
+		Event: message,
+	})
 }
 
 func (r *LocalWebsocketService) SocketDetails(ctx context.Context, req *nitricws.WebsocketDetailsRequest) (*nitricws.WebsocketDetailsResponse, error) {
@@ -209,39 +326,70 @@ func (r *LocalWebsocketService) SocketDetails(ctx context.Context, req *nitricws
 	}, nil
 }
 
+// SendMessage sends a message from a worker to a connection.
+//
+// Workers can't specify the frame type, so messages are sent as text frames unless the socket is configured to send binary frames.
+// The websocket API doesn't offer workers a broadcast either, they send to each connection and broadcasts are sent from the dashboard.
 func (r *LocalWebsocketService) SendMessage(ctx context.Context, req *nitricws.WebsocketSendRequest) (*nitricws.WebsocketSendResponse, error) {
-	r.lock.RLock()
-	defer r.lock.RUnlock()
+	binary := r.config[req.SocketName].BinaryWorkerMessages
 
-	conn, ok := r.connections[req.SocketName][req.ConnectionId]
-	if !ok {
-		return nil, fmt.Errorf("could not get connection " + req.ConnectionId)
+	if !binary && !utf8.Valid(req.Data) {
+		system.Log(fmt.Sprintf("websocket %s: a message that isn't valid UTF-8 is being sent as a text frame, set binaryWorkerMessages in local.nitric.yaml to send binary frames", req.SocketName))
+	}
+
+	err := r.Send(req.SocketName, req.ConnectionId, req.Data, binary)
+	if err != nil {
+		return nil, err
 	}
 
-	// Determine if the message is a binary message
-	isBinary := isBinaryString(req.Data)
+	return &nitricws.WebsocketSendResponse{}, nil
+}
 
-	if isBinary {
-		// binary is not supported by AWS, so tell user
-		req.Data = []byte("Binary messages are not currently supported by AWS")
+// Send sends a message to a connection as either a binary or text frame
+func (r *LocalWebsocketService) Send(socket string, connectionId string, data []byte, binary bool) error {
+	conn, err := r.getConnection(socket, connectionId)
+	if err != nil {
+		return err
 	}
 
-	err := conn.WriteMessage(websocket.TextMessage, req.Data)
+	err = conn.write(r.messageType(socket, binary), data)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	r.publishAction(WebsocketAction[EventItem]{
-		Name: req.SocketName,
-		Type: MESSAGE,
-		Event: WebsocketMessage{
-			Data:         string(req.Data),
-			Time:         time.Now(),
-			ConnectionID: req.ConnectionId,
-		},
-	})
+	r.publishMessage(socket, connectionId, data, binary, false)
 
-	return &nitricws.WebsocketSendResponse{}, nil
+	return nil
+}
+
+// Broadcast sends a message to every active connection of a socket as either a binary or text frame, returning the number of connections it was sent to
+func (r *LocalWebsocketService) Broadcast(socket string, data []byte, binary bool) (int, error) {
+	r.lock.RLock()
+	connections := make([]*connection, 0, len(r.connections[socket]))
+
+	for _, conn := range r.connections[socket] {
+		connections = append(connections, conn)
+	}
+	r.lock.RUnlock()
+
+	messageType := r.messageType(socket, binary)
+	sent := 0
+
+	var errs []error
+
+	for _, conn := range connections {
+		err := conn.write(messageType, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		sent++
+	}
+
+	r.publishMessage(socket, "", data, binary, true)
+
+	return sent, errors.Join(errs...)
 }
 
 func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitricws.WebsocketCloseConnectionRequest) (*nitricws.WebsocketCloseConnectionResponse, error) {
@@ -250,11 +398,11 @@ func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitric
 
 	conn, ok := r.connections[req.SocketName][req.ConnectionId]
 	if !ok {
-		return nil, fmt.Errorf("could not get connection")
+		return nil, fmt.Errorf("could not get connection %s: %w", req.ConnectionId, ErrConnectionNotFound)
 	}
 
 	// force close the connection
-	err := conn.Close()
+	err := conn.conn.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -262,27 +410,18 @@ func (r *LocalWebsocketService) CloseConnection(ctx context.Context, req *nitric
 	// delete the connection from the pool
 	delete(r.connections[req.SocketName], req.ConnectionId)
 
-	r.publishAction(WebsocketAction[EventItem]{
-		Name: req.SocketName,
-		Type: INFO,
-		Event: WebsocketInfo{
-			ConnectionCount: len(r.connections[req.SocketName]),
-		},
-	})
+	r.publishInfo(req.SocketName)
 
 	return &nitricws.WebsocketCloseConnectionResponse{}, nil
 }
 
-func NewLocalWebsocketService() (*LocalWebsocketService, error) {
+func NewLocalWebsocketService(config map[string]localconfig.LocalWebsocketConfiguration) (*LocalWebsocketService, error) {
 	return &LocalWebsocketService{
 		WebsocketManager: websockets.NewWebsocketManager(),
-		connections:      make(map[string]map[string]*websocket.Conn),
+		connections:      make(map[string]map[string]*connection),
+		config:           config,
 		lock:             sync.RWMutex{},
 		state:            make(map[string]map[string][]nitricws.WebsocketEventType),
 		bus:              EventBus.New(),
 	}, nil
 }
-
-func isBinaryString(data []byte) bool {
-	return !utf8.Valid(data)
-}
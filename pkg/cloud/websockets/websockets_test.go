@@ -0,0 +1,268 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websockets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+	nitricws "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
+)
+
+// connect opens a client connection to the socket, returning once the service has registered it
+func connect(t *testing.T, service *LocalWebsocketService, socket string, connectionId string) *websocket.Conn {
+	t.Helper()
+
+	registered := make(chan error, 1)
+
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			registered <- err
+			return
+		}
+
+		registered <- service.RegisterConnection(socket, connectionId, conn, map[string][]string{})
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	if err := <-registered; err != nil {
+		t.Fatalf("RegisterConnection() error = %v", err)
+	}
+
+	return client
+}
+
+func readMessage(t *testing.T, client *websocket.Conn) (int, string) {
+	t.Helper()
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	messageType, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	return messageType, string(data)
+}
+
+func newTestService(t *testing.T, config map[string]localconfig.LocalWebsocketConfiguration) *LocalWebsocketService {
+	t.Helper()
+
+	service, err := NewLocalWebsocketService(config)
+	if err != nil {
+		t.Fatalf("NewLocalWebsocketService() error = %v", err)
+	}
+
+	return service
+}
+
+func TestSendConcurrently(t *testing.T) {
+	service := newTestService(t, nil)
+	client := connect(t, service, "chat", "conn-1")
+
+	const messages = 50
+
+	var wg sync.WaitGroup
+
+	for i := range messages {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := service.Send("chat", "conn-1", []byte(fmt.Sprintf("message %d", i)), false); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	got := []string{}
+	want := []string{}
+
+	for i := range messages {
+		_, data := readMessage(t, client)
+		got = append(got, data)
+		want = append(want, fmt.Sprintf("message %d", i))
+	}
+
+	sort.Strings(got)
+	sort.Strings(want)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("received messages mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	service := newTestService(t, nil)
+
+	clients := []*websocket.Conn{
+		connect(t, service, "chat", "conn-1"),
+		connect(t, service, "chat", "conn-2"),
+		connect(t, service, "chat", "conn-3"),
+	}
+
+	// connections to other sockets don't receive the broadcast
+	other := connect(t, service, "other", "conn-4")
+
+	sent, err := service.Broadcast("chat", []byte{0xff, 0x00}, true)
+	if err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+
+	if sent != len(clients) {
+		t.Errorf("Broadcast() sent = %d, want %d", sent, len(clients))
+	}
+
+	for _, client := range clients {
+		messageType, data := readMessage(t, client)
+		if messageType != websocket.BinaryMessage || data != string([]byte{0xff, 0x00}) {
+			t.Errorf("received type %d %q, want a binary frame with the broadcast data", messageType, data)
+		}
+	}
+
+	_ = other.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, _, err := other.ReadMessage(); err == nil {
+		t.Errorf("connection to another socket received the broadcast")
+	}
+}
+
+func TestSendMessageFrameType(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   localconfig.LocalWebsocketConfiguration
+		data     []byte
+		wantType int
+	}{
+		{
+			name:     "sends worker messages as text frames by default",
+			data:     []byte("hello"),
+			wantType: websocket.TextMessage,
+		},
+		{
+			name:     "sends worker messages as binary frames when configured",
+			config:   localconfig.LocalWebsocketConfiguration{BinaryWorkerMessages: true},
+			data:     []byte("hello"),
+			wantType: websocket.BinaryMessage,
+		},
+		{
+			name:     "passes binary data through unchanged",
+			config:   localconfig.LocalWebsocketConfiguration{BinaryWorkerMessages: true},
+			data:     []byte{0xff, 0xfe, 0x00},
+			wantType: websocket.BinaryMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestService(t, map[string]localconfig.LocalWebsocketConfiguration{"chat": tt.config})
+			client := connect(t, service, "chat", "conn-1")
+
+			_, err := service.SendMessage(context.Background(), &nitricws.WebsocketSendRequest{
+				SocketName:   "chat",
+				ConnectionId: "conn-1",
+				Data:         tt.data,
+			})
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+
+			messageType, data := readMessage(t, client)
+			if messageType != tt.wantType {
+				t.Errorf("message type = %d, want %d", messageType, tt.wantType)
+			}
+
+			if data != string(tt.data) {
+				t.Errorf("data = %q, want %q", data, tt.data)
+			}
+		})
+	}
+}
+
+func TestConnectionNotFound(t *testing.T) {
+	service := newTestService(t, nil)
+	connect(t, service, "chat", "conn-1")
+
+	_, err := service.CloseConnection(context.Background(), &nitricws.WebsocketCloseConnectionRequest{SocketName: "chat", ConnectionId: "conn-1"})
+	if err != nil {
+		t.Fatalf("CloseConnection() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "Send to a closed connection",
+			call: func() error {
+				return service.Send("chat", "conn-1", []byte("hello"), false)
+			},
+		},
+		{
+			name: "SendMessage to an unknown connection",
+			call: func() error {
+				_, err := service.SendMessage(context.Background(), &nitricws.WebsocketSendRequest{SocketName: "chat", ConnectionId: "missing", Data: []byte("hello")})
+				return err
+			},
+		},
+		{
+			name: "CloseConnection of a closed connection",
+			call: func() error {
+				_, err := service.CloseConnection(context.Background(), &nitricws.WebsocketCloseConnectionRequest{SocketName: "chat", ConnectionId: "conn-1"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrConnectionNotFound) {
+				t.Errorf("error = %v, want %v", err, ErrConnectionNotFound)
+			}
+		})
+	}
+
+	if connections := service.GetConnections("chat"); len(connections) != 0 {
+		t.Errorf("GetConnections() = %v, want none after closing", connections)
+	}
+}
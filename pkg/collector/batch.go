@@ -39,6 +39,10 @@ import (
 type BatchRequirements struct {
 	batchName string
 	batchFile string
+	// imageUri is the image the batch is deployed from
+	imageUri string
+	// env is the batch's own env, which overrides the project's env in the spec
+	env map[string]string
 
 	resourceLock sync.Mutex
 
@@ -262,10 +266,12 @@ func (s *BatchRequirements) Subscribe(stream topicspb.Subscriber_SubscribeServer
 	})
 }
 
-func NewBatchRequirements(serviceName string, serviceFile string) *BatchRequirements {
+func NewBatchRequirements(serviceName string, serviceFile string, imageUri string, env map[string]string) *BatchRequirements {
 	requirements := &BatchRequirements{
 		batchName:      serviceName,
 		batchFile:      serviceFile,
+		imageUri:       imageUri,
+		env:            env,
 		resourceLock:   sync.Mutex{},
 		jobHandlers:    make(map[string]*batchpb.RegistrationRequest),
 		jobs:           make(map[string]*resourcespb.JobResource),
@@ -0,0 +1,299 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/proto"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+const (
+	SpecChangeAdded   = "added"
+	SpecChangeRemoved = "removed"
+	SpecChangeChanged = "changed"
+)
+
+// SpecChange is a resource or policy that differs between two specs
+type SpecChange struct {
+	Change  string   `json:"change"`
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Details []string `json:"details,omitempty"`
+}
+
+type SpecDiff struct {
+	Changes []SpecChange `json:"changes"`
+}
+
+func (d *SpecDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// String returns a human-readable summary of the changes
+func (d *SpecDiff) String() string {
+	if !d.HasChanges() {
+		return "No changes\n"
+	}
+
+	symbols := map[string]string{
+		SpecChangeAdded:   "+",
+		SpecChangeRemoved: "-",
+		SpecChangeChanged: "~",
+	}
+
+	sb := strings.Builder{}
+
+	for _, change := range d.Changes {
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", symbols[change.Change], change.Type, change.Name))
+
+		for _, detail := range change.Details {
+			sb.WriteString(fmt.Sprintf("    %s\n", detail))
+		}
+	}
+
+	counts := lo.CountValuesBy(d.Changes, func(c SpecChange) string { return c.Change })
+
+	sb.WriteString(fmt.Sprintf("\n%d added, %d removed, %d changed\n", counts[SpecChangeAdded], counts[SpecChangeRemoved], counts[SpecChangeChanged]))
+
+	return sb.String()
+}
+
+func resourceEnv(res *deploymentspb.Resource) map[string]string {
+	if res.GetService() != nil {
+		return res.GetService().GetEnv()
+	}
+
+	return res.GetBatch().GetEnv()
+}
+
+// withoutEnv returns a copy of the resource without environment variables, which are compared separately
+func withoutEnv(res *deploymentspb.Resource) *deploymentspb.Resource {
+	res = proto.Clone(res).(*deploymentspb.Resource)
+
+	if res.GetService() != nil {
+		res.GetService().Env = nil
+	}
+
+	if res.GetBatch() != nil {
+		res.GetBatch().Env = nil
+	}
+
+	return res
+}
+
+// diffEnv lists the environment variables that changed, without their values as they may be sensitive
+func diffEnv(oldEnv map[string]string, newEnv map[string]string) []string {
+	details := []string{}
+
+	for _, key := range lo.Union(lo.Keys(oldEnv), lo.Keys(newEnv)) {
+		oldVal, inOld := oldEnv[key]
+		newVal, inNew := newEnv[key]
+
+		switch {
+		case !inOld:
+			details = append(details, fmt.Sprintf("env %s added", key))
+		case !inNew:
+			details = append(details, fmt.Sprintf("env %s removed", key))
+		case oldVal != newVal:
+			details = append(details, fmt.Sprintf("env %s changed", key))
+		}
+	}
+
+	sort.Strings(details)
+
+	return details
+}
+
+// graphTriggers returns descriptions of the triggers from each resource, keyed by graph node id
+func graphTriggers(graph *Graph) map[string][]string {
+	triggers := map[string][]string{}
+
+	for _, edge := range graph.Edges {
+		if edge.Kind != GraphEdgeTrigger {
+			continue
+		}
+
+		for _, label := range strings.Split(edge.Label, "\n") {
+			triggers[edge.From] = append(triggers[edge.From], fmt.Sprintf("trigger %s -> %s", label, edge.To))
+		}
+	}
+
+	return triggers
+}
+
+func diffLists(oldItems []string, newItems []string) []string {
+	details := []string{}
+
+	for _, item := range newItems {
+		if !lo.Contains(oldItems, item) {
+			details = append(details, item+" added")
+		}
+	}
+
+	for _, item := range oldItems {
+		if !lo.Contains(newItems, item) {
+			details = append(details, item+" removed")
+		}
+	}
+
+	sort.Strings(details)
+
+	return details
+}
+
+func specResources(spec *deploymentspb.Spec) map[string]*deploymentspb.Resource {
+	resources := map[string]*deploymentspb.Resource{}
+
+	for _, res := range spec.GetResources() {
+		if res.GetPolicy() == nil {
+			resources[graphNodeId(res.GetId())] = res
+		}
+	}
+
+	return resources
+}
+
+// specPolicies returns the actions granted by a spec's policies, keyed by their principals and resources.
+// Policy names are derived from their contents, so they can't be used to match policies between specs.
+func specPolicies(spec *deploymentspb.Spec) map[string][]string {
+	policies := map[string][]string{}
+
+	ids := func(resources []*deploymentspb.Resource) string {
+		names := lo.Map(resources, func(res *deploymentspb.Resource, _ int) string {
+			return graphNodeId(res.GetId())
+		})
+
+		sort.Strings(names)
+
+		return strings.Join(names, ", ")
+	}
+
+	for _, res := range spec.GetResources() {
+		policy := res.GetPolicy()
+		if policy == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s -> %s", ids(policy.GetPrincipals()), ids(policy.GetResources()))
+
+		actions := lo.Map(policy.GetActions(), func(action resourcespb.Action, _ int) string {
+			return action.String()
+		})
+
+		policies[key] = lo.Uniq(append(policies[key], actions...))
+	}
+
+	return policies
+}
+
+// DiffSpecs compares two deployment specs, returning the resources and policies that were added, removed or changed
+func DiffSpecs(oldSpec *deploymentspb.Spec, newSpec *deploymentspb.Spec) (*SpecDiff, error) {
+	oldGraph, err := SpecToGraph(oldSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	newGraph, err := SpecToGraph(newSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTriggers, newTriggers := graphTriggers(oldGraph), graphTriggers(newGraph)
+
+	diff := &SpecDiff{Changes: []SpecChange{}}
+
+	oldResources, newResources := specResources(oldSpec), specResources(newSpec)
+
+	for _, key := range lo.Union(lo.Keys(oldResources), lo.Keys(newResources)) {
+		oldRes, inOld := oldResources[key]
+		newRes, inNew := newResources[key]
+
+		res := lo.Ternary(inNew, newRes, oldRes)
+		change := SpecChange{
+			Type: strings.ToLower(res.GetId().GetType().String()),
+			Name: res.GetId().GetName(),
+		}
+
+		switch {
+		case !inOld:
+			change.Change = SpecChangeAdded
+			change.Details = diffLists(nil, newTriggers[key])
+		case !inNew:
+			change.Change = SpecChangeRemoved
+		default:
+			change.Change = SpecChangeChanged
+			change.Details = append(diffEnv(resourceEnv(oldRes), resourceEnv(newRes)), diffLists(oldTriggers[key], newTriggers[key])...)
+
+			if len(change.Details) == 0 {
+				if proto.Equal(withoutEnv(oldRes), withoutEnv(newRes)) {
+					continue
+				}
+
+				change.Details = []string{"configuration changed"}
+			}
+		}
+
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	oldPolicies, newPolicies := specPolicies(oldSpec), specPolicies(newSpec)
+
+	for _, key := range lo.Union(lo.Keys(oldPolicies), lo.Keys(newPolicies)) {
+		oldActions, inOld := oldPolicies[key]
+		newActions, inNew := newPolicies[key]
+
+		change := SpecChange{Type: "policy", Name: key}
+
+		switch {
+		case !inOld:
+			change.Change = SpecChangeAdded
+			change.Details = []string{"actions " + strings.Join(newActions, ", ")}
+		case !inNew:
+			change.Change = SpecChangeRemoved
+			change.Details = []string{"actions " + strings.Join(oldActions, ", ")}
+		default:
+			change.Change = SpecChangeChanged
+			change.Details = lo.Map(diffLists(oldActions, newActions), func(detail string, _ int) string {
+				return "action " + detail
+			})
+
+			if len(change.Details) == 0 {
+				continue
+			}
+		}
+
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		return a.Name < b.Name
+	})
+
+	return diff, nil
+}
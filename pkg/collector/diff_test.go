@@ -0,0 +1,92 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func serviceResource(name string, env map[string]string) *deploymentspb.Resource {
+	return &deploymentspb.Resource{
+		Id:     &resourcespb.ResourceIdentifier{Name: name, Type: resourcespb.ResourceType_Service},
+		Config: &deploymentspb.Resource_Service{Service: &deploymentspb.Service{Env: env}},
+	}
+}
+
+func policyResource(service string, bucket string, actions ...resourcespb.Action) *deploymentspb.Resource {
+	return &deploymentspb.Resource{
+		Id: &resourcespb.ResourceIdentifier{Name: service + bucket, Type: resourcespb.ResourceType_Policy},
+		Config: &deploymentspb.Resource_Policy{Policy: &deploymentspb.Policy{
+			Principals: []*deploymentspb.Resource{{Id: &resourcespb.ResourceIdentifier{Name: service, Type: resourcespb.ResourceType_Service}}},
+			Actions:    actions,
+			Resources:  []*deploymentspb.Resource{{Id: &resourcespb.ResourceIdentifier{Name: bucket, Type: resourcespb.ResourceType_Bucket}}},
+		}},
+	}
+}
+
+func TestDiffSpecs(t *testing.T) {
+	oldSpec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			serviceResource("api", map[string]string{"KEEP": "1", "CHANGE": "a", "REMOVE": "x"}),
+			serviceResource("worker", nil),
+			{Id: &resourcespb.ResourceIdentifier{Name: "updates", Type: resourcespb.ResourceType_Topic}, Config: &deploymentspb.Resource_Topic{Topic: &deploymentspb.Topic{}}},
+			policyResource("api", "images", resourcespb.Action_BucketFileGet),
+		},
+	}
+
+	newSpec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			serviceResource("api", map[string]string{"KEEP": "1", "CHANGE": "b", "ADD": "y"}),
+			{Id: &resourcespb.ResourceIdentifier{Name: "updates", Type: resourcespb.ResourceType_Topic}, Config: &deploymentspb.Resource_Topic{Topic: &deploymentspb.Topic{
+				Subscriptions: []*deploymentspb.SubscriptionTarget{{Target: &deploymentspb.SubscriptionTarget_Service{Service: "api"}}},
+			}}},
+			{Id: &resourcespb.ResourceIdentifier{Name: "images", Type: resourcespb.ResourceType_Bucket}, Config: &deploymentspb.Resource_Bucket{Bucket: &deploymentspb.Bucket{}}},
+			policyResource("api", "images", resourcespb.Action_BucketFileGet, resourcespb.Action_BucketFilePut),
+		},
+	}
+
+	diff, err := DiffSpecs(oldSpec, newSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []SpecChange{
+		{Change: SpecChangeAdded, Type: "bucket", Name: "images", Details: []string{}},
+		{Change: SpecChangeChanged, Type: "policy", Name: "service:api -> bucket:images", Details: []string{"action BucketFilePut added"}},
+		{Change: SpecChangeChanged, Type: "service", Name: "api", Details: []string{"env ADD added", "env CHANGE changed", "env REMOVE removed"}},
+		{Change: SpecChangeRemoved, Type: "service", Name: "worker"},
+		{Change: SpecChangeChanged, Type: "topic", Name: "updates", Details: []string{"trigger subscription -> service:api added"}},
+	}
+
+	if diff := cmp.Diff(expected, diff.Changes); diff != "" {
+		t.Errorf("unexpected changes (-want +got):\n%s", diff)
+	}
+
+	diff, err = DiffSpecs(newSpec, newSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff.HasChanges() {
+		t.Errorf("expected no changes, got %v", diff.Changes)
+	}
+}
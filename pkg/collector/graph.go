@@ -0,0 +1,301 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/samber/lo"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+	storagepb "github.com/nitrictech/nitric/core/pkg/proto/storage/v1"
+)
+
+// GraphNode is a resource in the application graph
+type GraphNode struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// GraphEdge connects two resources, either a trigger (e.g. a topic subscription) or a policy granting a service access to a resource
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+}
+
+const (
+	GraphEdgeTrigger = "trigger"
+	GraphEdgePolicy  = "policy"
+)
+
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func graphNodeId(id *resourcespb.ResourceIdentifier) string {
+	return fmt.Sprintf("%s:%s", strings.ToLower(id.GetType().String()), id.GetName())
+}
+
+func (g *Graph) addNode(id *resourcespb.ResourceIdentifier) string {
+	nodeId := graphNodeId(id)
+
+	if !lo.ContainsBy(g.Nodes, func(n GraphNode) bool { return n.Id == nodeId }) {
+		g.Nodes = append(g.Nodes, GraphNode{
+			Id:   nodeId,
+			Name: id.GetName(),
+			Type: strings.ToLower(id.GetType().String()),
+		})
+	}
+
+	return nodeId
+}
+
+func (g *Graph) addTrigger(from string, service string, label string) {
+	to := graphNodeId(&resourcespb.ResourceIdentifier{Name: service, Type: resourcespb.ResourceType_Service})
+
+	g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Kind: GraphEdgeTrigger, Label: label})
+}
+
+// apiRouteTargets returns the routes of an API's OpenAPI document, keyed by the service that handles them
+func apiRouteTargets(document string) (map[string][]string, error) {
+	doc := &openapi3.T{}
+
+	if err := json.Unmarshal([]byte(document), doc); err != nil {
+		return nil, err
+	}
+
+	targets := map[string][]string{}
+
+	for path, pathItem := range doc.Paths {
+		for method, operation := range pathItem.Operations() {
+			// extensions may be decoded as raw json, so round trip them to read the target
+			extJson, err := json.Marshal(operation.Extensions["x-nitric-target"])
+			if err != nil {
+				return nil, err
+			}
+
+			target := struct {
+				Name string `json:"name"`
+			}{}
+
+			if err := json.Unmarshal(extJson, &target); err != nil || target.Name == "" {
+				continue
+			}
+
+			targets[target.Name] = append(targets[target.Name], fmt.Sprintf("%s %s", method, path))
+		}
+	}
+
+	for _, routes := range targets {
+		sort.Strings(routes)
+	}
+
+	return targets, nil
+}
+
+func blobEventLabel(config *storagepb.RegistrationRequest) string {
+	label := "on " + strings.ToLower(config.GetBlobEventType().String())
+
+	if config.GetKeyPrefixFilter() != "" {
+		label = fmt.Sprintf("%s %s*", label, config.GetKeyPrefixFilter())
+	}
+
+	return label
+}
+
+// SpecToGraph converts a deployment spec into a graph of its resources, the triggers between them and the policies granting access to them
+func SpecToGraph(spec *deploymentspb.Spec) (*Graph, error) {
+	graph := &Graph{
+		Nodes: []GraphNode{},
+		Edges: []GraphEdge{},
+	}
+
+	for _, res := range spec.GetResources() {
+		if res.GetPolicy() != nil {
+			continue
+		}
+
+		nodeId := graph.addNode(res.GetId())
+
+		switch config := res.GetConfig().(type) {
+		case *deploymentspb.Resource_Topic:
+			for _, sub := range config.Topic.GetSubscriptions() {
+				graph.addTrigger(nodeId, sub.GetService(), "subscription")
+			}
+		case *deploymentspb.Resource_Bucket:
+			for _, listener := range config.Bucket.GetListeners() {
+				graph.addTrigger(nodeId, listener.GetService(), blobEventLabel(listener.GetConfig()))
+			}
+		case *deploymentspb.Resource_Api:
+			targets, err := apiRouteTargets(config.Api.GetOpenapi())
+			if err != nil {
+				return nil, fmt.Errorf("unable to read routes for api %s: %w", res.GetId().GetName(), err)
+			}
+
+			for service, routes := range targets {
+				graph.addTrigger(nodeId, service, strings.Join(routes, "\n"))
+			}
+		case *deploymentspb.Resource_Http:
+			graph.addTrigger(nodeId, config.Http.GetTarget().GetService(), "proxy")
+		case *deploymentspb.Resource_Schedule:
+			label := "every " + config.Schedule.GetEvery().GetRate()
+			if config.Schedule.GetCron() != nil {
+				label = "cron " + config.Schedule.GetCron().GetExpression()
+			}
+
+			graph.addTrigger(nodeId, config.Schedule.GetTarget().GetService(), label)
+		case *deploymentspb.Resource_Websocket:
+			for label, target := range map[string]*deploymentspb.WebsocketTarget{
+				"connect":    config.Websocket.GetConnectTarget(),
+				"disconnect": config.Websocket.GetDisconnectTarget(),
+				"message":    config.Websocket.GetMessageTarget(),
+			} {
+				if target.GetService() != "" {
+					graph.addTrigger(nodeId, target.GetService(), label)
+				}
+			}
+		case *deploymentspb.Resource_Batch:
+			for _, job := range config.Batch.GetJobs() {
+				jobId := graph.addNode(&resourcespb.ResourceIdentifier{Name: job.GetName(), Type: resourcespb.ResourceType_Job})
+
+				graph.Edges = append(graph.Edges, GraphEdge{From: jobId, To: nodeId, Kind: GraphEdgeTrigger, Label: "job"})
+			}
+		}
+	}
+
+	for _, res := range spec.GetResources() {
+		policy := res.GetPolicy()
+		if policy == nil {
+			continue
+		}
+
+		actions := strings.Join(lo.Map(policy.GetActions(), func(action resourcespb.Action, _ int) string {
+			return action.String()
+		}), ", ")
+
+		for _, principal := range policy.GetPrincipals() {
+			from := graph.addNode(principal.GetId())
+
+			for _, resource := range policy.GetResources() {
+				to := graph.addNode(resource.GetId())
+
+				graph.Edges = append(graph.Edges, GraphEdge{From: from, To: to, Kind: GraphEdgePolicy, Label: actions})
+			}
+		}
+	}
+
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Id < graph.Nodes[j].Id
+	})
+
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+
+		if a.To != b.To {
+			return a.To < b.To
+		}
+
+		return a.Label < b.Label
+	})
+
+	return graph, nil
+}
+
+func quoteDot(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Dot renders the graph in the Graphviz DOT language
+func (g *Graph) Dot() string {
+	sb := strings.Builder{}
+
+	sb.WriteString("digraph nitric {\n")
+	sb.WriteString("  rankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		shape := "ellipse"
+		if node.Type == "service" || node.Type == "batch" {
+			shape = "box"
+		}
+
+		sb.WriteString(fmt.Sprintf("  %s [label=%s, shape=%s];\n", quoteDot(node.Id), quoteDot(fmt.Sprintf("%s\n(%s)", node.Name, node.Type)), shape))
+	}
+
+	for _, edge := range g.Edges {
+		style := "solid"
+		if edge.Kind == GraphEdgePolicy {
+			style = "dashed"
+		}
+
+		sb.WriteString(fmt.Sprintf("  %s -> %s [label=%s, style=%s];\n", quoteDot(edge.From), quoteDot(edge.To), quoteDot(edge.Label), style))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+var notMermaidId = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func quoteMermaid(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s) + `"`
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	ids := map[string]string{}
+
+	sb := strings.Builder{}
+
+	sb.WriteString("flowchart LR\n")
+
+	for i, node := range g.Nodes {
+		// mermaid ids can't contain most punctuation, so suffix them to keep sanitized names unique
+		ids[node.Id] = fmt.Sprintf("%s_%d", notMermaidId.ReplaceAllString(node.Id, "_"), i)
+
+		label := quoteMermaid(fmt.Sprintf("%s\n(%s)", node.Name, node.Type))
+		if node.Type == "service" || node.Type == "batch" {
+			sb.WriteString(fmt.Sprintf("  %s[%s]\n", ids[node.Id], label))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s([%s])\n", ids[node.Id], label))
+		}
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Kind == GraphEdgePolicy {
+			arrow = "-.->"
+		}
+
+		sb.WriteString(fmt.Sprintf("  %s %s|%s| %s\n", ids[edge.From], arrow, quoteMermaid(edge.Label), ids[edge.To]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	deploymentspb "github.com/nitrictech/nitric/core/pkg/proto/deployments/v1"
+	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
+)
+
+func TestSpecToGraph(t *testing.T) {
+	spec := &deploymentspb.Spec{
+		Resources: []*deploymentspb.Resource{
+			{
+				Id:     &resourcespb.ResourceIdentifier{Name: "api", Type: resourcespb.ResourceType_Service},
+				Config: &deploymentspb.Resource_Service{Service: &deploymentspb.Service{}},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "main", Type: resourcespb.ResourceType_Api},
+				Config: &deploymentspb.Resource_Api{Api: &deploymentspb.Api{Document: &deploymentspb.Api_Openapi{
+					Openapi: `{"openapi":"3.0.1","info":{"title":"main","version":"v1"},"paths":{"/hello":{"get":{"x-nitric-target":{"name":"api","type":"function"},"responses":{}}}}}`,
+				}}},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "updates", Type: resourcespb.ResourceType_Topic},
+				Config: &deploymentspb.Resource_Topic{Topic: &deploymentspb.Topic{Subscriptions: []*deploymentspb.SubscriptionTarget{
+					{Target: &deploymentspb.SubscriptionTarget_Service{Service: "api"}},
+				}}},
+			},
+			{
+				Id: &resourcespb.ResourceIdentifier{Name: "api-policy", Type: resourcespb.ResourceType_Policy},
+				Config: &deploymentspb.Resource_Policy{Policy: &deploymentspb.Policy{
+					Principals: []*deploymentspb.Resource{{Id: &resourcespb.ResourceIdentifier{Name: "api", Type: resourcespb.ResourceType_Service}}},
+					Actions:    []resourcespb.Action{resourcespb.Action_BucketFileGet, resourcespb.Action_BucketFilePut},
+					Resources:  []*deploymentspb.Resource{{Id: &resourcespb.ResourceIdentifier{Name: "images", Type: resourcespb.ResourceType_Bucket}}},
+				}},
+			},
+		},
+	}
+
+	graph, err := SpecToGraph(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &Graph{
+		Nodes: []GraphNode{
+			{Id: "api:main", Name: "main", Type: "api"},
+			{Id: "bucket:images", Name: "images", Type: "bucket"},
+			{Id: "service:api", Name: "api", Type: "service"},
+			{Id: "topic:updates", Name: "updates", Type: "topic"},
+		},
+		Edges: []GraphEdge{
+			{From: "api:main", To: "service:api", Kind: GraphEdgeTrigger, Label: "GET /hello"},
+			{From: "service:api", To: "bucket:images", Kind: GraphEdgePolicy, Label: "BucketFileGet, BucketFilePut"},
+			{From: "topic:updates", To: "service:api", Kind: GraphEdgeTrigger, Label: "subscription"},
+		},
+	}
+
+	if diff := cmp.Diff(expected, graph); diff != "" {
+		t.Errorf("unexpected graph (-want +got):\n%s", diff)
+	}
+}
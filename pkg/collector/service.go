@@ -43,6 +43,10 @@ type ServiceRequirements struct {
 	serviceName string
 	serviceType string
 	serviceFile string
+	// imageUri is the image the service is deployed from
+	imageUri string
+	// env is the service's own env, which overrides the project's env in the spec
+	env map[string]string
 
 	resourceLock sync.Mutex
 
@@ -377,7 +381,7 @@ func (s *ServiceRequirements) HandleEvents(stream websocketspb.WebsocketHandler_
 	})
 }
 
-func NewServiceRequirements(serviceName string, serviceFile string, serviceType string) *ServiceRequirements {
+func NewServiceRequirements(serviceName string, serviceFile string, serviceType string, imageUri string, env map[string]string) *ServiceRequirements {
 	if serviceType == "" {
 		serviceType = "default"
 	}
@@ -386,6 +390,8 @@ func NewServiceRequirements(serviceName string, serviceFile string, serviceType
 		serviceName:           serviceName,
 		serviceType:           serviceType,
 		serviceFile:           serviceFile,
+		imageUri:              imageUri,
+		env:                   env,
 		resourceLock:          sync.Mutex{},
 		routes:                make(map[string][]*apispb.RegistrationRequest),
 		schedules:             make(map[string]*schedulespb.RegistrationRequest),
@@ -530,6 +530,13 @@ func openAPIPathAndParams(workerPath string) (string, openapi3.Parameters) {
 	return normalizedPath, params
 }
 
+// OpenApiPath converts a worker route path (e.g. /users/:id) to its OpenAPI path template (e.g. /users/{id})
+func OpenApiPath(workerPath string) string {
+	normalizedPath, _ := openAPIPathAndParams(workerPath)
+
+	return normalizedPath
+}
+
 var notAlphaNumeric, _ = regexp.Compile("[^a-zA-Z0-9]+")
 
 // buildApiRequirements gathers and deduplicates all api requirements
@@ -1143,12 +1150,12 @@ func ServiceRequirementsToSpec(projectName string, environmentVariables map[stri
 				Service: &deploymentspb.Service{
 					Source: &deploymentspb.Service_Image{
 						Image: &deploymentspb.ImageSource{
-							Uri: fmt.Sprintf(serviceRequirements.serviceName),
+							Uri: serviceRequirements.imageUri,
 						},
 					},
 					Workers: int32(serviceRequirements.WorkerCount()),
 					Type:    serviceRequirements.serviceType,
-					Env:     environmentVariables,
+					Env:     lo.Assign(environmentVariables, serviceRequirements.env),
 				},
 			},
 		})
@@ -1164,11 +1171,11 @@ func ServiceRequirementsToSpec(projectName string, environmentVariables map[stri
 				Batch: &deploymentspb.Batch{
 					Source: &deploymentspb.Batch_Image{
 						Image: &deploymentspb.ImageSource{
-							Uri: fmt.Sprintf(batchRequirements.batchName),
+							Uri: batchRequirements.imageUri,
 						},
 					},
 					Type: "default",
-					Env:  environmentVariables,
+					Env:  lo.Assign(environmentVariables, batchRequirements.env),
 					Jobs: lo.Map(lo.Entries(batchRequirements.jobHandlers), func(item lo.Entry[string, *batchpb.RegistrationRequest], idx int) *deploymentspb.Job {
 						return &deploymentspb.Job{
 							Name:         item.Key,
@@ -141,7 +141,8 @@ type PolicySpec struct {
 type ServiceSpec struct {
 	*BaseResourceSpec
 
-	FilePath string `json:"filePath"`
+	FilePath string                   `json:"filePath"`
+	RunState *project.ServiceRunState `json:"runState,omitempty"`
 }
 
 type BatchSpec struct {
@@ -158,11 +159,13 @@ type HttpProxySpec struct {
 
 type Dashboard struct {
 	resourcesLock          sync.Mutex
+	historyLock            sync.Mutex
 	project                *project.Project
 	storageService         *storage.LocalStorageService
 	gatewayService         *gateway.LocalGatewayService
 	databaseService        *sql.LocalSqlServer
 	secretService          *secrets.DevSecretService
+	websocketService       *websockets.LocalWebsocketService
 	apis                   []ApiSpec
 	apiUseHttps            bool
 	apiSecurityDefinitions map[string]map[string]*resourcespb.ApiSecurityDefinitionResource
@@ -216,6 +219,7 @@ type DashboardResponse struct {
 	ApiAddresses        map[string]string     `json:"apiAddresses"`
 	WebsocketAddresses  map[string]string     `json:"websocketAddresses"`
 	HttpWorkerAddresses map[string]string     `json:"httpWorkerAddresses"`
+	IngressAddress      string                `json:"ingressAddress,omitempty"`
 	TriggerAddress      string                `json:"triggerAddress"`
 	StorageAddress      string                `json:"storageAddress"`
 	CurrentVersion      string                `json:"currentVersion"`
@@ -233,6 +237,7 @@ var content embed.FS
 
 func (d *Dashboard) getServices() ([]*ServiceSpec, error) {
 	serviceSpecs := []*ServiceSpec{}
+	runStates := d.project.RunStates().GetState()
 
 	for _, service := range d.project.GetServices() {
 		absPath, err := service.GetAbsoluteFilePath()
@@ -240,12 +245,18 @@ func (d *Dashboard) getServices() ([]*ServiceSpec, error) {
 			return nil, err
 		}
 
-		serviceSpecs = append(serviceSpecs, &ServiceSpec{
+		spec := &ServiceSpec{
 			BaseResourceSpec: &BaseResourceSpec{
 				Name: service.GetFilePath(),
 			},
 			FilePath: absPath,
-		})
+		}
+
+		if state, ok := runStates[service.GetFilePath()]; ok {
+			spec.RunState = &state
+		}
+
+		serviceSpecs = append(serviceSpecs, spec)
 	}
 
 	return serviceSpecs, nil
@@ -271,6 +282,10 @@ func (d *Dashboard) getBatchServices() ([]*BatchSpec, error) {
 	return batchSpecs, nil
 }
 
+func (d *Dashboard) updateRunStates(map[string]project.ServiceRunState) {
+	d.debouncedUpdate()
+}
+
 func (d *Dashboard) updateResources(lrs resources.LocalResourcesState) {
 	d.resourcesLock.Lock()
 	defer d.resourcesLock.Unlock()
@@ -423,10 +438,12 @@ func (d *Dashboard) updateResources(lrs resources.LocalResourcesState) {
 		}
 	}
 
-	for schemeName, apiDefinition := range lrs.ApiSecurityDefinitions.GetAll() {
-		d.apiSecurityDefinitions[apiDefinition.Resource.ApiName] = map[string]*resourcespb.ApiSecurityDefinitionResource{}
+	for key, apiDefinition := range lrs.ApiSecurityDefinitions.GetAll() {
+		if d.apiSecurityDefinitions[apiDefinition.Resource.ApiName] == nil {
+			d.apiSecurityDefinitions[apiDefinition.Resource.ApiName] = map[string]*resourcespb.ApiSecurityDefinitionResource{}
+		}
 
-		d.apiSecurityDefinitions[apiDefinition.Resource.ApiName][schemeName] = apiDefinition.Resource
+		d.apiSecurityDefinitions[apiDefinition.Resource.ApiName][resources.ApiSecurityDefinitionName(key, apiDefinition.Resource)] = apiDefinition.Resource
 	}
 
 	d.refresh()
@@ -724,8 +741,12 @@ func (d *Dashboard) Start() error {
 
 	http.HandleFunc("/api/sql", d.createSqlQueryHandler())
 
+	http.HandleFunc("/api/sql/cancel", d.createSqlCancelHandler())
+
 	http.HandleFunc("/api/secrets", d.createSecretsHandler())
 
+	http.HandleFunc("/api/tokens", d.createApiTokenHandler())
+
 	http.HandleFunc("/api/sql/migrate", d.createApplySqlMigrationsHandler(aferoFs, false))
 
 	// handle websockets
@@ -738,6 +759,10 @@ func (d *Dashboard) Start() error {
 
 	http.HandleFunc("/api/ws-clear-messages", d.handleWebsocketMessagesClear())
 
+	http.HandleFunc("/api/ws-connections", d.createWebsocketConnectionsHandler())
+
+	http.HandleFunc("/api/ws-send", d.createWebsocketSendHandler())
+
 	http.HandleFunc("/api/logs", d.createServiceLogsHandler(d.project))
 
 	d.wsWebSocket.HandleConnect(func(s *melody.Session) {
@@ -837,6 +862,7 @@ func (d *Dashboard) sendStackUpdate() error {
 		ApiAddresses:        d.gatewayService.GetApiAddresses(),
 		WebsocketAddresses:  d.gatewayService.GetWebsocketAddresses(),
 		HttpWorkerAddresses: d.gatewayService.GetHttpWorkerAddresses(),
+		IngressAddress:      d.gatewayService.GetIngressAddress(),
 		TriggerAddress:      d.gatewayService.GetTriggerAddress(),
 		// StorageAddress:      d.storageService.GetStorageEndpoint(),
 		CurrentVersion: currentVersion,
@@ -899,6 +925,7 @@ func New(noBrowser bool, localCloud *cloud.LocalCloud, project *project.Project)
 		gatewayService:         localCloud.Gateway,
 		databaseService:        localCloud.Databases,
 		secretService:          localCloud.Secrets,
+		websocketService:       localCloud.Websockets,
 		apis:                   []ApiSpec{},
 		apiUseHttps:            localCloud.Gateway.ApiTlsCredentials != nil,
 		apiSecurityDefinitions: map[string]map[string]*resourcespb.ApiSecurityDefinitionResource{},
@@ -944,6 +971,9 @@ func New(noBrowser bool, localCloud *cloud.LocalCloud, project *project.Project)
 	localCloud.Http.SubscribeToState(dash.updateHttpProxies)
 	localCloud.Databases.SubscribeToState(dash.updateSqlDatabases)
 
+	// service restarts and health are shown on the architecture diagram
+	project.RunStates().SubscribeToState(dash.updateRunStates)
+
 	// subscribe to history events from gateway
 	localCloud.Apis.SubscribeToAction(dash.handleApiHistory)
 	localCloud.Topics.SubscribeToAction(dash.handleTopicsHistory)
@@ -218,7 +218,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal(expectedResults)
+        expect(interception.response.body.rows).to.deep.equal(expectedResults)
       })
     })
 
@@ -263,7 +263,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal(expectedResults)
+        expect(interception.response.body.rows).to.deep.equal(expectedResults)
       })
     })
 
@@ -310,7 +310,7 @@ describe('Databases Spec', () => {
       cy.wait('@query').then((interception) => {
         // Validate the response
         expect(interception.response.statusCode).to.equal(200)
-        expect(interception.response.body).to.deep.equal([
+        expect(interception.response.body.rows).to.deep.equal([
           {
             id: 1,
             name: `${db}-foo`,
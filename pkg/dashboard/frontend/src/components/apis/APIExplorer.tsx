@@ -28,6 +28,7 @@ import APIResponseContent from './APIResponseContent'
 import CodeEditor from './CodeEditor'
 import APIMenu from './APIMenu'
 import APIHistory from './APIHistory'
+import APITokenGenerator from './APITokenGenerator'
 
 import FileUpload from '../storage/FileUpload'
 
@@ -251,6 +252,18 @@ const APIExplorer = () => {
     { name: 'Body', count: JSONBody ? 1 : undefined },
   ]
 
+  const setAuthorizationHeader = (token: string) => {
+    setRequest((prev) => ({
+      ...prev,
+      headers: [
+        ...prev.headers.filter(
+          ({ key }) => key.toLowerCase() !== 'authorization',
+        ),
+        { key: 'Authorization', value: `Bearer ${token}` },
+      ],
+    }))
+  }
+
   const currentTabName = tabs[currentTabIndex].name
   const currentBodyTab = reqBodyTypes[bodyTabIndex]
 
@@ -541,11 +554,13 @@ const APIExplorer = () => {
                           aria-hidden="true"
                         />
                       </div>
-                      <div className="ml-3 flex-1 md:flex md:justify-between">
+                      <div className="ml-3 flex-1">
                         <p className="text-sm">
                           Security rules have been applied to this API. These
-                          are not enforced locally. For more information, please
-                          visit our{' '}
+                          are enforced locally when <code>apiSecurity</code> is
+                          enabled in <code>local.nitric.yaml</code>, generate a
+                          token below to call secured routes. For more
+                          information, please visit our{' '}
                           <a
                             href="https://nitric.io/docs/apis#api-security"
                             target="_blank"
@@ -556,6 +571,15 @@ const APIExplorer = () => {
                           </a>
                           .
                         </p>
+                        <APITokenGenerator
+                          key={selectedApiEndpoint.api}
+                          api={selectedApiEndpoint.api}
+                          securitySchemes={Object.keys(
+                            selectedApiEndpoint.doc.components
+                              ?.securitySchemes ?? {},
+                          )}
+                          onToken={setAuthorizationHeader}
+                        />
                       </div>
                     </div>
                   </Alert>
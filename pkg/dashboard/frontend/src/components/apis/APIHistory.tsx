@@ -86,7 +86,7 @@ function isJSON(data: string | undefined) {
 }
 
 const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
-  event: { request, response },
+  event: { request, response, validationErrors },
 }) => {
   const [tabIndex, setTabIndex] = useState(0)
 
@@ -94,17 +94,21 @@ const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
 
   const tabs = [{ name: 'Headers' }, { name: 'Response' }]
 
-  const jsonTabs = [...tabs, { name: 'Payload' }]
+  if (isJson) {
+    tabs.push({ name: 'Payload' })
+  }
+
+  if (validationErrors?.length) {
+    tabs.push({ name: 'Validation' })
+  }
+
+  const currentTabName = tabs[tabIndex]?.name
 
   return (
     <div>
-      <Tabs
-        tabs={isJson ? jsonTabs : tabs}
-        index={tabIndex}
-        setIndex={setTabIndex}
-      />
+      <Tabs tabs={tabs} index={tabIndex} setIndex={setTabIndex} />
       <div className="py-5">
-        {tabIndex === 0 && (
+        {currentTabName === 'Headers' && (
           <TableGroup
             headers={['Key', 'Value']}
             rowDataClassName="max-w-[100px]"
@@ -124,7 +128,7 @@ const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
             ]}
           />
         )}
-        {tabIndex === 1 && (
+        {currentTabName === 'Response' && (
           <div className="flex flex-col gap-8">
             <div className="flex flex-col gap-2">
               <p className="text-md font-semibold">Response Data</p>
@@ -134,7 +138,7 @@ const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
             </div>
           </div>
         )}
-        {tabIndex === 2 && (
+        {currentTabName === 'Payload' && (
           <div className="flex flex-col gap-8">
             <div className="flex flex-col gap-2">
               <p className="text-md font-semibold">Request Body</p>
@@ -165,6 +169,23 @@ const ApiHistoryAccordionContent: React.FC<ApiHistoryItem> = ({
             </div>
           </div>
         )}
+        {currentTabName === 'Validation' && (
+          <div className="flex flex-col gap-2">
+            <p className="text-md font-semibold">
+              Violations of the OpenAPI document
+            </p>
+            <ul
+              data-testid="validation-errors"
+              className="list-disc pl-5 font-mono text-sm text-red-600"
+            >
+              {validationErrors?.map((error, idx) => (
+                <li key={idx} className="whitespace-pre-wrap">
+                  {error}
+                </li>
+              ))}
+            </ul>
+          </div>
+        )}
       </div>
     </div>
   )
@@ -0,0 +1,101 @@
+import { useState } from 'react'
+import toast from 'react-hot-toast'
+import { getHost } from '@/lib/utils'
+import { Button } from '../ui/button'
+import { Input } from '../ui/input'
+import {
+  Select,
+  SelectContent,
+  SelectGroup,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from '../ui/select'
+
+interface Props {
+  api: string
+  securitySchemes: string[]
+  onToken: (token: string) => void
+}
+
+const APITokenGenerator: React.FC<Props> = ({
+  api,
+  securitySchemes,
+  onToken,
+}) => {
+  const [scheme, setScheme] = useState(securitySchemes[0])
+  const [scopes, setScopes] = useState('')
+  const [subject, setSubject] = useState('')
+  const [loading, setLoading] = useState(false)
+
+  const generateToken = async () => {
+    setLoading(true)
+
+    const res = await fetch(`http://${getHost()}/api/tokens`, {
+      method: 'POST',
+      body: JSON.stringify({
+        apiName: api,
+        securityName: scheme,
+        subject: subject || undefined,
+        scopes: scopes
+          .split(/[\s,]+/)
+          .map((s) => s.trim())
+          .filter(Boolean),
+      }),
+    })
+
+    if (res.ok) {
+      const { token } = await res.json()
+      onToken(token)
+      toast.success('Added token to the Authorization header')
+    } else {
+      toast.error('Failed to generate token: ' + (await res.text()))
+    }
+
+    setLoading(false)
+  }
+
+  return (
+    <div className="mt-4 flex flex-col gap-2 md:flex-row md:items-center">
+      <Select value={scheme} onValueChange={setScheme}>
+        <SelectTrigger
+          className="md:w-48"
+          data-testid="token-security-definition"
+        >
+          <SelectValue placeholder="Security definition" />
+        </SelectTrigger>
+        <SelectContent>
+          <SelectGroup>
+            {securitySchemes.map((name) => (
+              <SelectItem key={name} value={name}>
+                {name}
+              </SelectItem>
+            ))}
+          </SelectGroup>
+        </SelectContent>
+      </Select>
+      <Input
+        data-testid="token-scopes"
+        placeholder="Scopes, e.g. user.read user.write"
+        value={scopes}
+        onChange={(e) => setScopes(e.target.value)}
+      />
+      <Input
+        data-testid="token-subject"
+        className="md:w-48"
+        placeholder="Subject (optional)"
+        value={subject}
+        onChange={(e) => setSubject(e.target.value)}
+      />
+      <Button
+        data-testid="generate-token-btn"
+        disabled={!scheme || loading}
+        onClick={generateToken}
+      >
+        Generate Token
+      </Button>
+    </div>
+  )
+}
+
+export default APITokenGenerator
@@ -3,9 +3,11 @@ import { type ComponentType } from 'react'
 import type { Edge, NodeProps } from 'reactflow'
 import NodeBase, { type NodeBaseData } from './NodeBase'
 import { Button } from '@/components/ui/button'
+import type { ServiceRunState } from '@/types'
 
 type ServiceData = {
   filePath: string
+  runState?: ServiceRunState
 }
 
 export interface ServiceNodeData extends NodeBaseData<ServiceData> {
@@ -27,6 +29,26 @@ export const ServiceNode: ComponentType<NodeProps<ServiceNodeData>> = (
         icon: Icon,
         nodeType: 'service',
         description: data.description,
+        children: data.resource.runState && (
+          <>
+            <div className="flex flex-col">
+              <span className="font-bold">Status:</span>
+              <span>{data.resource.runState.status}</span>
+            </div>
+            <div className="flex flex-col">
+              <span className="font-bold">Restarts:</span>
+              <span>{data.resource.runState.restarts}</span>
+            </div>
+            {data.resource.runState.lastError && (
+              <div className="flex flex-col">
+                <span className="font-bold">Last Error:</span>
+                <span className="whitespace-pre-wrap break-words">
+                  {data.resource.runState.lastError}
+                </span>
+              </div>
+            )}
+          </>
+        ),
         footerChildren: (
           <Button asChild>
             <a href={`vscode://file/${data.resource.filePath}`}>
@@ -25,6 +25,13 @@ import QueryResults from './QueryResults'
 import { useSqlMeta } from '@/lib/hooks/use-sql-meta'
 import SectionCard from '../shared/SectionCard'
 import NotFoundAlert from '../shared/NotFoundAlert'
+import { SQL_API } from '@/lib/constants'
+import {
+  DropdownMenu,
+  DropdownMenuContent,
+  DropdownMenuItem,
+  DropdownMenuTrigger,
+} from '../ui/dropdown-menu'
 
 interface QueryHistoryItem {
   query: string
@@ -38,6 +45,37 @@ interface QueryHistory {
 
 const DATABASES_STORAGE_KEY = 'nitric-local-dash-database'
 
+const exportResults = async (
+  query: string,
+  connectionString: string,
+  format: 'csv' | 'json',
+) => {
+  const res = await fetch(SQL_API, {
+    method: 'POST',
+    body: JSON.stringify({
+      query,
+      connectionString,
+      format,
+      download: true,
+    }),
+  })
+
+  if (!res.ok) {
+    toast.error('Export failed: ' + (await res.text()))
+    return
+  }
+
+  const blob = await res.blob()
+  const url = URL.createObjectURL(blob)
+  const a = document.createElement('a')
+  a.href = url
+  a.download = `query-results-${new Date().toISOString()}.${format}`
+  document.body.appendChild(a)
+  a.click()
+  document.body.removeChild(a)
+  URL.revokeObjectURL(url)
+}
+
 const getStorageHistory = (): QueryHistory | null => {
   try {
     const storage = localStorage.getItem(DATABASES_STORAGE_KEY)
@@ -60,6 +98,7 @@ const DatabasesExplorer: React.FC = () => {
   const [migrationLoading, setMigrationLoading] = useState(false)
 
   const [response, setResponse] = useState<string>()
+  const [runningQueryId, setRunningQueryId] = useState<string>()
 
   const [selectedDb, setSelectedDb] = useState<SQLDatabase>()
 
@@ -125,12 +164,16 @@ const DatabasesExplorer: React.FC = () => {
       return
     }
 
-    const url = `http://${getHost()}/api/sql`
+    // the query id is generated here so the query can be cancelled while it's running
+    const queryId = crypto.randomUUID()
+    setRunningQueryId(queryId)
+
     const requestOptions: RequestInit = {
       method: 'POST',
       body: JSON.stringify({
         query: sql,
         connectionString: selectedDb.connectionString,
+        queryId,
       }),
       headers: fieldRowArrToHeaders([
         {
@@ -145,10 +188,11 @@ const DatabasesExplorer: React.FC = () => {
     }
 
     const startTime = window.performance.now()
-    const res = await fetch(url, requestOptions)
+    const res = await fetch(SQL_API, requestOptions)
 
     const callResponse = await generateResponse(res, startTime)
     setResponse(callResponse.data)
+    setRunningQueryId(undefined)
 
     // refresh tables in case of DDL changes
     refreshTables()
@@ -156,6 +200,29 @@ const DatabasesExplorer: React.FC = () => {
     setTimeout(() => setCallLoading(false), 300)
   }
 
+  const handleCancel = async () => {
+    if (!runningQueryId) return
+
+    const res = await fetch(
+      `${SQL_API}/cancel?queryId=${encodeURIComponent(runningQueryId)}`,
+      { method: 'POST' },
+    )
+
+    if (!res.ok && res.status !== 404) {
+      toast.error('Failed to cancel query: ' + (await res.text()))
+    }
+  }
+
+  const handleExport = async (format: 'csv' | 'json') => {
+    if (!selectedDb || !sql) return
+
+    const loadingId = toast.loading('Exporting results')
+
+    await exportResults(sql, selectedDb.connectionString, format)
+
+    toast.dismiss(loadingId)
+  }
+
   const handleMigrate = async () => {
     if (!selectedDb) return
 
@@ -381,13 +448,50 @@ const DatabasesExplorer: React.FC = () => {
                         Results
                       </h3>
                     </div>
-                    <Button
-                      size="lg"
-                      data-testid={`run-btn`}
-                      onClick={handleRun}
-                    >
-                      Run
-                    </Button>
+                    <div className="flex items-center gap-x-2">
+                      <DropdownMenu>
+                        <DropdownMenuTrigger asChild>
+                          <Button
+                            size="lg"
+                            variant="outline"
+                            data-testid={`export-btn`}
+                            disabled={!sql || callLoading}
+                          >
+                            Export
+                          </Button>
+                        </DropdownMenuTrigger>
+                        <DropdownMenuContent align="end">
+                          <DropdownMenuItem
+                            onClick={() => handleExport('csv')}
+                          >
+                            Export as CSV
+                          </DropdownMenuItem>
+                          <DropdownMenuItem
+                            onClick={() => handleExport('json')}
+                          >
+                            Export as JSON
+                          </DropdownMenuItem>
+                        </DropdownMenuContent>
+                      </DropdownMenu>
+                      {callLoading && runningQueryId ? (
+                        <Button
+                          size="lg"
+                          variant="destructive"
+                          data-testid={`cancel-btn`}
+                          onClick={handleCancel}
+                        >
+                          Cancel
+                        </Button>
+                      ) : (
+                        <Button
+                          size="lg"
+                          data-testid={`run-btn`}
+                          onClick={handleRun}
+                        >
+                          Run
+                        </Button>
+                      )}
+                    </div>
                   </div>
                   <div className="mt-4">
                     <QueryResults response={response} loading={callLoading} />
@@ -20,7 +20,17 @@ interface Result {
   [key: string]: any
 }
 
-const parse = (value: string): Result[] | string => {
+interface QueryResponse {
+  queryId: string
+  rows: Result[]
+  columns: string[]
+  rowCount: number
+  truncated: boolean
+  /** set when the query failed after rows were streamed, the rows were rolled back */
+  error?: string
+}
+
+const parse = (value: string): QueryResponse | string => {
   try {
     return JSON.parse(value)
   } catch (e) {
@@ -68,13 +78,28 @@ const QueryResults: React.FC<QueryResultsProps> = ({ response, loading }) => {
     )
   }
 
-  const rows = parse(response)
+  const result = parse(response)
 
   // if the data is a string after parse, we can assume it's a error response
-  if (typeof rows === 'string') {
+  if (typeof result === 'string') {
     return (
       <Container>
-        <p className="m-0 border-0 px-6 py-4 text-sm">{rows}</p>
+        <p className="m-0 border-0 px-6 py-4 text-sm">{result}</p>
+      </Container>
+    )
+  }
+
+  const { rows, truncated, rowCount, error } = result
+
+  if (error) {
+    return (
+      <Container>
+        <p
+          data-testid="query-results-error"
+          className="m-0 border-0 px-6 py-4 text-sm"
+        >
+          {error}
+        </p>
       </Container>
     )
   }
@@ -184,6 +209,14 @@ const QueryResults: React.FC<QueryResultsProps> = ({ response, loading }) => {
         className="flex-grow border-t-0"
         rowClass={() => '[&>.rdg-cell]:items-center'}
       />
+      {truncated && (
+        <p
+          data-testid="query-results-truncated"
+          className="m-0 border-0 px-6 py-4 text-sm"
+        >
+          Showing the first {rowCount} rows. Export the results to see more.
+        </p>
+      )}
     </Container>
   )
 }
@@ -1,4 +1,6 @@
 import type {
+  BatchHistoryItem,
+  BatchJobStatus,
   EventHistoryItem,
   EventResource,
   TopicHistoryItem,
@@ -7,6 +9,19 @@ import { formatJSON } from '@/lib/utils'
 import CodeEditor from '../apis/CodeEditor'
 import HistoryAccordion from '../shared/HistoryAccordion'
 
+const jobBadges: Record<
+  BatchJobStatus,
+  { status: 'default' | 'blue' | 'green' | 'red'; label: string }
+> = {
+  queued: { status: 'default', label: 'queued' },
+  running: { status: 'blue', label: 'running' },
+  succeeded: { status: 'green', label: 'success' },
+  failed: { status: 'red', label: 'failure' },
+}
+
+const formatDuration = (ms: number) =>
+  ms < 1000 ? `${ms}ms` : `${(ms / 1000).toFixed(2)}s`
+
 interface Props {
   history: EventHistoryItem[]
   selectedWorker: EventResource
@@ -39,22 +54,87 @@ const EventsHistory: React.FC<Props> = ({
 
           const formattedPayload = payload ? formatJSON(payload) : ''
 
+          const job =
+            workerType === 'jobs'
+              ? (h.event as BatchHistoryItem['event'])
+              : undefined
+
+          const sections: React.ReactNode[] = []
+
+          if (job?.id) {
+            sections.push(
+              <div key="id" className="flex flex-col gap-2">
+                <p className="text-md font-semibold">Job ID</p>
+                <p className="font-mono" data-testid="job-id">
+                  {job.id}
+                </p>
+              </div>,
+            )
+          }
+
+          if (job?.error) {
+            sections.push(
+              <div key="error" className="flex flex-col gap-2">
+                <p className="text-md font-semibold">Error</p>
+                <p className="text-red-600" data-testid="job-error">
+                  {job.error}
+                </p>
+              </div>,
+            )
+          }
+
+          if (typeof job?.exitCode === 'number') {
+            sections.push(
+              <div key="exit-code" className="flex flex-col gap-2">
+                <p className="text-md font-semibold">Exit Code</p>
+                <p className="font-mono" data-testid="job-exit-code">
+                  {job.exitCode}
+                  {typeof job.stopStatus === 'number' && (
+                    <span className="ml-2 text-gray-500">
+                      (container stopped with status {job.stopStatus})
+                    </span>
+                  )}
+                </p>
+              </div>,
+            )
+          }
+
+          if (formattedPayload) {
+            sections.push(
+              <div key="payload" className="flex flex-col gap-2">
+                <p className="text-md font-semibold">Payload</p>
+                <CodeEditor
+                  contentType="application/json"
+                  readOnly={true}
+                  value={formattedPayload}
+                  title="Payload"
+                />
+              </div>,
+            )
+          }
+
+          if (job?.logs) {
+            sections.push(
+              <div key="logs" className="flex flex-col gap-2">
+                <p className="text-md font-semibold">Logs</p>
+                <pre
+                  className="max-h-96 overflow-auto whitespace-pre-wrap rounded-md bg-gray-50 p-4 font-mono text-xs"
+                  data-testid="job-logs"
+                >
+                  {job.logs}
+                </pre>
+              </div>,
+            )
+          }
+
           return {
             label: h.event.name,
             time: h.time,
             success: Boolean(h.event.success),
-            content: formattedPayload ? (
-              <div className="flex flex-col gap-8">
-                <div className="flex flex-col gap-2">
-                  <p className="text-md font-semibold">Payload</p>
-                  <CodeEditor
-                    contentType="application/json"
-                    readOnly={true}
-                    value={formattedPayload}
-                    title="Payload"
-                  />
-                </div>
-              </div>
+            badge: job?.status ? jobBadges[job.status] : undefined,
+            detail: job?.duration ? formatDuration(job.duration) : undefined,
+            content: sections.length ? (
+              <div className="flex flex-col gap-8">{sections}</div>
             ) : undefined,
           }
         })}
@@ -8,10 +8,18 @@ import {
 import Badge from './Badge'
 import { getDateString } from '@/lib/utils/get-date-string'
 import { createElement } from 'react'
+import { cn } from '@/lib/utils'
 
 interface HistoryAccordionItem {
   status?: number
   success?: boolean
+  /** overrides the success/status badge, e.g. for in progress items */
+  badge?: {
+    status: React.ComponentProps<typeof Badge>['status']
+    label: string
+  }
+  /** extra detail shown before the time, e.g. a duration */
+  detail?: string
   time: number
   label: string
   content?: React.ReactNode
@@ -36,7 +44,14 @@ const HistoryAccordion = ({ items }: Props) => {
             <AccordionItem key={idx} value={idx.toString()}>
               <TriggerElement className="p-2 !no-underline hover:bg-primary/5">
                 <div className="flex w-full flex-row items-center gap-4 font-body">
-                  {typeof item.success === 'boolean' ? (
+                  {item.badge ? (
+                    <Badge
+                      status={item.badge.status}
+                      className="!text-md h-6 w-12 sm:w-20"
+                    >
+                      {item.badge.label}
+                    </Badge>
+                  ) : typeof item.success === 'boolean' ? (
                     <Badge
                       status={item.success ? 'green' : 'red'}
                       className="!text-md h-6 w-12 sm:w-20"
@@ -59,7 +74,17 @@ const HistoryAccordion = ({ items }: Props) => {
                   <p className="max-w-[200px] truncate text-sm md:max-w-lg">
                     {item.label}
                   </p>
-                  <p className="ml-auto hidden pr-2 text-sm sm:inline">
+                  {item.detail && (
+                    <p className="ml-auto hidden text-sm text-gray-500 sm:inline">
+                      {item.detail}
+                    </p>
+                  )}
+                  <p
+                    className={cn(
+                      'hidden pr-2 text-sm sm:inline',
+                      !item.detail && 'ml-auto',
+                    )}
+                  >
                     {getDateString(item.time)}
                   </p>
                 </div>
@@ -0,0 +1,190 @@
+import { useState } from 'react'
+import toast from 'react-hot-toast'
+import { format } from 'date-fns/format'
+import type { WebSocketInfoData } from '../../types'
+import { getHost } from '../../lib/utils'
+import { Button } from '../ui/button'
+import { Label } from '../ui/label'
+import { Switch } from '../ui/switch'
+import { Textarea } from '../ui/textarea'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from '../ui/select'
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from '../ui/table'
+import SectionCard from '../shared/SectionCard'
+
+const ALL_CONNECTIONS = 'all'
+
+interface Props {
+  socket: string
+  connections: WebSocketInfoData['connections']
+}
+
+const formatQueryParams = (queryParams?: Record<string, string[]>) =>
+  Object.entries(queryParams ?? {})
+    .map(([key, values]) => values.map((value) => `${key}=${value}`).join('&'))
+    .join('&')
+
+const WSConnections: React.FC<Props> = ({ socket, connections = [] }) => {
+  const [target, setTarget] = useState(ALL_CONNECTIONS)
+  const [payload, setPayload] = useState('')
+  const [binary, setBinary] = useState(false)
+
+  const closeConnection = async (connectionId: string) => {
+    const res = await fetch(
+      `http://${getHost()}/api/ws-connections?socket=${encodeURIComponent(
+        socket,
+      )}&connectionId=${encodeURIComponent(connectionId)}`,
+      { method: 'DELETE' },
+    )
+
+    if (res.ok) {
+      toast.success('Connection closed')
+    } else {
+      toast.error('Failed to close connection: ' + (await res.text()))
+    }
+  }
+
+  // fall back to broadcasting once the selected connection has closed
+  const selectedTarget = connections.some((c) => c.connectionId === target)
+    ? target
+    : ALL_CONNECTIONS
+
+  const send = async () => {
+    if (binary) {
+      // binary payloads are entered as base64
+      try {
+        atob(payload)
+      } catch {
+        toast.error('Binary messages must be base64 encoded')
+        return
+      }
+    }
+
+    const res = await fetch(`http://${getHost()}/api/ws-send`, {
+      method: 'POST',
+      body: JSON.stringify({
+        socket,
+        connectionId:
+          selectedTarget === ALL_CONNECTIONS ? undefined : selectedTarget,
+        data: payload,
+        binary,
+      }),
+    })
+
+    if (res.ok) {
+      const { sent } = await res.json()
+      toast.success(`Message sent to ${sent} connection(s)`)
+    } else {
+      toast.error('Failed to send message: ' + (await res.text()))
+    }
+  }
+
+  return (
+    <>
+      <SectionCard className="mt-4" title="Connections">
+        {connections.length ? (
+          <Table data-testid="ws-connections">
+            <TableHeader>
+              <TableRow>
+                <TableHead>Connection ID</TableHead>
+                <TableHead>Connected At</TableHead>
+                <TableHead>Query Params</TableHead>
+                <TableHead />
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {connections.map((conn) => (
+                <TableRow key={conn.connectionId}>
+                  <TableCell className="font-mono">
+                    {conn.connectionId}
+                  </TableCell>
+                  <TableCell>
+                    {format(new Date(conn.connectedAt), 'HH:mm:ss')}
+                  </TableCell>
+                  <TableCell className="max-w-xs truncate font-mono">
+                    {formatQueryParams(conn.queryParams)}
+                  </TableCell>
+                  <TableCell className="text-right">
+                    <Button
+                      variant="outline"
+                      size="sm"
+                      data-testid={`close-connection-${conn.connectionId}`}
+                      onClick={() => closeConnection(conn.connectionId)}
+                    >
+                      Close
+                    </Button>
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        ) : (
+          <span className="text-lg text-gray-500">No active connections.</span>
+        )}
+      </SectionCard>
+
+      <SectionCard
+        className="mt-4"
+        title="Send to Connections"
+        footer={
+          <>
+            <Select value={selectedTarget} onValueChange={setTarget}>
+              <SelectTrigger className="w-[250px]">
+                <SelectValue placeholder="Select Connection" />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value={ALL_CONNECTIONS}>
+                  Broadcast to all connections
+                </SelectItem>
+                {connections.map((conn) => (
+                  <SelectItem key={conn.connectionId} value={conn.connectionId}>
+                    {conn.connectionId}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <div className="flex items-center gap-x-2">
+              <Switch
+                id="binary-message"
+                aria-label="Send as a binary frame"
+                checked={binary}
+                onCheckedChange={setBinary}
+              />
+              <Label htmlFor="binary-message">Binary (base64)</Label>
+            </div>
+            <Button
+              size={'lg'}
+              className="ml-auto"
+              data-testid="broadcast-message-btn"
+              disabled={!payload || !connections.length}
+              onClick={send}
+            >
+              {selectedTarget === ALL_CONNECTIONS ? 'Broadcast' : 'Send'}
+            </Button>
+          </>
+        }
+      >
+        <Textarea
+          placeholder={binary ? 'Enter base64 encoded data' : 'Enter message'}
+          data-testid="broadcast-message-input"
+          value={payload}
+          onChange={(evt) => setPayload(evt.target.value)}
+        />
+      </SectionCard>
+    </>
+  )
+}
+
+export default WSConnections
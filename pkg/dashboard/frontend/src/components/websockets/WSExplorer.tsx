@@ -6,6 +6,7 @@ import { formatJSON, generatePath, getHost } from '../../lib/utils'
 import { useWebSocket } from '../../lib/hooks/use-web-socket'
 import AppLayout from '../layout/AppLayout'
 import WSTreeView from './WSTreeView'
+import WSConnections from './WSConnections'
 import { copyToClipboard } from '../../lib/utils/copy-to-clipboard'
 import toast from 'react-hot-toast'
 import {
@@ -552,6 +553,10 @@ const WSExplorer = () => {
                       )}
                     </div>
                   </SectionCard>
+                  <WSConnections
+                    socket={selectedWebsocket.name}
+                    connections={wsInfo?.connections}
+                  />
                 </TabsContent>
                 <TabsContent value="send-messages" className="space-y-10">
                   <SectionCard className="mt-4" title="Query Params">
@@ -15,7 +15,7 @@ export interface SqlMetaResult {
 }
 
 export const useSqlMeta = (connectionString?: string) => {
-  const { data, mutate } = useSWR<SqlMetaResult[]>(
+  const { data, mutate } = useSWR<{ rows: SqlMetaResult[] }>(
     connectionString ? SQL_API : null,
     fetcher({
       method: 'POST',
@@ -24,7 +24,7 @@ export const useSqlMeta = (connectionString?: string) => {
   )
 
   return {
-    data,
+    data: data?.rows,
     mutate,
     loading: !data,
   }
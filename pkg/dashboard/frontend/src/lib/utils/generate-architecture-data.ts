@@ -578,6 +578,7 @@ export function generateArchitectureData(data: WebSocketResponse): {
         description: '',
         resource: {
           filePath: service.filePath,
+          runState: service.runState,
         },
         icon: CpuChipIcon,
         connectedEdges: [],
@@ -592,6 +593,18 @@ export function generateArchitectureData(data: WebSocketResponse): {
         ? `${connectedEdges.length} connection`
         : `${connectedEdges.length} connections`
 
+    const runState = service.runState
+    if (runState && (runState.status !== 'Running' || runState.restarts > 0)) {
+      node.data.description += ` · ${runState.status}`
+
+      if (runState.restarts > 0) {
+        node.data.description +=
+          runState.restarts === 1
+            ? ' · 1 restart'
+            : ` · ${runState.restarts} restarts`
+      }
+    }
+
     nodes.push(node)
   })
 
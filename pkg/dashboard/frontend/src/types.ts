@@ -44,7 +44,21 @@ export interface Schedule extends BaseResource {
 
 export type Topic = BaseResource
 
-export type Service = BaseResource
+export interface ServiceRunState {
+  status:
+    | 'Running'
+    | 'Done'
+    | 'Error'
+    | 'Restarting'
+    | 'Unhealthy'
+    | 'CrashLoop'
+  restarts: number
+  lastError?: string
+}
+
+export interface Service extends BaseResource {
+  runState?: ServiceRunState
+}
 
 export type Batch = BaseResource
 
@@ -63,12 +77,21 @@ export interface WebSocket extends BaseResource {
   targets: Record<WebsocketEvent, string>
 }
 
+export interface WebSocketConnection {
+  connectionId: string
+  queryParams?: Record<string, string[]>
+  connectedAt: string
+}
+
 export interface WebSocketInfoData {
   connectionCount: number
+  connections?: WebSocketConnection[]
   messages: {
     data: string
     time: string
     connectionId: string
+    binary?: boolean
+    broadcast?: boolean
   }[]
 }
 
@@ -203,10 +226,22 @@ export type TopicHistoryItem = HistoryItem<{
   success: boolean
 }>
 
+export type BatchJobStatus = 'queued' | 'running' | 'succeeded' | 'failed'
+
 export type BatchHistoryItem = HistoryItem<{
+  id?: string
   name: string
   payload: string
+  status?: BatchJobStatus
   success: boolean
+  error?: string
+  /** duration of the job run in milliseconds */
+  duration?: number
+  /** only captured for jobs run in isolated containers */
+  exitCode?: number
+  /** the container's status after it was stopped once the job completed */
+  stopStatus?: number
+  logs?: string
 }>
 
 export type ScheduleHistoryItem = HistoryItem<{
@@ -218,6 +253,7 @@ export type ApiHistoryItem = HistoryItem<{
   api: string
   request: RequestHistory
   response: APIResponse
+  validationErrors?: string[]
 }>
 
 export interface RequestHistory {
@@ -19,22 +19,28 @@ package dashboard
 import (
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"github.com/spf13/afero"
 
 	"github.com/nitrictech/cli/pkg/cloud/apis"
 	"github.com/nitrictech/cli/pkg/cloud/batch"
+	"github.com/nitrictech/cli/pkg/cloud/gateway"
 	"github.com/nitrictech/cli/pkg/cloud/schedules"
+	"github.com/nitrictech/cli/pkg/cloud/sql"
 	"github.com/nitrictech/cli/pkg/cloud/topics"
 	"github.com/nitrictech/cli/pkg/cloud/websockets"
 	base_http "github.com/nitrictech/nitric/cloud/common/runtime/gateway"
@@ -42,6 +48,7 @@ import (
 	resourcespb "github.com/nitrictech/nitric/core/pkg/proto/resources/v1"
 	secretspb "github.com/nitrictech/nitric/core/pkg/proto/secrets/v1"
 	storagepb "github.com/nitrictech/nitric/core/pkg/proto/storage/v1"
+	websocketspb "github.com/nitrictech/nitric/core/pkg/proto/websockets/v1"
 )
 
 func (d *Dashboard) handleStorage() func(http.ResponseWriter, *http.Request) {
@@ -266,6 +273,14 @@ func (d *Dashboard) createSqlQueryHandler() func(http.ResponseWriter, *http.Requ
 		var requestBody struct {
 			Query            string `json:"query"`
 			ConnectionString string `json:"connectionString"`
+			QueryId          string `json:"queryId"`
+			Limit            int    `json:"limit"`
+			Offset           int    `json:"offset"`
+			TimeoutSeconds   int    `json:"timeoutSeconds"`
+			// Format is either json (default) or csv
+			Format string `json:"format"`
+			// Download marks the response as an attachment, used for exporting results
+			Download bool `json:"download"`
 		}
 
 		err := json.NewDecoder(r.Body).Decode(&requestBody)
@@ -274,28 +289,103 @@ func (d *Dashboard) createSqlQueryHandler() func(http.ResponseWriter, *http.Requ
 			return
 		}
 
-		// Execute the SQL query
-		results, err := d.databaseService.Query(context.Background(), requestBody.ConnectionString, requestBody.Query)
+		if requestBody.Format == "" {
+			requestBody.Format = "json"
+		}
+
+		if requestBody.Format != "json" && requestBody.Format != "csv" {
+			http.Error(w, "invalid format, must be json or csv", http.StatusBadRequest)
+			return
+		}
+
+		maxRows := sql.DefaultMaxRows
+
+		if requestBody.Download {
+			maxRows = sql.MaxExportRows
+		}
+
+		if requestBody.Limit <= 0 || requestBody.Limit > maxRows {
+			requestBody.Limit = maxRows
+		}
+
+		// generate the id up front so it can be returned before the query completes
+		if requestBody.QueryId == "" {
+			requestBody.QueryId = uuid.NewString()
+		}
+
+		writer := newSqlResultWriter(w, requestBody.QueryId, requestBody.Format)
+
+		if requestBody.Download {
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="query-results.%s"`, requestBody.Format))
+		}
+
+		// Execute the SQL query, streaming the rows to the response as they're read
+		result, err := d.databaseService.StreamQuery(r.Context(), requestBody.ConnectionString, requestBody.Query, sql.QueryOptions{
+			QueryId: requestBody.QueryId,
+			Offset:  requestBody.Offset,
+			MaxRows: requestBody.Limit,
+			Timeout: time.Duration(requestBody.TimeoutSeconds) * time.Second,
+		}, writer.WriteRow)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if writer.Started() {
+				// the status has already been sent, so the failure is reported in the result summary instead
+				if closeErr := writer.Close(result, err); closeErr != nil {
+					log.Printf("failed to write sql query results: %v", closeErr)
+				}
+
+				return
+			}
+
+			status := http.StatusInternalServerError
+
+			switch {
+			case errors.Is(err, sql.ErrQueryCancelled), errors.Is(err, sql.ErrQueryTimeout):
+				status = http.StatusRequestTimeout
+			case errors.Is(err, sql.ErrOffsetNotSupported):
+				status = http.StatusBadRequest
+			}
+
+			http.Error(w, err.Error(), status)
+
 			return
 		}
 
-		// Write the results to the response
-		jsonResponse, err := json.Marshal(results)
+		err = writer.Close(result, nil)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			log.Printf("failed to write sql query results: %v", err)
+		}
+	}
+}
+
+func (d *Dashboard) createSqlCancelHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Set CORs headers
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-		_, err = w.Write(jsonResponse)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		queryId := r.URL.Query().Get("queryId")
+		if queryId == "" {
+			http.Error(w, "missing queryId param", http.StatusBadRequest)
+			return
+		}
+
+		if !d.databaseService.CancelQuery(queryId) {
+			http.Error(w, "query not found", http.StatusNotFound)
 			return
 		}
+
+		w.WriteHeader(http.StatusOK)
 	}
 }
 
@@ -442,6 +532,64 @@ func (d *Dashboard) createSecretsHandler() func(http.ResponseWriter, *http.Reque
 	}
 }
 
+func (d *Dashboard) createApiTokenHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var requestBody struct {
+			ApiName          string   `json:"apiName"`
+			SecurityName     string   `json:"securityName"`
+			Subject          string   `json:"subject"`
+			Scopes           []string `json:"scopes"`
+			ExpiresInSeconds int      `json:"expiresInSeconds"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if requestBody.ApiName == "" || requestBody.SecurityName == "" {
+			http.Error(w, "apiName and securityName are required", http.StatusBadRequest)
+			return
+		}
+
+		token, err := d.gatewayService.MintToken(requestBody.ApiName, requestBody.SecurityName, gateway.MintTokenOptions{
+			Subject:   requestBody.Subject,
+			Scopes:    requestBody.Scopes,
+			ExpiresIn: time.Duration(requestBody.ExpiresInSeconds) * time.Second,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(map[string]string{"token": token})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		handleResponseWriter(w, jsonResponse)
+	}
+}
+
 func (d *Dashboard) createHistoryHttpHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -513,9 +661,155 @@ func (d *Dashboard) handleWebsocketMessagesClear() func(http.ResponseWriter, *ht
 	}
 }
 
+// createWebsocketConnectionsHandler lists the active connections of a socket, or closes a specific connection
+func (d *Dashboard) createWebsocketConnectionsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		socketName := r.URL.Query().Get("socket")
+
+		if socketName == "" {
+			http.Error(w, "missing socket param", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			jsonResponse, err := json.Marshal(d.websocketService.GetConnections(socketName))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			handleResponseWriter(w, jsonResponse)
+		case "DELETE":
+			connectionId := r.URL.Query().Get("connectionId")
+
+			if connectionId == "" {
+				http.Error(w, "missing connectionId param", http.StatusBadRequest)
+				return
+			}
+
+			_, err := d.websocketService.CloseConnection(context.Background(), &websocketspb.WebsocketCloseConnectionRequest{
+				SocketName:   socketName,
+				ConnectionId: connectionId,
+			})
+			if errors.Is(err, websockets.ErrConnectionNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// createWebsocketSendHandler sends a message to a specific connection, or broadcasts it to all connections if no connection is given
+func (d *Dashboard) createWebsocketSendHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if r.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var requestBody struct {
+			Socket       string `json:"socket"`
+			ConnectionId string `json:"connectionId"`
+			Data         string `json:"data"`
+			// Binary indicates data is base64 encoded and should be sent as a binary frame
+			Binary bool `json:"binary"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if requestBody.Socket == "" {
+			http.Error(w, "socket is required", http.StatusBadRequest)
+			return
+		}
+
+		data := []byte(requestBody.Data)
+
+		if requestBody.Binary {
+			data, err = base64.StdEncoding.DecodeString(requestBody.Data)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid base64 data: %s", err.Error()), http.StatusBadRequest)
+				return
+			}
+		}
+
+		sent := 1
+
+		if requestBody.ConnectionId == "" {
+			sent, err = d.websocketService.Broadcast(requestBody.Socket, data, requestBody.Binary)
+		} else {
+			err = d.websocketService.Send(requestBody.Socket, requestBody.ConnectionId, data, requestBody.Binary)
+		}
+
+		if errors.Is(err, websockets.ErrConnectionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(map[string]int{"sent": sent})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		handleResponseWriter(w, jsonResponse)
+	}
+}
+
 func (d *Dashboard) handleApiHistory(state apis.ApiRequestState) {
 	var queryParams []Param
 
+	pathParams := []Param{}
+
+	for key, value := range state.PathParams {
+		pathParams = append(pathParams, Param{
+			Key:   key,
+			Value: value,
+		})
+	}
+
+	slices.SortFunc(pathParams, func(a, b Param) int {
+		return compare(a.Key, b.Key)
+	})
+
 	state.ReqCtx.QueryArgs().VisitAll(func(key []byte, val []byte) {
 		queryParams = append(queryParams, Param{
 			Key:   string(key),
@@ -534,7 +828,7 @@ func (d *Dashboard) handleApiHistory(state apis.ApiRequestState) {
 				QueryParams: queryParams,
 				Headers:     base_http.HttpHeadersToMap(&state.ReqCtx.Request.Header),
 				Body:        state.ReqCtx.Request.Body(),
-				PathParams:  []Param{},
+				PathParams:  pathParams,
 			},
 			Response: &ResponseHistory{
 				Headers: lo.MapEntries(state.HttpResp.Headers, func(k string, v *apispb.HeaderValue) (string, []string) {
@@ -545,6 +839,7 @@ func (d *Dashboard) handleApiHistory(state apis.ApiRequestState) {
 				Data:   state.HttpResp.GetBody(),
 				Size:   len(state.HttpResp.GetBody()),
 			},
+			ValidationErrors: state.ValidationErrors,
 		},
 	})
 	if err != nil {
@@ -560,6 +855,7 @@ func (d *Dashboard) handleWebsocketEvents(action websockets.WebsocketAction[webs
 	switch e := action.Event.(type) {
 	case websockets.WebsocketInfo:
 		d.websocketsInfo[action.Name].ConnectionCount = e.ConnectionCount
+		d.websocketsInfo[action.Name].Connections = e.Connections
 	case websockets.WebsocketMessage:
 		d.websocketsInfo[action.Name].Messages = append([]websockets.WebsocketMessage{e}, d.websocketsInfo[action.Name].Messages...)
 	}
@@ -600,14 +896,26 @@ func (d *Dashboard) handleSchedulesHistory(action schedules.ActionState) {
 }
 
 func (d *Dashboard) handleBatchJobsHistory(action batch.ActionState) {
-	err := d.writeHistoryRecord(&HistoryEvent[any]{
-		Time:       time.Now().UnixMilli(),
+	// each job run has a single record, updated as its status changes
+	err := d.replaceHistoryRecord(&HistoryEvent[any]{
+		Time:       action.QueuedAt.UnixMilli(),
 		RecordType: BATCHJOBS,
 		Event: BatchHistoryItem{
-			Name:    action.JobName,
-			Payload: action.Payload,
-			Success: action.Success,
+			Id:         action.JobId,
+			Name:       action.JobName,
+			Payload:    action.Payload,
+			Status:     string(action.Status),
+			Success:    action.Success,
+			Error:      action.Error,
+			Duration:   action.Duration().Milliseconds(),
+			ExitCode:   action.ExitCode,
+			StopStatus: action.StopStatus,
+			Logs:       action.Logs,
 		},
+	}, func(existing *HistoryEvent[any]) bool {
+		event, ok := existing.Event.(map[string]any)
+
+		return ok && event["id"] == action.JobId
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -22,6 +22,7 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"slices"
 
 	"github.com/nitrictech/cli/pkg/paths"
 )
@@ -61,9 +62,18 @@ type TopicHistoryItem struct {
 }
 
 type BatchHistoryItem struct {
+	Id      string `json:"id,omitempty"`
 	Name    string `json:"name,omitempty"`
 	Payload string `json:"payload,omitempty"`
+	Status  string `json:"status,omitempty"`
 	Success bool   `json:"success,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Duration of the job run in milliseconds
+	Duration int64 `json:"duration,omitempty"`
+	// ExitCode, StopStatus and Logs are only captured for jobs run in isolated containers
+	ExitCode   *int   `json:"exitCode,omitempty"`
+	StopStatus *int   `json:"stopStatus,omitempty"`
+	Logs       string `json:"logs,omitempty"`
 }
 
 type ScheduleHistoryItem struct {
@@ -72,9 +82,10 @@ type ScheduleHistoryItem struct {
 }
 
 type ApiHistoryItem struct {
-	Api      string           `json:"api"`
-	Request  *RequestHistory  `json:"request"`
-	Response *ResponseHistory `json:"response"`
+	Api              string           `json:"api"`
+	Request          *RequestHistory  `json:"request"`
+	Response         *ResponseHistory `json:"response"`
+	ValidationErrors []string         `json:"validationErrors,omitempty"`
 }
 
 type Param struct {
@@ -105,6 +116,15 @@ func NewHistoryError(recordType RecordType, historyFile string) error {
 }
 
 func (d *Dashboard) writeHistoryRecord(historyRecord *HistoryEvent[any]) error {
+	return d.replaceHistoryRecord(historyRecord, nil)
+}
+
+// replaceHistoryRecord replaces the first existing record that matches, or appends the record if none do
+func (d *Dashboard) replaceHistoryRecord(historyRecord *HistoryEvent[any], matches func(existing *HistoryEvent[any]) bool) error {
+	// records can be written from concurrent job runs
+	d.historyLock.Lock()
+	defer d.historyLock.Unlock()
+
 	historyFile, err := paths.NitricHistoryFile(d.project.Directory, string(historyRecord.RecordType))
 	if err != nil {
 		return err
@@ -115,7 +135,16 @@ func (d *Dashboard) writeHistoryRecord(historyRecord *HistoryEvent[any]) error {
 		return NewHistoryError(historyRecord.RecordType, historyFile)
 	}
 
-	existingRecords = append(existingRecords, historyRecord)
+	idx := -1
+	if matches != nil {
+		idx = slices.IndexFunc(existingRecords, matches)
+	}
+
+	if idx >= 0 {
+		existingRecords[idx] = historyRecord
+	} else {
+		existingRecords = append(existingRecords, historyRecord)
+	}
 
 	data, err := json.Marshal(existingRecords)
 	if err != nil {
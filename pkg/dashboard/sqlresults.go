@@ -0,0 +1,228 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/nitrictech/cli/pkg/cloud/sql"
+)
+
+// flush the response every n rows so the dashboard can render results as they arrive
+const sqlFlushInterval = 100
+
+// sqlResultWriter streams query rows to a http response as either JSON or CSV.
+//
+// JSON responses are an object of the form {"queryId": "...", "rows": [...], "columns": [...], "rowCount": 0, "truncated": false},
+// the summary fields follow the rows as they're only known once the query completes.
+// Queries that fail after rows have been streamed still complete the document, with the failure in an "error" field,
+// as the rows were never committed.
+// CSV responses can't carry the summary in the body, so it's sent in trailers instead.
+type sqlResultWriter struct {
+	w       http.ResponseWriter
+	queryId string
+	format  string
+	csv     *csv.Writer
+	started bool
+	rows    int
+}
+
+type sqlResultSummary struct {
+	Columns   []string `json:"columns"`
+	RowCount  int      `json:"rowCount"`
+	Truncated bool     `json:"truncated"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func newSqlResultWriter(w http.ResponseWriter, queryId string, format string) *sqlResultWriter {
+	w.Header().Set("X-Nitric-Sql-Query-Id", queryId)
+	w.Header().Set("Access-Control-Expose-Headers", "X-Nitric-Sql-Query-Id")
+
+	if format == "csv" {
+		w.Header().Set("Trailer", "X-Nitric-Sql-Row-Count, X-Nitric-Sql-Truncated, X-Nitric-Sql-Error")
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	return &sqlResultWriter{
+		w:       w,
+		queryId: queryId,
+		format:  format,
+	}
+}
+
+// Started returns true once the response status has been written
+func (s *sqlResultWriter) Started() bool {
+	return s.started
+}
+
+func (s *sqlResultWriter) start(columns []string) error {
+	s.started = true
+
+	s.w.WriteHeader(http.StatusOK)
+
+	if s.format == "csv" {
+		s.csv = csv.NewWriter(s.w)
+
+		return s.csv.Write(columns)
+	}
+
+	queryId, err := json.Marshal(s.queryId)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.w, `{"queryId":%s,"rows":[`, queryId)
+
+	return err
+}
+
+func (s *sqlResultWriter) WriteRow(row *orderedmap.OrderedMap[string, any]) error {
+	if !s.started {
+		columns := []string{}
+
+		for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+			columns = append(columns, pair.Key)
+		}
+
+		err := s.start(columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.format == "csv" {
+		record := []string{}
+
+		for pair := row.Oldest(); pair != nil; pair = pair.Next() {
+			record = append(record, csvValue(pair.Value))
+		}
+
+		err := s.csv.Write(record)
+		if err != nil {
+			return err
+		}
+	} else {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+
+		if s.rows > 0 {
+			data = append([]byte(","), data...)
+		}
+
+		_, err = s.w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.rows++
+
+	if s.rows%sqlFlushInterval == 0 {
+		s.flush()
+	}
+
+	return nil
+}
+
+// Close completes the response, writing the result summary along with the error of a query that failed after streaming started
+func (s *sqlResultWriter) Close(result *sql.QueryResult, queryErr error) error {
+	errMessage := ""
+	if queryErr != nil {
+		errMessage = queryErr.Error()
+	}
+
+	if !s.started {
+		err := s.start(result.Columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.format == "csv" {
+		s.flush()
+
+		s.w.Header().Set("X-Nitric-Sql-Row-Count", strconv.Itoa(result.RowCount))
+		s.w.Header().Set("X-Nitric-Sql-Truncated", strconv.FormatBool(result.Truncated))
+
+		if errMessage != "" {
+			s.w.Header().Set("X-Nitric-Sql-Error", errMessage)
+		}
+
+		return s.csv.Error()
+	}
+
+	summary, err := json.Marshal(sqlResultSummary{
+		Columns:   result.Columns,
+		RowCount:  result.RowCount,
+		Truncated: result.Truncated,
+		Error:     errMessage,
+	})
+	if err != nil {
+		return err
+	}
+
+	// close the rows array and merge the summary into the response object
+	_, err = fmt.Fprintf(s.w, "],%s", summary[1:])
+	if err != nil {
+		return err
+	}
+
+	s.flush()
+
+	return nil
+}
+
+func (s *sqlResultWriter) flush() {
+	if s.csv != nil {
+		s.csv.Flush()
+	}
+
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func csvValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return fmt.Sprint(v)
+	}
+
+	// complex values such as json columns and arrays are written as JSON
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+
+	return string(data)
+}
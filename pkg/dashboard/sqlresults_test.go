@@ -0,0 +1,145 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/nitrictech/cli/pkg/cloud/sql"
+)
+
+func testRow(id int) *orderedmap.OrderedMap[string, any] {
+	row := orderedmap.New[string, any]()
+	row.Set("id", id)
+
+	return row
+}
+
+func TestSqlResultWriterJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     int
+		result   *sql.QueryResult
+		queryErr error
+		want     map[string]any
+	}{
+		{
+			name:   "summarises truncated results",
+			rows:   2,
+			result: &sql.QueryResult{Columns: []string{"id"}, RowCount: 2, Truncated: true},
+			want: map[string]any{
+				"queryId":   "query-1",
+				"rows":      []any{map[string]any{"id": float64(0)}, map[string]any{"id": float64(1)}},
+				"columns":   []any{"id"},
+				"rowCount":  float64(2),
+				"truncated": true,
+			},
+		},
+		{
+			name:   "writes an empty result",
+			result: &sql.QueryResult{Columns: []string{"id"}},
+			want: map[string]any{
+				"queryId":   "query-1",
+				"rows":      []any{},
+				"columns":   []any{"id"},
+				"rowCount":  float64(0),
+				"truncated": false,
+			},
+		},
+		{
+			name:     "completes the document when the query fails after streaming rows",
+			rows:     1,
+			result:   &sql.QueryResult{Columns: []string{"id"}, RowCount: 1},
+			queryErr: errors.New("commit failed"),
+			want: map[string]any{
+				"queryId":   "query-1",
+				"rows":      []any{map[string]any{"id": float64(0)}},
+				"columns":   []any{"id"},
+				"rowCount":  float64(1),
+				"truncated": false,
+				"error":     "commit failed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			writer := newSqlResultWriter(recorder, "query-1", "json")
+
+			for i := range tt.rows {
+				if err := writer.WriteRow(testRow(i)); err != nil {
+					t.Fatalf("WriteRow() error = %v", err)
+				}
+			}
+
+			if err := writer.Close(tt.result, tt.queryErr); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := map[string]any{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("response isn't valid JSON: %v\n%s", err, recorder.Body.String())
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("response mismatch (-want +got):\n%s", diff)
+			}
+
+			if id := recorder.Header().Get("X-Nitric-Sql-Query-Id"); id != "query-1" {
+				t.Errorf("query id header = %q, want %q", id, "query-1")
+			}
+		})
+	}
+}
+
+func TestSqlResultWriterCSVTrailers(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := newSqlResultWriter(recorder, "query-1", "csv")
+
+	if err := writer.WriteRow(testRow(1)); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+
+	if err := writer.Close(&sql.QueryResult{Columns: []string{"id"}, RowCount: 1, Truncated: true}, errors.New("commit failed")); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if body := recorder.Body.String(); body != "id\n1\n" {
+		t.Errorf("body = %q, want %q", body, "id\n1\n")
+	}
+
+	trailer := recorder.Result().Trailer
+
+	want := map[string]string{
+		"X-Nitric-Sql-Row-Count": "1",
+		"X-Nitric-Sql-Truncated": "true",
+		"X-Nitric-Sql-Error":     "commit failed",
+	}
+
+	for key, value := range want {
+		if got := trailer.Get(key); got != value {
+			t.Errorf("trailer %s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Image builders that can be selected in nitric.yaml
+const (
+	BuilderDocker   = "docker"
+	BuilderBuildKit = "buildkit"
+)
+
+// BuilderEnvVar overrides the configured image builder, e.g. to use a daemonless builder on CI runners without docker
+const BuilderEnvVar = "NITRIC_BUILDER"
+
+// ImageBuilder builds container images from a dockerfile and a build context
+type ImageBuilder interface {
+	Build(dockerfile, srcPath, imageTag string, options ...DockerBuildOption) error
+	// ImageExists returns true if an image with the tag has been built
+	ImageExists(imageTag string) bool
+	// Daemonless returns true if built images aren't available to a container engine, so they can't be run locally
+	Daemonless() bool
+}
+
+// NewImageBuilder returns the named image builder, daemonless builders write OCI image archives to outputDir
+func NewImageBuilder(name string, outputDir string) (ImageBuilder, error) {
+	if envBuilder := os.Getenv(BuilderEnvVar); envBuilder != "" {
+		name = envBuilder
+	}
+
+	switch name {
+	case "", BuilderDocker:
+		return New()
+	case BuilderBuildKit:
+		return NewBuildKitBuilder(outputDir)
+	default:
+		return nil, fmt.Errorf("unknown image builder %s, supported builders are %s and %s", name, BuilderDocker, BuilderBuildKit)
+	}
+}
+
+var _ ImageBuilder = (*Docker)(nil)
+
+func (d *Docker) ImageExists(imageTag string) bool {
+	_, _, err := d.ImageInspectWithRaw(context.Background(), imageTag)
+
+	return err == nil
+}
+
+func (d *Docker) Daemonless() bool {
+	return false
+}
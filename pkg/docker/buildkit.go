@@ -0,0 +1,121 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BuildKitBuilder builds images with BuildKit without a docker daemon, writing each image to an OCI image archive
+type BuildKitBuilder struct {
+	command   string
+	outputDir string
+}
+
+var _ ImageBuilder = (*BuildKitBuilder)(nil)
+
+// NewBuildKitBuilder returns a builder using buildctl-daemonless.sh, which runs a rootless buildkitd for each build,
+// or buildctl connected to the buildkitd at BUILDKIT_HOST
+func NewBuildKitBuilder(outputDir string) (*BuildKitBuilder, error) {
+	if command, err := exec.LookPath("buildctl-daemonless.sh"); err == nil {
+		return &BuildKitBuilder{command: command, outputDir: outputDir}, nil
+	}
+
+	if command, err := exec.LookPath("buildctl"); err == nil && os.Getenv("BUILDKIT_HOST") != "" {
+		return &BuildKitBuilder{command: command, outputDir: outputDir}, nil
+	}
+
+	return nil, errors.New("the buildkit builder requires buildctl-daemonless.sh, or buildctl with BUILDKIT_HOST set, see https://github.com/moby/buildkit#rootless-mode for installation instructions")
+}
+
+// ImageArchive returns the path of the OCI image archive for an image
+func (b *BuildKitBuilder) ImageArchive(imageTag string) string {
+	return filepath.Join(b.outputDir, strings.NewReplacer("/", "_", ":", "_").Replace(imageTag)+".tar")
+}
+
+func (b *BuildKitBuilder) ImageExists(imageTag string) bool {
+	_, err := os.Stat(b.ImageArchive(imageTag))
+
+	return err == nil
+}
+
+func (b *BuildKitBuilder) Daemonless() bool {
+	return true
+}
+
+func (b *BuildKitBuilder) Build(dockerfile, srcPath, imageTag string, options ...DockerBuildOption) error {
+	opts := defaultBuildOptions()
+
+	for _, o := range options {
+		o(opts)
+	}
+
+	// the dockerfile frontend reads <dockerfile>.dockerignore from the dockerfile's directory
+	removeIgnoreFile, err := writeIgnoreFile(dockerfile, opts.excludes)
+	if err != nil {
+		return err
+	}
+
+	defer removeIgnoreFile()
+
+	if err := os.MkdirAll(b.outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create image output directory %s: %w", b.outputDir, err)
+	}
+
+	names := append([]string{imageTag}, opts.tags...)
+
+	args := []string{
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", fmt.Sprintf("context=%s", srcPath),
+		"--local", fmt.Sprintf("dockerfile=%s", filepath.Dir(dockerfile)),
+		"--opt", fmt.Sprintf("filename=%s", filepath.Base(dockerfile)),
+		"--opt", fmt.Sprintf("platform=%s", strings.Join(opts.platforms, ",")),
+		"--output", fmt.Sprintf("type=oci,dest=%s,\"name=%s\"", b.ImageArchive(imageTag), strings.Join(names, ",")),
+	}
+
+	for k, v := range opts.args {
+		args = append(args, "--opt", fmt.Sprintf("build-arg:%s=%s", k, v))
+	}
+
+	for k, v := range opts.labels {
+		args = append(args, "--opt", fmt.Sprintf("label:%s=%s", k, v))
+	}
+
+	cacheTo, cacheFrom := localCacheDirs(imageTag)
+
+	if cacheTo != "" {
+		args = append(args, "--export-cache", fmt.Sprintf("type=local,dest=%s", cacheTo))
+	}
+
+	if cacheFrom != "" {
+		args = append(args, "--import-cache", fmt.Sprintf("type=local,src=%s", cacheFrom))
+	}
+
+	cmd := exec.Command(b.command, args...)
+
+	cmd.Stdout = opts.logger
+	cmd.Stderr = opts.logger
+
+	return cmd.Run()
+}
@@ -26,6 +26,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
 	"strings"
 	"sync"
 	"unicode"
@@ -103,12 +104,23 @@ func (d *Docker) createBuildxBuilder() (*BuildxBuilder, error) {
 	return &BuildxBuilder{Name: builderName}, nil
 }
 
+// DefaultPlatform is the platform images are built for when none is requested
+const DefaultPlatform = "linux/amd64"
+
+// NativePlatform returns the linux platform matching the host's architecture, which runs without emulation
+func NativePlatform() string {
+	return "linux/" + goruntime.GOARCH
+}
+
 type dockerBuildOptions struct {
 	// Whether or not to use a builder container
 	useBuilder bool
 	excludes   []string
 	logger     io.Writer
 	args       map[string]string
+	platforms  []string
+	tags       []string
+	labels     map[string]string
 }
 
 func defaultBuildOptions() *dockerBuildOptions {
@@ -117,6 +129,9 @@ func defaultBuildOptions() *dockerBuildOptions {
 		excludes:   []string{},
 		logger:     io.Discard,
 		args:       map[string]string{},
+		platforms:  []string{DefaultPlatform},
+		tags:       []string{},
+		labels:     map[string]string{},
 	}
 }
 
@@ -146,6 +161,74 @@ func WithBuildArgs(args map[string]string) DockerBuildOption {
 	}
 }
 
+// WithPlatforms sets the platforms to build the image for, building for more than one requires an image store that supports multi-platform images (e.g. the containerd image store)
+func WithPlatforms(platforms []string) DockerBuildOption {
+	return func(o *dockerBuildOptions) {
+		if len(platforms) > 0 {
+			o.platforms = platforms
+		}
+	}
+}
+
+// WithAdditionalTags tags the image with more tags as well as the image tag passed to Build
+func WithAdditionalTags(tags []string) DockerBuildOption {
+	return func(o *dockerBuildOptions) {
+		o.tags = tags
+	}
+}
+
+func WithLabels(labels map[string]string) DockerBuildOption {
+	return func(o *dockerBuildOptions) {
+		o.labels = labels
+	}
+}
+
+// writeIgnoreFile writes a temporary dockerignore file alongside the dockerfile, returning a func to remove it
+func writeIgnoreFile(dockerfile string, excludes []string) (func(), error) {
+	ignoreFile, err := os.Create(fmt.Sprintf("%s.dockerignore", dockerfile))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ignoreFile.Write([]byte(strings.Join(excludes, "\n")))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ignoreFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		os.Remove(ignoreFile.Name())
+	}, nil
+}
+
+// localCacheDirs returns the directories to export and import the build cache for an image, configured by the DOCKER_BUILD_CACHE environment variables
+func localCacheDirs(imageTag string) (string, string) {
+	cacheTo := ""
+	cacheFrom := ""
+
+	dockerBuildCache := os.Getenv("DOCKER_BUILD_CACHE")
+	if dockerBuildCache != "" {
+		cacheTo = filepath.Join(dockerBuildCache, imageTag)
+		cacheFrom = filepath.Join(dockerBuildCache, imageTag)
+	}
+
+	dockerBuildCacheDest := os.Getenv("DOCKER_BUILD_CACHE_DEST")
+	if dockerBuildCacheDest != "" {
+		cacheTo = filepath.Join(dockerBuildCacheDest, imageTag)
+	}
+
+	dockerBuildCacheSrc := os.Getenv("DOCKER_BUILD_CACHE_SRC")
+	if dockerBuildCacheSrc != "" {
+		cacheFrom = filepath.Join(dockerBuildCacheSrc, imageTag)
+	}
+
+	return cacheTo, cacheFrom
+}
+
 func (d *Docker) Build(dockerfile, srcPath, imageTag string, options ...DockerBuildOption) error {
 	opts := defaultBuildOptions()
 
@@ -165,25 +248,12 @@ func (d *Docker) Build(dockerfile, srcPath, imageTag string, options ...DockerBu
 		}
 	}
 
-	// write a temporary dockerignore file
-	ignoreFile, err := os.Create(fmt.Sprintf("%s.dockerignore", dockerfile))
-	if err != nil {
-		return err
-	}
-
-	_, err = ignoreFile.Write([]byte(strings.Join(opts.excludes, "\n")))
-	if err != nil {
-		return err
-	}
-
-	err = ignoreFile.Close()
+	removeIgnoreFile, err := writeIgnoreFile(dockerfile, opts.excludes)
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		os.Remove(ignoreFile.Name())
-	}()
+	defer removeIgnoreFile()
 
 	buildArgsCmd := make([]string, 0)
 	for k, v := range opts.args {
@@ -191,7 +261,7 @@ func (d *Docker) Build(dockerfile, srcPath, imageTag string, options ...DockerBu
 	}
 
 	args := []string{
-		"buildx", "build", srcPath, "-f", dockerfile, "-t", imageTag, "--load", "--platform", "linux/amd64",
+		"buildx", "build", srcPath, "-f", dockerfile, "-t", imageTag, "--load", "--platform", strings.Join(opts.platforms, ","),
 	}
 	// Podman doesn't support builder containers
 	if builder != nil && opts.useBuilder {
@@ -200,37 +270,22 @@ func (d *Docker) Build(dockerfile, srcPath, imageTag string, options ...DockerBu
 
 	args = append(args, buildArgsCmd...)
 
-	cacheTo := ""
-	cacheFrom := ""
-
-	dockerBuildCache := os.Getenv("DOCKER_BUILD_CACHE")
-	if dockerBuildCache != "" {
-		imageCache := filepath.Join(dockerBuildCache, imageTag)
-
-		cacheTo = fmt.Sprintf("--cache-to=type=local,dest=%s", imageCache)
-		cacheFrom = fmt.Sprintf("--cache-from=type=local,src=%s", imageCache)
+	for _, tag := range opts.tags {
+		args = append(args, "-t", tag)
 	}
 
-	dockerBuildCacheDest := os.Getenv("DOCKER_BUILD_CACHE_DEST")
-	if dockerBuildCacheDest != "" {
-		imageCache := filepath.Join(dockerBuildCacheDest, imageTag)
-
-		cacheTo = fmt.Sprintf("--cache-to=type=local,dest=%s", imageCache)
+	for k, v := range opts.labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
 	}
 
-	dockerBuildCacheSrc := os.Getenv("DOCKER_BUILD_CACHE_SRC")
-	if dockerBuildCacheSrc != "" {
-		imageCache := filepath.Join(dockerBuildCacheSrc, imageTag)
-
-		cacheFrom = fmt.Sprintf("--cache-from=type=local,src=%s", imageCache)
-	}
+	cacheTo, cacheFrom := localCacheDirs(imageTag)
 
 	if cacheTo != "" {
-		args = append(args, cacheTo)
+		args = append(args, fmt.Sprintf("--cache-to=type=local,dest=%s", cacheTo))
 	}
 
 	if cacheFrom != "" {
-		args = append(args, cacheFrom)
+		args = append(args, fmt.Sprintf("--cache-from=type=local,src=%s", cacheFrom))
 	}
 
 	// The args should be compatible with either docker or podman
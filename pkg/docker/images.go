@@ -0,0 +1,51 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+)
+
+// Labels applied to images built by the CLI, so they can be found again
+const (
+	LabelProject = "io.nitric.project"
+	LabelService = "io.nitric.service"
+	LabelDigest  = "io.nitric.digest"
+)
+
+// ListImages returns the local images with all of the given labels
+func (d *Docker) ListImages(labels map[string]string) ([]image.Summary, error) {
+	labelFilters := filters.NewArgs()
+
+	for k, v := range labels {
+		labelFilters.Add("label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	return d.ImageList(context.Background(), types.ImageListOptions{Filters: labelFilters})
+}
+
+// RemoveImage removes a local image by id, including any tags referring to it
+func (d *Docker) RemoveImage(id string) error {
+	_, err := d.ImageRemove(context.Background(), id, types.ImageRemoveOptions{Force: true, PruneChildren: true})
+
+	return err
+}
@@ -0,0 +1,236 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/pkg/errors"
+)
+
+// dockerHubAuthKey is the key docker uses for Docker Hub credentials in its config file
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+type dockerConfigAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// dockerConfig is the subset of the docker CLI's config.json used to find registry credentials
+type dockerConfig struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredsStore  string                      `json:"credsStore,omitempty"`
+	CredHelpers map[string]string           `json:"credHelpers,omitempty"`
+}
+
+func dockerConfigPath() string {
+	if configDir := os.Getenv("DOCKER_CONFIG"); configDir != "" {
+		return filepath.Join(configDir, "config.json")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(homeDir, ".docker", "config.json")
+}
+
+// RegistryHost returns the registry an image reference would be pushed to
+func RegistryHost(ref string) string {
+	host, _, found := strings.Cut(ref, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return "docker.io"
+	}
+
+	return host
+}
+
+func authKeys(host string) []string {
+	if host == "docker.io" {
+		return []string{dockerHubAuthKey, "index.docker.io", "docker.io"}
+	}
+
+	return []string{host, "https://" + host, "http://" + host}
+}
+
+// credentialHelperAuth gets credentials from a docker credential helper, e.g. docker-credential-desktop
+func credentialHelperAuth(helper string, serverAddress string) (*registry.AuthConfig, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		// helpers report missing credentials on stdout, in which case the push is attempted anonymously
+		if strings.Contains(string(out), "credentials not found") {
+			return &registry.AuthConfig{}, nil
+		}
+
+		return nil, fmt.Errorf("docker credential helper %s failed: %w %s", helper, err, stderr.String())
+	}
+
+	creds := struct {
+		Username string
+		Secret   string
+	}{}
+
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return nil, fmt.Errorf("unable to read credentials from docker credential helper %s: %w", helper, err)
+	}
+
+	// helpers return identity tokens with the username <token>
+	if creds.Username == "<token>" {
+		return &registry.AuthConfig{IdentityToken: creds.Secret, ServerAddress: serverAddress}, nil
+	}
+
+	return &registry.AuthConfig{Username: creds.Username, Password: creds.Secret, ServerAddress: serverAddress}, nil
+}
+
+// registryAuthConfig returns the credentials for a registry from the docker config, credentials are empty if none are configured
+func registryAuthConfig(host string) (*registry.AuthConfig, error) {
+	configFile, err := os.ReadFile(dockerConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &registry.AuthConfig{}, nil
+		}
+
+		return nil, err
+	}
+
+	config := &dockerConfig{}
+	if err := json.Unmarshal(configFile, config); err != nil {
+		return nil, fmt.Errorf("unable to read docker config: %w", err)
+	}
+
+	keys := authKeys(host)
+
+	for _, key := range keys {
+		if helper, ok := config.CredHelpers[key]; ok {
+			return credentialHelperAuth(helper, keys[0])
+		}
+	}
+
+	if config.CredsStore != "" {
+		return credentialHelperAuth(config.CredsStore, keys[0])
+	}
+
+	for _, key := range keys {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+
+		authConfig := &registry.AuthConfig{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			IdentityToken: auth.IdentityToken,
+			RegistryToken: auth.RegistryToken,
+			ServerAddress: key,
+		}
+
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for %s in docker config: %w", key, err)
+			}
+
+			authConfig.Username, authConfig.Password, _ = strings.Cut(string(decoded), ":")
+		}
+
+		return authConfig, nil
+	}
+
+	return &registry.AuthConfig{}, nil
+}
+
+type pushLine struct {
+	Error string `json:"error"`
+	Aux   *struct {
+		Tag    string `json:"Tag"`
+		Digest string `json:"Digest"`
+	} `json:"aux"`
+}
+
+// Push tags a local image with a remote reference and pushes it using credentials from the docker config, returning the pushed manifest digest
+func (d *Docker) Push(image string, ref string) (string, error) {
+	if err := d.ImageTag(context.Background(), image, ref); err != nil {
+		return "", err
+	}
+
+	authConfig, err := registryAuthConfig(RegistryHost(ref))
+	if err != nil {
+		return "", err
+	}
+
+	registryAuth, err := registry.EncodeAuthConfig(*authConfig)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := d.ImagePush(context.Background(), ref, types.ImagePushOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return "", errors.WithMessage(err, "Push")
+	}
+
+	defer resp.Close()
+
+	digest := ""
+
+	scanner := bufio.NewScanner(resp)
+	for scanner.Scan() {
+		line := &pushLine{}
+
+		if err := json.Unmarshal(scanner.Bytes(), line); err != nil {
+			return "", err
+		}
+
+		if line.Error != "" {
+			return "", fmt.Errorf("unable to push %s: %s", ref, line.Error)
+		}
+
+		if line.Aux != nil && line.Aux.Digest != "" {
+			digest = line.Aux.Digest
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if digest == "" {
+		return "", fmt.Errorf("registry didn't return a digest for %s", ref)
+	}
+
+	return digest, nil
+}
@@ -125,6 +125,16 @@ func NitricServiceLogFile(stackPath string) string {
 	return filepath.Join(NitricTmpDir(stackPath), "./services.log")
 }
 
+// NitricSpecBaselineFile returns the path to the spec last deployed to a stack
+func NitricSpecBaselineFile(stackPath string, stackName string) string {
+	return filepath.Join(NitricTmpDir(stackPath), "specs", fmt.Sprintf("%s.json", stackName))
+}
+
+// NitricPushManifestFile returns the default path to the manifest of images pushed by nitric build --push
+func NitricPushManifestFile(stackPath string) string {
+	return filepath.Join(NitricTmpDir(stackPath), "images.json")
+}
+
 func NitricTlsCredentialsPath(stackPath string) string {
 	return filepath.Join(NitricTmpDir(stackPath), "./tls")
 }
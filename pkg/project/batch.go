@@ -51,6 +51,17 @@ type Batch struct {
 	buildContext runtime.RuntimeBuildContext
 
 	runCmd string
+
+	// platforms the batch can be built for, empty if it supports any
+	platforms []string
+
+	// image is the repository the batch is built to, scoped to the project
+	imageScope string
+	image      string
+
+	// env is the batch's own env, which overrides the project's env
+	env       map[string]string
+	resources ResourcesConfiguration
 }
 
 func (s *Batch) GetFilePath() string {
@@ -150,19 +161,8 @@ func (s *Batch) Run(stop <-chan bool, updates chan<- ServiceRunUpdate, env map[s
 	return err
 }
 
-// RunContainer - Runs a container for the service, blocking until the container exits
-func (s *Batch) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate, opts ...RunContainerOption) error {
-	runtimeOptions := lo.ToPtr(defaultRunContainerOptions)
-
-	for _, opt := range opts {
-		opt(runtimeOptions)
-	}
-
-	dockerClient, err := docker.New()
-	if err != nil {
-		return err
-	}
-
+// containerConfig returns the configuration of containers run from the batch image
+func (s *Batch) containerConfig(runtimeOptions *runContainerOptions, updates chan<- ServiceRunUpdate) (*container.Config, *container.HostConfig) {
 	hostConfig := &container.HostConfig{
 		// LogConfig:  *f.ce.Logger(f.runCtx).Config(),
 		LogConfig: container.LogConfig{
@@ -185,6 +185,8 @@ func (s *Batch) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate,
 		hostConfig.ExtraHosts = []string{"host.docker.internal:" + dockerHost.String()}
 	}
 
+	applyResourceLimits(hostConfig, s.resources)
+
 	randomPort, _ := netx.TakePort(1)
 	hostProxyPort := fmt.Sprint(randomPort[0])
 	env := []string{
@@ -225,13 +227,31 @@ func (s *Batch) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate,
 	}
 
 	containerConfig := &container.Config{
-		Image: s.Name, // Select an image to use based on the handler
+		Image: s.image, // Select an image to use based on the handler
 		Env:   env,
 		ExposedPorts: nat.PortSet{
 			nat.Port(hostProxyPort): struct{}{},
 		},
 	}
 
+	return containerConfig, hostConfig
+}
+
+// RunContainer - Runs a container for the service, blocking until the container exits
+func (s *Batch) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate, opts ...RunContainerOption) error {
+	runtimeOptions := lo.ToPtr(defaultRunContainerOptions)
+
+	for _, opt := range opts {
+		opt(runtimeOptions)
+	}
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return err
+	}
+
+	containerConfig, hostConfig := s.containerConfig(runtimeOptions, updates)
+
 	// Create the container
 	containerId, err := dockerClient.ContainerCreate(
 		containerConfig,
@@ -382,13 +402,8 @@ func (s *Batch) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate,
 }
 
 // FIXME: Duplicate code from service.go
-func (s *Batch) BuildImage(fs afero.Fs, logs io.Writer, useBuilder bool) error {
-	dockerClient, err := docker.New()
-	if err != nil {
-		return err
-	}
-
-	err = fs.MkdirAll(tempBuildDir, os.ModePerm)
+func (s *Batch) BuildImage(fs afero.Fs, builder docker.ImageBuilder, logs io.Writer, useBuilder bool, platforms []string, fingerprint string) error {
+	err := fs.MkdirAll(tempBuildDir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("unable to create temporary build directory %s: %w", tempBuildDir, err)
 	}
@@ -411,15 +426,20 @@ func (s *Batch) BuildImage(fs afero.Fs, logs io.Writer, useBuilder bool) error {
 		}
 	}()
 
-	// build the docker image
-	err = dockerClient.Build(
-		tmpDockerFile.Name(),
-		s.buildContext.BaseDirectory,
-		s.Name,
+	buildOptions := append([]docker.DockerBuildOption{
 		docker.WithBuildArgs(s.buildContext.BuildArguments),
 		docker.WithExcludes(strings.Split(s.buildContext.IgnoreFileContents, "\n")),
 		docker.WithLogger(logs),
 		docker.WithBuilder(useBuilder),
+		docker.WithPlatforms(resolvePlatforms(s.platforms, platforms)),
+	}, imageBuildOptions(s.imageScope, s.Name, s.image, fingerprint)...)
+
+	// build the docker image
+	err = builder.Build(
+		tmpDockerFile.Name(),
+		s.buildContext.BaseDirectory,
+		s.image,
+		buildOptions...,
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,226 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/cloud/batch"
+	"github.com/nitrictech/cli/pkg/docker"
+	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
+)
+
+// how long to wait for a fresh job container to register its job handler
+const isolatedJobStartTimeout = 2 * time.Minute
+
+// applyJobRequirements limits the container to the CPU and memory requested by the job, memory is in MiB
+func applyJobRequirements(hostConfig *container.HostConfig, requirements *batchpb.JobResourceRequirements) {
+	if requirements.GetCpus() > 0 {
+		hostConfig.Resources.NanoCPUs = int64(float64(requirements.GetCpus()) * 1e9)
+	}
+
+	if requirements.GetMemory() > 0 {
+		hostConfig.Resources.Memory = requirements.GetMemory() * 1024 * 1024
+	}
+
+	if requirements.GetGpus() > 0 {
+		hostConfig.Resources.DeviceRequests = []container.DeviceRequest{
+			{
+				Count:        int(requirements.GetGpus()),
+				Capabilities: [][]string{{"gpu"}},
+			},
+		}
+	}
+}
+
+// RunJobContainer - Runs a single job in a fresh container from the batch image, blocking until the job completes.
+// The container connects to a nitric server using the given job manager, which is only used for this job.
+func (s *Batch) RunJobContainer(jobManager *batch.IsolatedJobManager, req *batchpb.JobRequest, requirements *batchpb.JobResourceRequirements, updates chan<- ServiceRunUpdate, opts ...RunContainerOption) (*batch.IsolatedJobResult, error) {
+	runtimeOptions := lo.ToPtr(defaultRunContainerOptions)
+
+	for _, opt := range opts {
+		opt(runtimeOptions)
+	}
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	containerConfig, hostConfig := s.containerConfig(runtimeOptions, updates)
+	applyJobRequirements(hostConfig, requirements)
+
+	runId := uuid.NewString()[:8]
+	label := fmt.Sprintf("%s (job %s)", s.GetFilePath(), runId)
+
+	containerId, err := dockerClient.ContainerCreate(containerConfig, hostConfig, nil, fmt.Sprintf("%s-job-%s", s.Name, runId))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = dockerClient.ContainerRemove(context.Background(), containerId, container.RemoveOptions{Force: true})
+	}()
+
+	err = dockerClient.ContainerStart(context.TODO(), containerId, container.StartOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	updates <- ServiceRunUpdate{
+		ServiceName: s.Name,
+		Label:       "nitric",
+		Message:     fmt.Sprintf("started job %s for %s in a new container", runId, req.GetJobName()),
+		Status:      ServiceRunStatus_Running,
+	}
+
+	attachResponse, err := dockerClient.ContainerAttach(context.TODO(), containerId, container.AttachOptions{Stream: true, Stdout: true, Stderr: true})
+	if err != nil {
+		return nil, fmt.Errorf("error attaching to container %s: %w", s.Name, err)
+	}
+
+	go func() {
+		defer attachResponse.Close()
+
+		_, _ = io.Copy(writerFunc(func(p []byte) (int, error) {
+			updates <- ServiceRunUpdate{
+				ServiceName: s.Name,
+				Label:       label,
+				Message:     string(p),
+				Status:      ServiceRunStatus_Running,
+			}
+
+			return len(p), nil
+		}), attachResponse.Reader)
+	}()
+
+	okChan, errChan := dockerClient.ContainerWait(context.TODO(), containerId, container.WaitConditionNotRunning)
+
+	// the job is abandoned if the container exits before it completes
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+	defer cancelJob()
+
+	jobErr := make(chan error, 1)
+
+	go func() {
+		jobErr <- runIsolatedJob(jobCtx, jobManager, req, isolatedJobStartTimeout)
+	}()
+
+	result := &batch.IsolatedJobResult{}
+
+	var runErr error
+
+	select {
+	case runErr = <-jobErr:
+		// the job's handler has returned, but the SDK keeps the container running waiting for more jobs,
+		// so the job's exit status comes from the handler result and the container's own status is only recorded as its stop status
+		if runErr != nil {
+			result.ExitCode = 1
+		}
+
+		timeout := 10
+		if err := dockerClient.ContainerStop(context.Background(), containerId, container.StopOptions{Timeout: &timeout}); err != nil {
+			return nil, err
+		}
+
+		select {
+		case okBody := <-okChan:
+			result.StopStatus = lo.ToPtr(int(okBody.StatusCode))
+		case err := <-errChan:
+			return nil, err
+		}
+	case okBody := <-okChan:
+		cancelJob()
+
+		// the container exited before the job completed, e.g. it ran out of memory
+		result.ExitCode = int(okBody.StatusCode)
+		runErr = fmt.Errorf("job container exited with status %d before the job completed", okBody.StatusCode)
+
+		inspect, err := dockerClient.ContainerInspect(context.Background(), containerId)
+		if err == nil && inspect.State != nil && inspect.State.OOMKilled {
+			runErr = fmt.Errorf("job container was killed after exceeding its memory limit of %dMiB", requirements.GetMemory())
+		}
+	case err := <-errChan:
+		return nil, err
+	}
+
+	logReader, err := dockerClient.ContainerLogs(context.Background(), containerId, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err == nil {
+		var logs bytes.Buffer
+
+		_, _ = stdcopy.StdCopy(&logs, &logs, logReader)
+		result.Logs = logs.String()
+	}
+
+	return result, runErr
+}
+
+// runIsolatedJob waits for the job container to register a handler for the job, then sends it the job.
+// It returns once the job completes, the handler hasn't registered within the start timeout, or the context is done.
+func runIsolatedJob(ctx context.Context, jobManager *batch.IsolatedJobManager, req *batchpb.JobRequest, startTimeout time.Duration) error {
+	startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	err := jobManager.WaitForHandler(startCtx, req.GetJobName())
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("job container did not register a handler for %s within %s", req.GetJobName(), startTimeout)
+	} else if err != nil {
+		return err
+	}
+
+	type jobResult struct {
+		resp *batchpb.ClientMessage
+		err  error
+	}
+
+	result := make(chan jobResult, 1)
+
+	go func() {
+		resp, err := jobManager.HandleJobRequest(&batchpb.ServerMessage{
+			Content: &batchpb.ServerMessage_JobRequest{
+				JobRequest: req,
+			},
+		})
+
+		result <- jobResult{resp: resp, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-result:
+		if r.err != nil {
+			return r.err
+		}
+
+		if r.resp.GetJobResponse() != nil && !r.resp.GetJobResponse().GetSuccess() {
+			return fmt.Errorf("job %s reported a failure", req.GetJobName())
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+
+	"github.com/nitrictech/cli/pkg/cloud/batch"
+	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
+)
+
+func TestApplyJobRequirements(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements *batchpb.JobResourceRequirements
+		want         container.Resources
+	}{
+		{
+			name: "leaves the container unlimited without requirements",
+		},
+		{
+			name:         "limits cpus and memory in MiB",
+			requirements: &batchpb.JobResourceRequirements{Cpus: 0.5, Memory: 512},
+			want: container.Resources{
+				NanoCPUs: 500_000_000,
+				Memory:   512 * 1024 * 1024,
+			},
+		},
+		{
+			name:         "requests gpus",
+			requirements: &batchpb.JobResourceRequirements{Gpus: 2},
+			want: container.Resources{
+				DeviceRequests: []container.DeviceRequest{{Count: 2, Capabilities: [][]string{{"gpu"}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostConfig := &container.HostConfig{}
+
+			applyJobRequirements(hostConfig, tt.requirements)
+
+			if diff := cmp.Diff(tt.want, hostConfig.Resources); diff != "" {
+				t.Errorf("resources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeJobStream is a job handler's connection, as the SDK in a job container would make
+type fakeJobStream struct {
+	grpc.ServerStream
+
+	recv chan *batchpb.ClientMessage
+	sent chan *batchpb.ServerMessage
+}
+
+func (f *fakeJobStream) Recv() (*batchpb.ClientMessage, error) {
+	msg, ok := <-f.recv
+	if !ok {
+		return nil, io.EOF
+	}
+
+	return msg, nil
+}
+
+func (f *fakeJobStream) Send(msg *batchpb.ServerMessage) error {
+	f.sent <- msg
+	return nil
+}
+
+// connectJobHandler registers a handler for the job, answering job requests with respond, requests are left unanswered if respond is nil
+func connectJobHandler(t *testing.T, jobManager *batch.IsolatedJobManager, jobName string, respond func(req *batchpb.ServerMessage) *batchpb.ClientMessage) {
+	t.Helper()
+
+	stream := &fakeJobStream{
+		recv: make(chan *batchpb.ClientMessage, 1),
+		sent: make(chan *batchpb.ServerMessage, 1),
+	}
+
+	stream.recv <- &batchpb.ClientMessage{
+		Content: &batchpb.ClientMessage_RegistrationRequest{
+			RegistrationRequest: &batchpb.RegistrationRequest{JobName: jobName},
+		},
+	}
+
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+	})
+
+	go func() {
+		_ = jobManager.HandleJob(stream)
+	}()
+
+	go func() {
+		defer close(stream.recv)
+
+		for {
+			select {
+			case <-done:
+				return
+			case msg := <-stream.sent:
+				if msg.GetJobRequest() != nil && respond != nil {
+					stream.recv <- respond(msg)
+				}
+			}
+		}
+	}()
+}
+
+func jobResponse(success bool) func(req *batchpb.ServerMessage) *batchpb.ClientMessage {
+	return func(req *batchpb.ServerMessage) *batchpb.ClientMessage {
+		return &batchpb.ClientMessage{
+			Id:      req.GetId(),
+			Content: &batchpb.ClientMessage_JobResponse{JobResponse: &batchpb.JobResponse{Success: success}},
+		}
+	}
+}
+
+func TestRunIsolatedJob(t *testing.T) {
+	req := &batchpb.JobRequest{JobName: "resize"}
+
+	tests := []struct {
+		name     string
+		handlers map[string]func(req *batchpb.ServerMessage) *batchpb.ClientMessage
+		wantErr  string
+	}{
+		{
+			name:     "sends the job to its handler",
+			handlers: map[string]func(req *batchpb.ServerMessage) *batchpb.ClientMessage{"resize": jobResponse(true)},
+		},
+		{
+			name:     "reports a job failure",
+			handlers: map[string]func(req *batchpb.ServerMessage) *batchpb.ClientMessage{"resize": jobResponse(false)},
+			wantErr:  "job resize reported a failure",
+		},
+		{
+			name:    "times out when no handler registers",
+			wantErr: "job container did not register a handler for resize within",
+		},
+		{
+			name:     "times out when only handlers for other jobs register",
+			handlers: map[string]func(req *batchpb.ServerMessage) *batchpb.ClientMessage{"thumbnail": jobResponse(true)},
+			wantErr:  "job container did not register a handler for resize within",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobManager := batch.NewIsolatedJobManager()
+
+			for jobName, respond := range tt.handlers {
+				connectJobHandler(t, jobManager, jobName, respond)
+			}
+
+			err := runIsolatedJob(context.Background(), jobManager, req, 100*time.Millisecond)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("runIsolatedJob() error = %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("runIsolatedJob() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunIsolatedJobStopsWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name     string
+		register bool
+	}{
+		{
+			name: "while waiting for a handler",
+		},
+		{
+			name:     "while the handler is running the job",
+			register: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobManager := batch.NewIsolatedJobManager()
+
+			if tt.register {
+				// the handler never answers, like a container that exited part way through a job
+				connectJobHandler(t, jobManager, "resize", nil)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			result := make(chan error, 1)
+
+			go func() {
+				result <- runIsolatedJob(ctx, jobManager, &batchpb.JobRequest{JobName: "resize"}, time.Minute)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+
+			select {
+			case err := <-result:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("runIsolatedJob() error = %v, want %v", err, context.Canceled)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("runIsolatedJob() didn't return once cancelled")
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/docker"
+	"github.com/nitrictech/cli/pkg/project/runtime"
+	"github.com/nitrictech/nitric/core/pkg/logger"
+)
+
+const buildCacheFile = "./.nitric/build-cache.json"
+
+// buildCache records the fingerprint of the build context each image was last successfully built from
+type buildCache struct {
+	fs      afero.Fs
+	builder docker.ImageBuilder
+	lock    sync.Mutex
+	entries map[string]string
+}
+
+func loadBuildCache(fs afero.Fs, builder docker.ImageBuilder) *buildCache {
+	cache := &buildCache{fs: fs, builder: builder, entries: map[string]string{}}
+
+	contents, err := afero.ReadFile(fs, buildCacheFile)
+	if err == nil {
+		// a corrupt cache only means everything is rebuilt
+		_ = json.Unmarshal(contents, &cache.entries)
+	}
+
+	return cache
+}
+
+// isCached returns true if the image was built from the same fingerprint and still exists
+func (c *buildCache) isCached(imageName string, fingerprint string) bool {
+	c.lock.Lock()
+	cached := c.entries[imageName] == fingerprint
+	c.lock.Unlock()
+
+	return cached && c.builder.ImageExists(imageName)
+}
+
+func (c *buildCache) set(imageName string, fingerprint string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[imageName] = fingerprint
+
+	contents, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = c.fs.MkdirAll(filepath.Dir(buildCacheFile), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(c.fs, buildCacheFile, contents, 0o644)
+}
+
+// build runs the build unless the image's build context is unchanged since it was last built, returning the resulting build update
+func (c *buildCache) build(serviceName string, imageName string, buildContext runtime.RuntimeBuildContext, platforms []string, build func(fingerprint string) error) ServiceBuildUpdate {
+	// if the context can't be fingerprinted the image is always built
+	fingerprint, fingerprintErr := buildFingerprint(c.fs, buildContext, platforms)
+	if fingerprintErr == nil && c.isCached(imageName, fingerprint) {
+		return ServiceBuildUpdate{
+			ServiceName: serviceName,
+			Message:     "Unchanged since last build",
+			Status:      ServiceBuildStatus_Cached,
+		}
+	}
+
+	if err := build(fingerprint); err != nil {
+		return ServiceBuildUpdate{
+			ServiceName: serviceName,
+			Err:         err,
+			Message:     err.Error(),
+			Status:      ServiceBuildStatus_Error,
+		}
+	}
+
+	if fingerprintErr == nil {
+		if err := c.set(imageName, fingerprint); err != nil {
+			logger.Errorf("unable to update build cache: %s", err)
+		}
+	}
+
+	return ServiceBuildUpdate{
+		ServiceName: serviceName,
+		Message:     "Build Complete",
+		Status:      ServiceBuildStatus_Complete,
+	}
+}
+
+type ignorePattern struct {
+	pattern string
+	regex   *regexp.Regexp
+	negate  bool
+}
+
+// ignoreRegex converts a .dockerignore pattern into a regex matching the path and everything beneath it
+func ignoreRegex(pattern string) (*regexp.Regexp, error) {
+	sb := strings.Builder{}
+
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// **/ matches zero or more directories
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in ignore pattern %s", pattern)
+			}
+
+			sb.WriteString(pattern[i : i+end+1])
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("(/.*)?$")
+
+	return regexp.Compile(sb.String())
+}
+
+func parseIgnorePatterns(contents string) ([]ignorePattern, error) {
+	patterns := []ignorePattern{}
+
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negate := strings.HasPrefix(line, "!")
+		line = strings.TrimPrefix(line, "!")
+
+		// patterns are relative to the root of the build context, like .dockerignore
+		line = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(line)), "/")
+
+		regex, err := ignoreRegex(line)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, ignorePattern{pattern: line, regex: regex, negate: negate})
+	}
+
+	return patterns, nil
+}
+
+// isIgnored applies the patterns in order, so later patterns override earlier ones
+func isIgnored(patterns []ignorePattern, path string) bool {
+	ignored := false
+
+	for _, pattern := range patterns {
+		if pattern.regex.MatchString(path) {
+			ignored = !pattern.negate
+		}
+	}
+
+	return ignored
+}
+
+// mayIncludeWithin returns true if a negated pattern could include something inside an ignored directory again,
+// patterns that match the directory itself apply to everything inside it, so only patterns matching strictly inside it can
+func mayIncludeWithin(patterns []ignorePattern, dir string) bool {
+	for _, p := range patterns {
+		if !p.negate {
+			continue
+		}
+
+		wildcard := strings.IndexAny(p.pattern, "*?[\\")
+		if wildcard < 0 {
+			if p.pattern != dir && strings.HasPrefix(p.pattern+"/", dir+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		prefix := p.pattern[:wildcard]
+		if strings.HasPrefix(dir+"/", prefix) || strings.HasPrefix(prefix, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildFingerprint hashes everything that affects an image build: the dockerfile, build args, platforms and the files sent in the build context
+func buildFingerprint(fs afero.Fs, buildContext runtime.RuntimeBuildContext, platforms []string) (string, error) {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "dockerfile:%s\n", buildContext.DockerfileContents)
+	fmt.Fprintf(hash, "ignore:%s\n", buildContext.IgnoreFileContents)
+	fmt.Fprintf(hash, "platforms:%s\n", strings.Join(platforms, ","))
+
+	argKeys := lo.Keys(buildContext.BuildArguments)
+	sort.Strings(argKeys)
+
+	for _, key := range argKeys {
+		fmt.Fprintf(hash, "arg:%s=%s\n", key, buildContext.BuildArguments[key])
+	}
+
+	patterns, err := parseIgnorePatterns(buildContext.IgnoreFileContents)
+	if err != nil {
+		return "", err
+	}
+
+	baseDir := buildContext.BaseDirectory
+	if baseDir == "" {
+		baseDir = "."
+	}
+
+	// afero.Walk visits files in lexical order, keeping the fingerprint stable
+	err = afero.Walk(fs, baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+		if relPath == "." {
+			return nil
+		}
+
+		if isIgnored(patterns, relPath) {
+			// ignored directories can only be skipped if no pattern could include something inside them again
+			if info.IsDir() && !mayIncludeWithin(patterns, relPath) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			fmt.Fprintf(hash, "entry:%s:%s\n", relPath, info.Mode().Type())
+			return nil
+		}
+
+		file, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		fileHash := sha256.New()
+
+		_, err = io.Copy(fileHash, file)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "file:%s:%s:%x\n", relPath, info.Mode().Perm(), fileHash.Sum(nil))
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to fingerprint build context %s: %w", baseDir, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
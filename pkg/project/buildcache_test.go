@@ -0,0 +1,90 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/project/runtime"
+)
+
+func TestIsIgnored(t *testing.T) {
+	patterns, err := parseIgnorePatterns("# comment\nnode_modules/\n*.log\n**/__pycache__\n.nitric/\n!.nitric/*.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		path    string
+		ignored bool
+	}{
+		{path: "node_modules", ignored: true},
+		{path: "node_modules/lodash/index.js", ignored: true},
+		{path: "services/node_modules/index.js", ignored: false},
+		{path: "debug.log", ignored: true},
+		{path: "logs/debug.log", ignored: false},
+		{path: "lib/deep/__pycache__/mod.pyc", ignored: true},
+		{path: ".nitric/build/service.dockerfile", ignored: true},
+		{path: ".nitric/local.yaml", ignored: false},
+		{path: "services/api.ts", ignored: false},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			if ignored := isIgnored(patterns, tt.path); ignored != tt.ignored {
+				t.Errorf("expected ignored to be %t, got %t", tt.ignored, ignored)
+			}
+		})
+	}
+}
+
+func TestBuildFingerprint(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "project/services/api.ts", []byte("api"), 0o644)
+	_ = afero.WriteFile(fs, "project/node_modules/lib/index.js", []byte("lib"), 0o644)
+
+	buildContext := runtime.RuntimeBuildContext{
+		DockerfileContents: "FROM node",
+		BaseDirectory:      "project",
+		BuildArguments:     map[string]string{"HANDLER": "services/api.ts"},
+		IgnoreFileContents: "node_modules/",
+	}
+
+	fingerprint := func() string {
+		fp, err := buildFingerprint(fs, buildContext, []string{"linux/amd64"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return fp
+	}
+
+	original := fingerprint()
+
+	_ = afero.WriteFile(fs, "project/node_modules/lib/index.js", []byte("changed"), 0o644)
+
+	if fingerprint() != original {
+		t.Errorf("expected ignored files not to change the fingerprint")
+	}
+
+	_ = afero.WriteFile(fs, "project/services/api.ts", []byte("changed"), 0o644)
+
+	if fingerprint() == original {
+		t.Errorf("expected a changed source file to change the fingerprint")
+	}
+}
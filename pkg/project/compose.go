@@ -0,0 +1,325 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/version"
+)
+
+const (
+	// HeadlessServerBasePort is the port of the first service's nitric server in headless mode, services are followed by batches
+	HeadlessServerBasePort = 50051
+
+	composeLocalCloudService = "nitric"
+	composeSqlService        = "postgres"
+	composeProjectDir        = "/app"
+)
+
+type ComposeBuild struct {
+	Context          string `yaml:"context"`
+	DockerfileInline string `yaml:"dockerfile_inline"`
+}
+
+type ComposeDependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type ComposeHealthcheck struct {
+	Test     []string `yaml:"test"`
+	Interval string   `yaml:"interval,omitempty"`
+	Timeout  string   `yaml:"timeout,omitempty"`
+	Retries  int      `yaml:"retries,omitempty"`
+}
+
+type ComposeResourceLimits struct {
+	Cpus   string `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+type ComposeResources struct {
+	Limits ComposeResourceLimits `yaml:"limits"`
+}
+
+type ComposeDeploy struct {
+	Resources ComposeResources `yaml:"resources"`
+}
+
+type ComposeService struct {
+	Image       string                       `yaml:"image,omitempty"`
+	Build       *ComposeBuild                `yaml:"build,omitempty"`
+	Command     []string                     `yaml:"command,omitempty"`
+	WorkingDir  string                       `yaml:"working_dir,omitempty"`
+	Environment map[string]string            `yaml:"environment,omitempty"`
+	Ports       []string                     `yaml:"ports,omitempty"`
+	Volumes     []string                     `yaml:"volumes,omitempty"`
+	DependsOn   map[string]ComposeDependency `yaml:"depends_on,omitempty"`
+	Healthcheck *ComposeHealthcheck          `yaml:"healthcheck,omitempty"`
+	Deploy      *ComposeDeploy               `yaml:"deploy,omitempty"`
+}
+
+type ComposeVolume struct {
+	Name string `yaml:"name,omitempty"`
+}
+
+// ComposeFile describes the project's services running against a headless local cloud with docker compose
+type ComposeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]ComposeService `yaml:"services"`
+	Volumes  map[string]ComposeVolume  `yaml:"volumes,omitempty"`
+}
+
+var invalidComposeNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// composeName normalizes a name to the characters allowed in compose project and service names
+func composeName(name string) string {
+	return strings.Trim(invalidComposeNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-_")
+}
+
+// composeEscape escapes values so compose doesn't interpolate them
+func composeEscape(env map[string]string) map[string]string {
+	return lo.MapValues(env, func(value string, _ string) string {
+		return strings.ReplaceAll(value, "$", "$$")
+	})
+}
+
+// cliInstallVersion returns the version of the CLI to install in the local cloud container, released builds install themselves
+func cliInstallVersion() string {
+	if _, err := semver.NewVersion(version.Version); err != nil {
+		return "latest"
+	}
+
+	return strings.TrimPrefix(version.Version, "v")
+}
+
+// HeadlessServerPorts returns the fixed nitric server port of each service and batch by file path
+func (p *Project) HeadlessServerPorts() map[string]int {
+	ports := map[string]int{}
+
+	for i, svc := range p.services {
+		ports[svc.GetFilePath()] = HeadlessServerBasePort + i
+	}
+
+	for i, batch := range p.batches {
+		ports[batch.GetFilePath()] = HeadlessServerBasePort + len(p.services) + i
+	}
+
+	return ports
+}
+
+// RegisterHeadlessServers starts a nitric server for each service and batch on its headless port, without running them
+func (p *Project) RegisterHeadlessServers(localCloud *cloud.LocalCloud) error {
+	ports := p.HeadlessServerPorts()
+
+	for _, svc := range p.services {
+		if _, err := localCloud.AddServiceOnPort(svc.GetFilePath(), ports[svc.GetFilePath()]); err != nil {
+			return fmt.Errorf("unable to add service %s: %w", svc.GetFilePath(), err)
+		}
+	}
+
+	for _, batch := range p.batches {
+		if _, err := localCloud.AddBatchOnPort(batch.GetFilePath(), ports[batch.GetFilePath()]); err != nil {
+			return fmt.Errorf("unable to add batch %s: %w", batch.GetFilePath(), err)
+		}
+	}
+
+	return nil
+}
+
+// GatewayPorts returns the fixed local gateway ports from local.nitric.yaml, only these can be published by compose
+func (p *Project) GatewayPorts() []int {
+	ports := []int{}
+
+	for _, api := range p.LocalConfig.Apis {
+		ports = append(ports, api.Port)
+	}
+
+	for _, ws := range p.LocalConfig.Websockets {
+		ports = append(ports, ws.Port)
+	}
+
+	for _, http := range p.LocalConfig.Http {
+		ports = append(ports, http.Port)
+	}
+
+	ports = append(ports, p.LocalConfig.Ingress.Port)
+
+	ports = lo.Uniq(lo.Filter(ports, func(port int, _ int) bool { return port > 0 }))
+	sort.Ints(ports)
+
+	return ports
+}
+
+func composeDeploy(resources ResourcesConfiguration) *ComposeDeploy {
+	if resources.Cpus <= 0 && resources.Memory <= 0 {
+		return nil
+	}
+
+	limits := ComposeResourceLimits{}
+
+	if resources.Cpus > 0 {
+		limits.Cpus = strconv.FormatFloat(resources.Cpus, 'f', -1, 64)
+	}
+
+	if resources.Memory > 0 {
+		limits.Memory = fmt.Sprintf("%dM", resources.Memory)
+	}
+
+	return &ComposeDeploy{Resources: ComposeResources{Limits: limits}}
+}
+
+// composeAppService describes a service or batch container connected to its server in the local cloud container
+func composeAppService(image string, port int, env map[string]string, resources ResourcesConfiguration) ComposeService {
+	return ComposeService{
+		Image: image,
+		Environment: lo.Assign(composeEscape(env), map[string]string{
+			"NITRIC_ENVIRONMENT":  "run",
+			"SERVICE_ADDRESS":     fmt.Sprintf("%s:%d", composeLocalCloudService, port),
+			"NITRIC_SERVICE_PORT": strconv.Itoa(port),
+			"NITRIC_SERVICE_HOST": composeLocalCloudService,
+		}),
+		DependsOn: map[string]ComposeDependency{
+			composeLocalCloudService: {Condition: "service_started"},
+		},
+		Deploy: composeDeploy(resources),
+	}
+}
+
+// Compose describes the project as a docker compose file written to composeDir, services use their built images and connect to a headless local cloud
+func (p *Project) Compose(composeDir string, env map[string]string) (*ComposeFile, error) {
+	projectDir, err := filepath.Abs(p.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	composeDir, err = filepath.Abs(composeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// relative paths keep the compose file portable alongside the project
+	relProjectDir, err := filepath.Rel(composeDir, projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	relProjectDir = filepath.ToSlash(relProjectDir)
+	if !strings.HasPrefix(relProjectDir, ".") {
+		relProjectDir = "./" + relProjectDir
+	}
+
+	sqlVolume := fmt.Sprintf("%s-local-sql", p.Name)
+
+	compose := &ComposeFile{
+		Name:     composeName(p.Name),
+		Services: map[string]ComposeService{},
+		Volumes: map[string]ComposeVolume{
+			// share the database with nitric run
+			sqlVolume: {Name: sqlVolume},
+		},
+	}
+
+	compose.Services[composeSqlService] = ComposeService{
+		Image: "postgres:latest",
+		Environment: map[string]string{
+			"POSTGRES_PASSWORD": "localsecret",
+			"PGDATA":            "/var/lib/postgresql/data/pgdata",
+		},
+		Volumes: []string{sqlVolume + ":/var/lib/postgresql/data"},
+		Healthcheck: &ComposeHealthcheck{
+			Test:     []string{"CMD-SHELL", "pg_isready -U postgres"},
+			Interval: "2s",
+			Timeout:  "5s",
+			Retries:  15,
+		},
+	}
+
+	compose.Services[composeLocalCloudService] = ComposeService{
+		Build: &ComposeBuild{
+			Context: relProjectDir,
+			DockerfileInline: strings.Join([]string{
+				"FROM debian:bookworm-slim",
+				"RUN apt-get update && apt-get install -y --no-install-recommends curl ca-certificates && rm -rf /var/lib/apt/lists/*",
+				fmt.Sprintf("RUN curl -L \"https://nitric.io/install?version=%s\" | bash", cliInstallVersion()),
+				"ENV PATH=\"/root/.nitric/bin:$${PATH}\"",
+				"",
+			}, "\n"),
+		},
+		Command:    []string{"nitric", "local-cloud"},
+		WorkingDir: composeProjectDir,
+		Environment: map[string]string{
+			"NITRIC_SQL_HOST": composeSqlService,
+			"NITRIC_SQL_PORT": "5432",
+		},
+		Ports: lo.Map(p.GatewayPorts(), func(port int, _ int) string {
+			return fmt.Sprintf("%d:%d", port, port)
+		}),
+		Volumes: []string{relProjectDir + ":" + composeProjectDir},
+		DependsOn: map[string]ComposeDependency{
+			composeSqlService: {Condition: "service_healthy"},
+		},
+	}
+
+	ports := p.HeadlessServerPorts()
+
+	for _, svc := range p.services {
+		name := composeName(svc.Name)
+		if _, ok := compose.Services[name]; ok {
+			return nil, fmt.Errorf("service %s conflicts with another compose service named %s", svc.GetFilePath(), name)
+		}
+
+		compose.Services[name] = composeAppService(svc.image, ports[svc.GetFilePath()], lo.Assign(env, svc.env), svc.resources)
+	}
+
+	for _, batch := range p.batches {
+		name := composeName(batch.Name)
+		if _, ok := compose.Services[name]; ok {
+			return nil, fmt.Errorf("batch %s conflicts with another compose service named %s", batch.GetFilePath(), name)
+		}
+
+		compose.Services[name] = composeAppService(batch.image, ports[batch.GetFilePath()], lo.Assign(env, batch.env), batch.resources)
+	}
+
+	return compose, nil
+}
+
+// WriteComposeFile writes the compose file to the given path
+func WriteComposeFile(fs afero.Fs, compose *ComposeFile, path string) error {
+	contents, err := yaml.Marshal(compose)
+	if err != nil {
+		return err
+	}
+
+	err = fs.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, contents, 0o600)
+}
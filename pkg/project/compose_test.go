@@ -0,0 +1,83 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nitrictech/cli/pkg/project/localconfig"
+)
+
+func TestCompose(t *testing.T) {
+	proj := &Project{
+		Name:      "My App",
+		Directory: "/work/app",
+		LocalConfig: localconfig.LocalConfiguration{
+			Apis: map[string]localconfig.LocalApiConfiguration{
+				"main": {LocalResourceConfiguration: localconfig.LocalResourceConfiguration{Port: 4001}},
+			},
+		},
+		services: []Service{
+			{Name: "app_services-api", basedir: "services", filepath: "api.ts", image: "app/app_services-api", env: map[string]string{"LEVEL": "debug"}, resources: ResourcesConfiguration{Cpus: 0.5, Memory: 256}},
+		},
+		batches: []Batch{
+			{Name: "app_batches-job", basedir: "batches", filepath: "job.ts", image: "app/app_batches-job"},
+		},
+	}
+
+	compose, err := proj.Compose("/work/app/deploy", map[string]string{"LEVEL": "info", "REGION": "local"})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+
+	if compose.Name != "my-app" {
+		t.Errorf("Compose() name = %s, want my-app", compose.Name)
+	}
+
+	localCloud := compose.Services[composeLocalCloudService]
+	if diff := cmp.Diff([]string{"..:/app"}, localCloud.Volumes); diff != "" {
+		t.Errorf("local cloud volumes mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"4001:4001"}, localCloud.Ports); diff != "" {
+		t.Errorf("local cloud ports mismatch (-want +got):\n%s", diff)
+	}
+
+	wantApi := ComposeService{
+		Image: "app/app_services-api",
+		Environment: map[string]string{
+			"LEVEL":               "debug",
+			"REGION":              "local",
+			"NITRIC_ENVIRONMENT":  "run",
+			"SERVICE_ADDRESS":     "nitric:50051",
+			"NITRIC_SERVICE_PORT": "50051",
+			"NITRIC_SERVICE_HOST": "nitric",
+		},
+		DependsOn: map[string]ComposeDependency{"nitric": {Condition: "service_started"}},
+		Deploy:    &ComposeDeploy{Resources: ComposeResources{Limits: ComposeResourceLimits{Cpus: "0.5", Memory: "256M"}}},
+	}
+
+	if diff := cmp.Diff(wantApi, compose.Services["app_services-api"]); diff != "" {
+		t.Errorf("api service mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := compose.Services["app_batches-job"].Environment["SERVICE_ADDRESS"]; got != "nitric:50052" {
+		t.Errorf("batch SERVICE_ADDRESS = %s, want nitric:50052", got)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
@@ -34,6 +35,11 @@ type RuntimeConfiguration struct {
 	Context string
 	// Additional args to pass to the custom runtime
 	Args map[string]string
+	// Paths relative to the context to include in the build context, e.g. lockfiles and workspace manifests.
+	// When set with packages, each service's build context only includes its own directory, these paths and the packages
+	Include []string
+	// Shared local packages relative to the context that services depend on, they're passed to the dockerfile as the PACKAGES build arg
+	Packages []string
 }
 
 type BaseService interface {
@@ -41,6 +47,64 @@ type BaseService interface {
 	GetMatch() string
 	GetRuntime() string
 	GetStart() string
+	GetPlatforms() []string
+	GetBuildArgs() map[string]string
+}
+
+type ResourcesConfiguration struct {
+	// CPUs available to the container when run locally, e.g. 0.5
+	Cpus float64 `yaml:"cpus,omitempty"`
+
+	// Memory limit in MiB for the container when run locally
+	Memory int64 `yaml:"memory,omitempty"`
+}
+
+type RestartPolicy string
+
+const (
+	RestartPolicy_Never     RestartPolicy = "never"
+	RestartPolicy_OnFailure RestartPolicy = "on-failure"
+	RestartPolicy_Always    RestartPolicy = "always"
+)
+
+type RestartConfiguration struct {
+	// When the service is restarted after it exits or becomes unhealthy locally: never (the default), on-failure or always
+	Policy RestartPolicy `yaml:"policy,omitempty"`
+
+	// Delay before the first restart, doubled for each consecutive restart up to max-backoff, defaults to 1s
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+
+	// Longest delay between restarts, defaults to 30s
+	MaxBackoff time.Duration `yaml:"max-backoff,omitempty"`
+
+	// Restarts allowed within crash-loop-window before the service is considered crash looping and no longer restarted, defaults to 5
+	CrashLoopRestarts int `yaml:"crash-loop-restarts,omitempty"`
+
+	// Window crash-loop-restarts are counted over, defaults to 1m
+	CrashLoopWindow time.Duration `yaml:"crash-loop-window,omitempty"`
+}
+
+type HealthCheckConfiguration struct {
+	// Command run in the service's container, or in its basedir for start commands, the service is healthy when it exits 0
+	Command []string `yaml:"command,omitempty"`
+
+	// Path requested from the service over http, the service is healthy when it responds with a status below 400
+	Path string `yaml:"path,omitempty"`
+
+	// Port the http probe is sent to, defaults to the service's http proxy port when run in a container
+	Port int `yaml:"port,omitempty"`
+
+	// Time between checks, defaults to 10s
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// Time a check can take before it fails, defaults to 5s
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// Consecutive failed checks before the service is unhealthy, defaults to 3
+	Retries int `yaml:"retries,omitempty"`
+
+	// Time after the service starts during which failed checks aren't counted
+	StartPeriod time.Duration `yaml:"start-period,omitempty"`
 }
 
 type BaseServiceConfiguration struct {
@@ -55,6 +119,21 @@ type BaseServiceConfiguration struct {
 
 	// This is a command that will be use to run these services when using nitric start
 	Start string `yaml:"start"`
+
+	// The platforms images can be built for (e.g. linux/arm64), all of them are built unless a stack or nitric run requests a subset
+	Platforms []string `yaml:"platforms,omitempty"`
+
+	// Env files for these services relative to the project directory, overriding the project's .env files
+	EnvFiles []string `yaml:"env-files,omitempty"`
+
+	// Env vars for these services, overriding their env files
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// Additional build args passed to the dockerfile, overriding the runtime's args
+	BuildArgs map[string]string `yaml:"build-args,omitempty"`
+
+	// Resource limits for the containers run locally
+	Resources ResourcesConfiguration `yaml:"resources,omitempty"`
 }
 
 func (b BaseServiceConfiguration) GetBasedir() string {
@@ -73,17 +152,39 @@ func (b BaseServiceConfiguration) GetStart() string {
 	return b.Start
 }
 
+func (b BaseServiceConfiguration) GetPlatforms() []string {
+	return b.Platforms
+}
+
+func (b BaseServiceConfiguration) GetBuildArgs() map[string]string {
+	return b.BuildArgs
+}
+
 type ServiceConfiguration struct {
 	BaseServiceConfiguration `yaml:",inline"`
 
 	// This allows specifying a particular service type (e.g. "Job"), this is optional and custom service types can be defined for each stack
 	Type string `yaml:"type,omitempty"`
+
+	// Restart policy for the service when run locally
+	Restart RestartConfiguration `yaml:"restart,omitempty"`
+
+	// Health check for the service when run locally
+	HealthCheck HealthCheckConfiguration `yaml:"health-check,omitempty"`
 }
 
 type BatchConfiguration struct {
 	BaseServiceConfiguration `yaml:",inline"`
 }
 
+type BuildConfiguration struct {
+	// Builder is the image builder to use, docker (the default) or buildkit to build without a docker daemon
+	Builder string `yaml:"builder,omitempty"`
+
+	// Output is the directory daemonless builders write OCI image archives to, defaults to .nitric/images
+	Output string `yaml:"output,omitempty"`
+}
+
 type ProjectConfiguration struct {
 	Name      string                          `yaml:"name"`
 	Directory string                          `yaml:"-"`
@@ -92,6 +193,7 @@ type ProjectConfiguration struct {
 	Batches   []BatchConfiguration            `yaml:"batch-services"`
 	Runtimes  map[string]RuntimeConfiguration `yaml:"runtimes,omitempty"`
 	Preview   []preview.Feature               `yaml:"preview,omitempty"`
+	Build     BuildConfiguration              `yaml:"build,omitempty"`
 }
 
 const defaultNitricYamlPath = "./nitric.yaml"
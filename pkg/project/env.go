@@ -0,0 +1,62 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/joho/godotenv"
+	"github.com/samber/lo"
+	"github.com/spf13/afero"
+)
+
+// loadServiceEnv reads a service's env files relative to the project directory in order, then applies its inline env vars
+func loadServiceEnv(fs afero.Fs, projectDir string, envFiles []string, inlineEnv map[string]string) (map[string]string, error) {
+	serviceEnv := map[string]string{}
+
+	for _, envFile := range envFiles {
+		envPath := filepath.Join(projectDir, envFile)
+
+		contents, err := afero.ReadFile(fs, envPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read env file %s: %w", envPath, err)
+		}
+
+		fileEnv, err := godotenv.Parse(bytes.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse env file %s: %w", envPath, err)
+		}
+
+		serviceEnv = lo.Assign(serviceEnv, fileEnv)
+	}
+
+	return lo.Assign(serviceEnv, inlineEnv), nil
+}
+
+// applyResourceLimits limits a container to the CPUs and memory configured for its service
+func applyResourceLimits(hostConfig *container.HostConfig, resources ResourcesConfiguration) {
+	if resources.Cpus > 0 {
+		hostConfig.Resources.NanoCPUs = int64(resources.Cpus * 1e9)
+	}
+
+	if resources.Memory > 0 {
+		hostConfig.Resources.Memory = resources.Memory * 1024 * 1024
+	}
+}
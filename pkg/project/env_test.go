@@ -0,0 +1,73 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func TestLoadServiceEnv(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "project/.env.api", []byte("LOG_LEVEL=info\nREGION=local\n"), 0o644)
+	_ = afero.WriteFile(fs, "project/.env.api.local", []byte("LOG_LEVEL=debug\n"), 0o644)
+
+	tests := []struct {
+		name      string
+		envFiles  []string
+		inlineEnv map[string]string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "no env",
+			want: map[string]string{},
+		},
+		{
+			name:     "later env files override earlier ones",
+			envFiles: []string{".env.api", ".env.api.local"},
+			want:     map[string]string{"LOG_LEVEL": "debug", "REGION": "local"},
+		},
+		{
+			name:      "inline env overrides env files",
+			envFiles:  []string{".env.api"},
+			inlineEnv: map[string]string{"REGION": "us-east-1"},
+			want:      map[string]string{"LOG_LEVEL": "info", "REGION": "us-east-1"},
+		},
+		{
+			name:     "missing env file",
+			envFiles: []string{".env.missing"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadServiceEnv(fs, "project", tt.envFiles, tt.inlineEnv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadServiceEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Errorf("loadServiceEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/docker"
+)
+
+var notImageNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// projectImageScope returns a name unique to the project on this machine, so projects with the same name don't share images
+func projectImageScope(projectName string, projectDir string) string {
+	name := strings.Trim(notImageNameChars.ReplaceAllString(strings.ToLower(projectName), "-"), "-._")
+	if name == "" {
+		name = "nitric"
+	}
+
+	absDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		absDir = projectDir
+	}
+
+	return fmt.Sprintf("%s-%x", name, sha256.Sum256([]byte(absDir)))[:len(name)+9]
+}
+
+// imageName returns the repository of a service's image within the project's scope, the image is tagged latest and with its content digest
+func imageName(scope string, serviceName string) string {
+	return fmt.Sprintf("%s/%s", scope, notImageNameChars.ReplaceAllString(strings.ToLower(serviceName), "-"))
+}
+
+// digestTag returns the tag for an image built from a build context fingerprint
+func digestTag(image string, fingerprint string) string {
+	return fmt.Sprintf("%s:%s", image, fingerprint[:min(12, len(fingerprint))])
+}
+
+func imageBuildOptions(scope string, serviceName string, image string, fingerprint string) []docker.DockerBuildOption {
+	labels := map[string]string{
+		docker.LabelProject: scope,
+		docker.LabelService: serviceName,
+	}
+
+	tags := []string{}
+
+	if fingerprint != "" {
+		labels[docker.LabelDigest] = fingerprint
+		tags = append(tags, digestTag(image, fingerprint))
+	}
+
+	return []docker.DockerBuildOption{docker.WithLabels(labels), docker.WithAdditionalTags(tags)}
+}
+
+// ProjectImage is a local image built by the CLI for this project
+type ProjectImage struct {
+	Id      string
+	Service string
+	Tags    []string
+	Created time.Time
+	Size    int64
+	// Current is true if the image is the latest build of a service still in the project
+	Current bool
+}
+
+// ListImages returns the images built for this project, newest first
+func (p *Project) ListImages() ([]ProjectImage, error) {
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := dockerClient.ListImages(map[string]string{docker.LabelProject: p.imageScope})
+	if err != nil {
+		return nil, err
+	}
+
+	currentImages := append(
+		lo.Map(p.services, func(s Service, _ int) string { return s.image + ":latest" }),
+		lo.Map(p.batches, func(b Batch, _ int) string { return b.image + ":latest" })...,
+	)
+
+	images := lo.Map(summaries, func(summary image.Summary, _ int) ProjectImage {
+		return ProjectImage{
+			Id:      summary.ID,
+			Service: summary.Labels[docker.LabelService],
+			Tags:    summary.RepoTags,
+			Created: time.Unix(summary.Created, 0),
+			Size:    summary.Size,
+			Current: lo.Some(summary.RepoTags, currentImages),
+		}
+	})
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Created.After(images[j].Created)
+	})
+
+	return images, nil
+}
+
+// PruneImages removes the images built for this project that aren't the latest build of a current service, returning the removed images
+func (p *Project) PruneImages() ([]ProjectImage, error) {
+	images, err := p.ListImages()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []ProjectImage{}
+
+	for _, img := range images {
+		if img.Current {
+			continue
+		}
+
+		if err := dockerClient.RemoveImage(img.Id); err != nil {
+			return removed, fmt.Errorf("unable to remove image %s: %w", img.Id, err)
+		}
+
+		removed = append(removed, img)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,44 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestImageName(t *testing.T) {
+	first := projectImageScope("My Project", "/src/first")
+	second := projectImageScope("My Project", "/src/second")
+
+	if first == second {
+		t.Errorf("expected projects with the same name in different directories to have different scopes, got %s", first)
+	}
+
+	if first != projectImageScope("My Project", "/src/first") {
+		t.Errorf("expected the scope to be stable")
+	}
+
+	image := imageName(first, "my-project_services-api")
+	if !regexp.MustCompile(`^my-project-[0-9a-f]{8}/my-project_services-api$`).MatchString(image) {
+		t.Errorf("unexpected image name %s", image)
+	}
+
+	if tag := digestTag(image, "0123456789abcdef0123"); tag != image+":0123456789ab" {
+		t.Errorf("unexpected digest tag %s", tag)
+	}
+}
@@ -19,6 +19,7 @@ package localconfig
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
@@ -29,9 +30,126 @@ type LocalResourceConfiguration struct {
 	Port int `yaml:"port"`
 }
 
+type LocalCorsConfiguration struct {
+	// AllowOrigins lists the origins permitted to call the API, use "*" to allow any origin
+	AllowOrigins []string `yaml:"allowOrigins"`
+	// AllowMethods lists the methods permitted in preflight requests, defaults to the methods registered for the route
+	AllowMethods []string `yaml:"allowMethods"`
+	// AllowHeaders lists the request headers permitted in preflight requests, defaults to the headers requested
+	AllowHeaders []string `yaml:"allowHeaders"`
+	// ExposeHeaders lists the response headers made available to the browser
+	ExposeHeaders []string `yaml:"exposeHeaders"`
+	// AllowCredentials permits cookies and authorization headers to be sent with cross-origin requests
+	AllowCredentials bool `yaml:"allowCredentials"`
+	// MaxAge is the number of seconds a preflight response can be cached for
+	MaxAge int `yaml:"maxAge"`
+}
+
+type LocalApiConfiguration struct {
+	LocalResourceConfiguration `yaml:",inline"`
+	// Cors enables CORS handling by the local gateway for this API
+	Cors *LocalCorsConfiguration `yaml:"cors"`
+}
+
+type LocalWebsocketConfiguration struct {
+	LocalResourceConfiguration `yaml:",inline"`
+	// AwsCompatWarnings logs a warning when binary messages are sent, as they aren't supported by AWS API Gateway websockets
+	AwsCompatWarnings bool `yaml:"awsCompatWarnings"`
+	// BinaryWorkerMessages sends messages from services as binary frames. Services can't choose the frame type of a message,
+	// so by default they're sent as text frames like AWS API Gateway websockets
+	BinaryWorkerMessages bool `yaml:"binaryWorkerMessages"`
+	// IdleTimeout disconnects clients that haven't sent a message within the duration, disabled by default (AWS API Gateway uses 10m)
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	// MaxLifetime disconnects clients once they've been connected for the duration, disabled by default (AWS API Gateway uses 2h)
+	MaxLifetime time.Duration `yaml:"maxLifetime"`
+	// PingInterval is how often keepalive pings are sent to clients, clients that don't answer a ping are disconnected.
+	// Defaults to 30s, a negative value disables them
+	PingInterval time.Duration `yaml:"pingInterval"`
+}
+
+type LocalSecurityDefinitionConfiguration struct {
+	// JwksUrl overrides the JWKS used to validate tokens, use "local" for tokens minted by the local dashboard
+	JwksUrl string `yaml:"jwksUrl"`
+	// Issuer overrides the expected token issuer when a custom JwksUrl is used
+	Issuer string `yaml:"issuer"`
+}
+
+type LocalApiSecurityConfiguration struct {
+	// Enabled enforces the declared API security rules on requests to local APIs
+	Enabled bool `yaml:"enabled"`
+	// Definitions overrides security definitions by name, for every api that declares them
+	Definitions map[string]LocalSecurityDefinitionConfiguration `yaml:"definitions"`
+	// Apis overrides the security definitions of a single api, by api name then definition name, taking precedence over Definitions
+	Apis map[string]map[string]LocalSecurityDefinitionConfiguration `yaml:"apis"`
+}
+
+// Override returns the override for an api's security definition, if any
+func (c LocalApiSecurityConfiguration) Override(apiName string, definitionName string) LocalSecurityDefinitionConfiguration {
+	if override, ok := c.Apis[apiName][definitionName]; ok {
+		return override
+	}
+
+	return c.Definitions[definitionName]
+}
+
+type LocalApiValidationConfiguration struct {
+	// Enabled validates requests to local APIs against their OpenAPI document, rejecting invalid requests with a 400.
+	// Meaningful validation needs a document in Specs, see below.
+	Enabled bool `yaml:"enabled"`
+	// Responses also validates worker responses, violations are reported but the response is still returned.
+	// It only applies to APIs with a document in Specs, generated documents don't describe responses.
+	Responses bool `yaml:"responses"`
+	// Specs maps API names to OpenAPI documents to validate against instead of the generated document.
+	// Routes registered by services carry no schemas, so the generated document only describes paths, methods and
+	// path params. Query params, request bodies and responses are only validated when a spec is provided here.
+	Specs map[string]string `yaml:"specs"`
+}
+
+type LocalIngressRouteConfiguration struct {
+	// Host matches the request host header, a leading "*." matches any subdomain, empty matches any host
+	Host string `yaml:"host"`
+	// PathPrefix matches the start of the request path, empty matches any path
+	PathPrefix string `yaml:"pathPrefix"`
+	// Service is the name of the service running the http proxy to route matching requests to
+	Service string `yaml:"service"`
+	// StripPrefix removes the matched path prefix before the request is forwarded
+	StripPrefix bool `yaml:"stripPrefix"`
+}
+
+type LocalIngressConfiguration struct {
+	LocalResourceConfiguration `yaml:",inline"`
+	// Routes are matched by the most specific host, then the longest path prefix
+	Routes []LocalIngressRouteConfiguration `yaml:"routes"`
+}
+
+type LocalJobConfiguration struct {
+	// Parallelism overrides the batch parallelism for this job
+	Parallelism int `yaml:"parallelism"`
+}
+
+type LocalBatchConfiguration struct {
+	// Parallelism is the number of runs of each job allowed at once, defaults to as many as the job's CPU requirements allow
+	Parallelism int `yaml:"parallelism"`
+	// Jobs configures individual jobs by name
+	Jobs map[string]LocalJobConfiguration `yaml:"jobs"`
+	// Isolated runs every job in a fresh container from the batch image, with the job's CPU and memory requirements applied, only supported by nitric run
+	Isolated bool `yaml:"isolated"`
+}
+
+type LocalPoliciesConfiguration struct {
+	// Strict rejects runtime requests to buckets, key value stores, topics, queues, secrets and databases a service hasn't been granted access to
+	Strict bool `yaml:"strict"`
+}
+
 type LocalConfiguration struct {
-	Apis       map[string]LocalResourceConfiguration `yaml:"apis"`
-	Websockets map[string]LocalResourceConfiguration `yaml:"websockets"`
+	Apis          map[string]LocalApiConfiguration       `yaml:"apis"`
+	Websockets    map[string]LocalWebsocketConfiguration `yaml:"websockets"`
+	Http          map[string]LocalResourceConfiguration  `yaml:"http"`
+	ApiSecurity   LocalApiSecurityConfiguration          `yaml:"apiSecurity"`
+	ApiValidation LocalApiValidationConfiguration        `yaml:"apiValidation"`
+	Ingress       LocalIngressConfiguration              `yaml:"ingress"`
+	Batch         LocalBatchConfiguration                `yaml:"batch"`
+	Policies      LocalPoliciesConfiguration             `yaml:"policies"`
 }
 
 const defaultLocalNitricYamlPath = "./local.nitric.yaml"
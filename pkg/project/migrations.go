@@ -22,10 +22,12 @@ import (
 	"io"
 	"os"
 	goruntime "runtime"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/samber/lo"
 	"github.com/spf13/afero"
 
 	"github.com/nitrictech/cli/pkg/cloud/sql"
@@ -50,7 +52,14 @@ func migrationImageName(dbName string) string {
 	return fmt.Sprintf("%s-migrations", dbName)
 }
 
-func BuildAndRunMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
+// MigrationRunner returns the migration runner for local clouds, it builds migration images with the project's configured builder
+func (p *Project) MigrationRunner() sql.MigrationRunner {
+	return func(fs afero.Fs, servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
+		return buildAndRunMigrations(fs, p.ImageBuilder, servers, databasesToMigrate, useBuilder)
+	}
+}
+
+func buildAndRunMigrations(fs afero.Fs, newBuilder func() (docker.ImageBuilder, error), servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
 	serviceRequirements := collector.MakeDatabaseServiceRequirements(databasesToMigrate)
 
 	migrationImageContexts, err := collector.GetMigrationImageBuildContexts(serviceRequirements, []*collector.BatchRequirements{}, fs)
@@ -59,7 +68,20 @@ func BuildAndRunMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer,
 	}
 
 	if len(migrationImageContexts) > 0 {
-		updates, err := BuildMigrationImages(fs, migrationImageContexts, useBuilder)
+		builder, err := newBuilder()
+		if err != nil {
+			return err
+		}
+
+		// migrations are run locally, so their images must be loaded into the local container engine
+		if builder.Daemonless() {
+			databases := lo.Keys(migrationImageContexts)
+			slices.Sort(databases)
+
+			return fmt.Errorf("migrations for %s are run in containers, which daemonless builders can't build images for, use the %s builder to run them locally", strings.Join(databases, ", "), docker.BuilderDocker)
+		}
+
+		updates, err := BuildMigrationImages(fs, builder, migrationImageContexts, useBuilder)
 		if err != nil {
 			return err
 		}
@@ -80,16 +102,30 @@ func BuildAndRunMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer,
 	return nil
 }
 
-func BuildMigrationImage(fs afero.Fs, dbName string, buildContext *runtime.RuntimeBuildContext, logs io.Writer, useBuilder bool) error {
-	tempBuildDir := GetTempBuildDir()
-	svcName := migrationImageName(dbName)
+// RejectMigrations is the migration runner for local clouds without a container engine to build and run migration images
+func RejectMigrations(fs afero.Fs, servers map[string]*sql.DatabaseServer, databasesToMigrate map[string]*resourcespb.SqlDatabaseResource, useBuilder bool) error {
+	serviceRequirements := collector.MakeDatabaseServiceRequirements(databasesToMigrate)
 
-	dockerClient, err := docker.New()
+	migrationImageContexts, err := collector.GetMigrationImageBuildContexts(serviceRequirements, []*collector.BatchRequirements{}, fs)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get migration image build contexts: %w", err)
+	}
+
+	if len(migrationImageContexts) > 0 {
+		databases := lo.Keys(migrationImageContexts)
+		slices.Sort(databases)
+
+		return fmt.Errorf("migrations for %s can't be run without a container engine, apply them to the database directly", strings.Join(databases, ", "))
 	}
 
-	err = fs.MkdirAll(tempBuildDir, os.ModePerm)
+	return nil
+}
+
+func BuildMigrationImage(fs afero.Fs, builder docker.ImageBuilder, dbName string, buildContext *runtime.RuntimeBuildContext, logs io.Writer, useBuilder bool) error {
+	tempBuildDir := GetTempBuildDir()
+	svcName := migrationImageName(dbName)
+
+	err := fs.MkdirAll(tempBuildDir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("unable to create temporary build directory %s: %w", tempBuildDir, err)
 	}
@@ -113,7 +149,7 @@ func BuildMigrationImage(fs afero.Fs, dbName string, buildContext *runtime.Runti
 	}()
 
 	// build the docker image
-	err = dockerClient.Build(
+	err = builder.Build(
 		tmpDockerFile.Name(),
 		buildContext.BaseDirectory,
 		svcName,
@@ -130,7 +166,7 @@ func BuildMigrationImage(fs afero.Fs, dbName string, buildContext *runtime.Runti
 }
 
 // FIXME: This is essentially a copy of the project.BuildServiceImages function
-func BuildMigrationImages(fs afero.Fs, migrationBuildContexts map[string]*runtime.RuntimeBuildContext, useBuilder bool) (chan ServiceBuildUpdate, error) {
+func BuildMigrationImages(fs afero.Fs, builder docker.ImageBuilder, migrationBuildContexts map[string]*runtime.RuntimeBuildContext, useBuilder bool) (chan ServiceBuildUpdate, error) {
 	updatesChan := make(chan ServiceBuildUpdate)
 
 	maxConcurrentBuilds := make(chan struct{}, min(goruntime.NumCPU(), goruntime.GOMAXPROCS(0)))
@@ -150,7 +186,7 @@ func BuildMigrationImages(fs afero.Fs, migrationBuildContexts map[string]*runtim
 			svcName := migrationImageName(dbName)
 
 			// Start goroutine
-			if err := BuildMigrationImage(fs, dbName, buildContext, writer, useBuilder); err != nil {
+			if err := BuildMigrationImage(fs, builder, dbName, buildContext, writer, useBuilder); err != nil {
 				updatesChan <- ServiceBuildUpdate{
 					ServiceName: svcName,
 					Err:         err,
@@ -0,0 +1,35 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"github.com/samber/lo"
+)
+
+// resolvePlatforms returns the platforms to build a service for.
+// The requested platforms are narrowed to those the service supports, if it supports none of them its own platforms are used instead.
+func resolvePlatforms(supported []string, requested []string) []string {
+	if len(supported) == 0 {
+		return requested
+	}
+
+	if matching := lo.Intersect(requested, supported); len(matching) > 0 {
+		return matching
+	}
+
+	return supported
+}
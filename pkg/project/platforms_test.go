@@ -0,0 +1,44 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolvePlatforms(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		supported []string
+		requested []string
+		expected  []string
+	}{
+		{name: "defaults when nothing is requested", expected: nil},
+		{name: "requested platforms", requested: []string{"linux/arm64"}, expected: []string{"linux/arm64"}},
+		{name: "service platforms", supported: []string{"linux/amd64", "linux/arm64"}, expected: []string{"linux/amd64", "linux/arm64"}},
+		{name: "narrows to requested", supported: []string{"linux/amd64", "linux/arm64"}, requested: []string{"linux/arm64"}, expected: []string{"linux/arm64"}},
+		{name: "service platforms win when unsupported", supported: []string{"linux/amd64"}, requested: []string{"linux/arm64"}, expected: []string{"linux/amd64"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, resolvePlatforms(tt.supported, tt.requested)); diff != "" {
+				t.Errorf("unexpected platforms (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -39,11 +39,15 @@ import (
 	goruntime "runtime"
 
 	"github.com/nitrictech/cli/pkg/cloud"
+	"github.com/nitrictech/cli/pkg/cloud/batch"
 	"github.com/nitrictech/cli/pkg/collector"
+	"github.com/nitrictech/cli/pkg/docker"
+	"github.com/nitrictech/cli/pkg/paths"
 	"github.com/nitrictech/cli/pkg/preview"
 	"github.com/nitrictech/cli/pkg/project/localconfig"
 	"github.com/nitrictech/cli/pkg/project/runtime"
 	"github.com/nitrictech/nitric/core/pkg/logger"
+	batchpb "github.com/nitrictech/nitric/core/pkg/proto/batch/v1"
 )
 
 type Project struct {
@@ -54,6 +58,29 @@ type Project struct {
 
 	services []Service
 	batches  []Batch
+
+	// imageScope prefixes the project's image names
+	imageScope string
+
+	build BuildConfiguration
+
+	// runStates tracks the lifecycle of locally run services
+	runStates *RunStates
+}
+
+// RunStates returns the run states of the project's locally run services
+func (p *Project) RunStates() *RunStates {
+	return p.runStates
+}
+
+// ImageBuilder returns the image builder configured for the project
+func (p *Project) ImageBuilder() (docker.ImageBuilder, error) {
+	outputDir := p.build.Output
+	if outputDir == "" {
+		outputDir = filepath.Join(paths.NitricTmpDir(p.Directory), "images")
+	}
+
+	return docker.NewImageBuilder(p.build.Builder, outputDir)
 }
 
 func (p *Project) GetServices() []Service {
@@ -65,10 +92,12 @@ func (p *Project) GetBatchServices() []Batch {
 }
 
 // TODO: Reduce duplicate code
-// BuildBatches - Builds all the batches in the project
-func (p *Project) BuildBatches(fs afero.Fs, useBuilder bool) (chan ServiceBuildUpdate, error) {
+// BuildBatches - Builds all the batches in the project, for the requested platforms they support
+func (p *Project) BuildBatches(fs afero.Fs, builder docker.ImageBuilder, useBuilder bool, platforms []string) (chan ServiceBuildUpdate, error) {
 	updatesChan := make(chan ServiceBuildUpdate)
 
+	cache := loadBuildCache(fs, builder)
+
 	maxConcurrentBuilds := make(chan struct{}, min(goruntime.NumCPU(), goruntime.GOMAXPROCS(0)))
 
 	waitGroup := sync.WaitGroup{}
@@ -86,21 +115,9 @@ func (p *Project) BuildBatches(fs afero.Fs, useBuilder bool) (chan ServiceBuildU
 			// this will block once the buffer is full
 			maxConcurrentBuilds <- struct{}{}
 
-			// Start goroutine
-			if err := svc.BuildImage(fs, writer, useBuilder); err != nil {
-				updatesChan <- ServiceBuildUpdate{
-					ServiceName: svc.Name,
-					Err:         err,
-					Message:     err.Error(),
-					Status:      ServiceBuildStatus_Error,
-				}
-			} else {
-				updatesChan <- ServiceBuildUpdate{
-					ServiceName: svc.Name,
-					Message:     "Build Complete",
-					Status:      ServiceBuildStatus_Complete,
-				}
-			}
+			updatesChan <- cache.build(svc.Name, svc.image, svc.buildContext, resolvePlatforms(svc.platforms, platforms), func(fingerprint string) error {
+				return svc.BuildImage(fs, builder, writer, useBuilder, platforms, fingerprint)
+			})
 
 			// release our lock
 			<-maxConcurrentBuilds
@@ -122,10 +139,12 @@ func (p *Project) BuildBatches(fs afero.Fs, useBuilder bool) (chan ServiceBuildU
 	return updatesChan, nil
 }
 
-// BuildServices - Builds all the services in the project
-func (p *Project) BuildServices(fs afero.Fs, useBuilder bool) (chan ServiceBuildUpdate, error) {
+// BuildServices - Builds all the services in the project, for the requested platforms they support
+func (p *Project) BuildServices(fs afero.Fs, builder docker.ImageBuilder, useBuilder bool, platforms []string) (chan ServiceBuildUpdate, error) {
 	updatesChan := make(chan ServiceBuildUpdate)
 
+	cache := loadBuildCache(fs, builder)
+
 	maxConcurrentBuilds := make(chan struct{}, min(goruntime.NumCPU(), goruntime.GOMAXPROCS(0)))
 
 	waitGroup := sync.WaitGroup{}
@@ -140,21 +159,9 @@ func (p *Project) BuildServices(fs afero.Fs, useBuilder bool) (chan ServiceBuild
 			// this will block once the buffer is full
 			maxConcurrentBuilds <- struct{}{}
 
-			// Start goroutine
-			if err := svc.BuildImage(fs, writer, useBuilder); err != nil {
-				updatesChan <- ServiceBuildUpdate{
-					ServiceName: svc.Name,
-					Err:         err,
-					Message:     err.Error(),
-					Status:      ServiceBuildStatus_Error,
-				}
-			} else {
-				updatesChan <- ServiceBuildUpdate{
-					ServiceName: svc.Name,
-					Message:     "Build Complete",
-					Status:      ServiceBuildStatus_Complete,
-				}
-			}
+			updatesChan <- cache.build(svc.Name, svc.image, svc.buildContext, resolvePlatforms(svc.platforms, platforms), func(fingerprint string) error {
+				return svc.BuildImage(fs, builder, writer, useBuilder, platforms, fingerprint)
+			})
 
 			// release our lock
 			<-maxConcurrentBuilds
@@ -176,8 +183,8 @@ func (p *Project) BuildServices(fs afero.Fs, useBuilder bool) (chan ServiceBuild
 	return updatesChan, nil
 }
 
-func (p *Project) collectServiceRequirements(service Service) (*collector.ServiceRequirements, error) {
-	serviceRequirements := collector.NewServiceRequirements(service.Name, service.GetFilePath(), service.Type)
+func (p *Project) collectServiceRequirements(service Service, withCommand bool) (*collector.ServiceRequirements, error) {
+	serviceRequirements := collector.NewServiceRequirements(service.Name, service.GetFilePath(), service.Type, service.image, service.env)
 
 	// start a grpc service with this registered
 	grpcServer := grpc.NewServer()
@@ -241,7 +248,20 @@ func (p *Project) collectServiceRequirements(service Service) (*collector.Servic
 		return nil, fmt.Errorf("unable to split host and port for local Nitric collection server: %w", err)
 	}
 
-	err = service.RunContainer(stopChannel, updatesChannel, WithNitricPort(port), WithNitricEnvironment("build"))
+	// collection runs the service once, without its restart policy or health checks, so exits and failures end the collection
+	service = service.withoutSupervision()
+
+	if withCommand {
+		commandParts, cmdErr := service.startCommand()
+		if cmdErr != nil {
+			return nil, cmdErr
+		}
+
+		err = service.runCommand(stopChannel, updatesChannel, commandParts, lo.Assign(service.env, collectEnv(port)), nil)
+	} else {
+		err = service.runContainer(stopChannel, updatesChannel, newRunContainerOptions(WithNitricPort(port), WithNitricEnvironment("build"), WithEnvVars(service.env)), nil)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -253,8 +273,8 @@ func (p *Project) collectServiceRequirements(service Service) (*collector.Servic
 	return serviceRequirements, nil
 }
 
-func (p *Project) collectBatchRequirements(service Batch) (*collector.BatchRequirements, error) {
-	serviceRequirements := collector.NewBatchRequirements(service.Name, service.GetFilePath())
+func (p *Project) collectBatchRequirements(service Batch, withCommand bool) (*collector.BatchRequirements, error) {
+	serviceRequirements := collector.NewBatchRequirements(service.Name, service.GetFilePath(), service.image, service.env)
 
 	// start a grpc service with this registered
 	grpcServer := grpc.NewServer()
@@ -294,7 +314,12 @@ func (p *Project) collectBatchRequirements(service Batch) (*collector.BatchRequi
 		return nil, fmt.Errorf("unable to split host and port for local Nitric collection server: %w", err)
 	}
 
-	err = service.RunContainer(stopChannel, updatesChannel, WithNitricPort(port), WithNitricEnvironment("build"))
+	if withCommand {
+		err = service.Run(stopChannel, updatesChannel, lo.Assign(service.env, collectEnv(port)))
+	} else {
+		err = service.RunContainer(stopChannel, updatesChannel, WithNitricPort(port), WithNitricEnvironment("build"), WithEnvVars(service.env))
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -306,7 +331,28 @@ func (p *Project) collectBatchRequirements(service Batch) (*collector.BatchRequi
 	return serviceRequirements, nil
 }
 
+// collectEnv returns the environment for services run on the host to declare their requirements to the collection server
+func collectEnv(port string) map[string]string {
+	return map[string]string{
+		"PYTHONUNBUFFERED":    "TRUE", // ensure all print statements print immediately for python
+		"NITRIC_ENVIRONMENT":  "build",
+		"SERVICE_ADDRESS":     "localhost:" + port,
+		"NITRIC_SERVICE_PORT": port,
+		"NITRIC_SERVICE_HOST": "localhost",
+	}
+}
+
+// CollectServicesRequirements - Collects the requirements of all services by running their built images
 func (p *Project) CollectServicesRequirements() ([]*collector.ServiceRequirements, error) {
+	return p.collectServicesRequirements(false)
+}
+
+// CollectServicesRequirementsWithCommand - Collects the requirements of all services by running their start commands on the host, without building images
+func (p *Project) CollectServicesRequirementsWithCommand() ([]*collector.ServiceRequirements, error) {
+	return p.collectServicesRequirements(true)
+}
+
+func (p *Project) collectServicesRequirements(withCommand bool) ([]*collector.ServiceRequirements, error) {
 	allServiceRequirements := []*collector.ServiceRequirements{}
 	serviceErrors := []error{}
 
@@ -322,7 +368,7 @@ func (p *Project) CollectServicesRequirements() ([]*collector.ServiceRequirement
 		go func(s Service) {
 			defer wg.Done()
 
-			serviceRequirements, err := p.collectServiceRequirements(s)
+			serviceRequirements, err := p.collectServiceRequirements(s, withCommand)
 			if err != nil {
 				errorLock.Lock()
 				defer errorLock.Unlock()
@@ -348,7 +394,17 @@ func (p *Project) CollectServicesRequirements() ([]*collector.ServiceRequirement
 	return allServiceRequirements, nil
 }
 
+// CollectBatchRequirements - Collects the requirements of all batches by running their built images
 func (p *Project) CollectBatchRequirements() ([]*collector.BatchRequirements, error) {
+	return p.collectBatchesRequirements(false)
+}
+
+// CollectBatchRequirementsWithCommand - Collects the requirements of all batches by running their start commands on the host, without building images
+func (p *Project) CollectBatchRequirementsWithCommand() ([]*collector.BatchRequirements, error) {
+	return p.collectBatchesRequirements(true)
+}
+
+func (p *Project) collectBatchesRequirements(withCommand bool) ([]*collector.BatchRequirements, error) {
 	allBatchRequirements := []*collector.BatchRequirements{}
 	batchErrors := []error{}
 
@@ -364,7 +420,7 @@ func (p *Project) CollectBatchRequirements() ([]*collector.BatchRequirements, er
 		go func(s Batch) {
 			defer wg.Done()
 
-			batchRequirements, err := p.collectBatchRequirements(s)
+			batchRequirements, err := p.collectBatchRequirements(s, withCommand)
 			if err != nil {
 				errorLock.Lock()
 				defer errorLock.Unlock()
@@ -422,7 +478,7 @@ func (p *Project) RunServicesWithCommand(localCloud *cloud.LocalCloud, stop <-ch
 				"SERVICE_ADDRESS":    "localhost:" + strconv.Itoa(port),
 			}
 
-			for key, value := range env {
+			for key, value := range lo.Assign(env, svc.env) {
 				envVariables[key] = value
 			}
 
@@ -462,7 +518,7 @@ func (p *Project) RunBatchesWithCommand(localCloud *cloud.LocalCloud, stop <-cha
 				"SERVICE_ADDRESS":    "localhost:" + strconv.Itoa(port),
 			}
 
-			for key, value := range env {
+			for key, value := range lo.Assign(env, svc.env) {
 				envVariables[key] = value
 			}
 
@@ -483,6 +539,10 @@ func (p *Project) RunBatchesWithCommand(localCloud *cloud.LocalCloud, stop <-cha
 func (p *Project) RunBatches(localCloud *cloud.LocalCloud, stop <-chan bool, updates chan<- ServiceRunUpdate, env map[string]string) error {
 	stopChannels := lo.FanOut[bool](len(p.batches), 1, stop)
 
+	if p.LocalConfig.Batch.Isolated {
+		localCloud.Batch.SetIsolatedJobRunner(p.isolatedJobRunner(localCloud, updates, env))
+	}
+
 	group, _ := errgroup.WithContext(context.TODO())
 
 	for i, service := range p.batches {
@@ -495,13 +555,35 @@ func (p *Project) RunBatches(localCloud *cloud.LocalCloud, stop <-chan bool, upd
 				return err
 			}
 
-			return svc.RunContainer(stopChannels[idx], updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(env))
+			return svc.RunContainer(stopChannels[idx], updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(lo.Assign(env, svc.env)))
 		})
 	}
 
 	return group.Wait()
 }
 
+// isolatedJobRunner runs each job in a fresh container, the long running batch containers are kept to register the jobs they handle
+func (p *Project) isolatedJobRunner(localCloud *cloud.LocalCloud, updates chan<- ServiceRunUpdate, env map[string]string) batch.IsolatedJobRunner {
+	return func(serviceName string, req *batchpb.JobRequest, requirements *batchpb.JobResourceRequirements) (*batch.IsolatedJobResult, error) {
+		svc, ok := lo.Find(p.batches, func(b Batch) bool {
+			return b.GetFilePath() == serviceName
+		})
+		if !ok {
+			return nil, fmt.Errorf("unable to find batch service %s", serviceName)
+		}
+
+		jobManager := batch.NewIsolatedJobManager()
+
+		port, stopServer, err := localCloud.AddIsolatedBatch(serviceName, jobManager)
+		if err != nil {
+			return nil, err
+		}
+		defer stopServer()
+
+		return svc.RunJobContainer(jobManager, req, requirements, updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(lo.Assign(env, svc.env)))
+	}
+}
+
 // RunServices - Runs all the services as containers
 // use the stop channel to stop all running services
 func (p *Project) RunServices(localCloud *cloud.LocalCloud, stop <-chan bool, updates chan<- ServiceRunUpdate, env map[string]string) error {
@@ -519,7 +601,7 @@ func (p *Project) RunServices(localCloud *cloud.LocalCloud, stop <-chan bool, up
 				return err
 			}
 
-			return svc.RunContainer(stopChannels[idx], updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(env))
+			return svc.RunContainer(stopChannels[idx], updates, WithNitricPort(strconv.Itoa(port)), WithEnvVars(lo.Assign(env, svc.env)))
 		})
 	}
 
@@ -545,9 +627,12 @@ func (pc *ProjectConfiguration) pathToNormalizedServiceName(servicePath string)
 func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *localconfig.LocalConfiguration, fs afero.Fs) (*Project, error) {
 	services := []Service{}
 	batches := []Batch{}
+	runStates := NewRunStates()
 
 	matches := map[string]string{}
 
+	scope := projectImageScope(projectConfig.Name, projectConfig.Directory)
+
 	baseServices := []BaseService{}
 	for _, serviceSpec := range projectConfig.Services {
 		baseServices = append(baseServices, serviceSpec)
@@ -600,6 +685,21 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 				if err != nil {
 					return nil, fmt.Errorf("unable to create build context for custom service file %s: %w", f, err)
 				}
+
+				if len(customRuntime.Include) > 0 || len(customRuntime.Packages) > 0 {
+					includes, err := contextIncludes(fs, buildContext.BaseDirectory, f, customRuntime)
+					if err != nil {
+						return nil, fmt.Errorf("unable to create build context for custom service file %s: %w", f, err)
+					}
+
+					buildContext.IncludeOnly(includes)
+				}
+
+				if len(customRuntime.Packages) > 0 {
+					buildContext.BuildArguments["PACKAGES"] = strings.Join(lo.Map(customRuntime.Packages, func(pkg string, _ int) string {
+						return filepath.ToSlash(filepath.Clean(pkg))
+					}), " ")
+				}
 			} else {
 				buildContext, err = runtime.NewBuildContext(
 					relativeServiceEntrypointPath,
@@ -614,6 +714,9 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 				}
 			}
 
+			// build args from nitric.yaml override the runtime's args
+			buildContext.BuildArguments = lo.Assign(buildContext.BuildArguments, baseService.GetBuildArgs())
+
 			if matches[f] != "" {
 				return nil, fmt.Errorf("service file %s matched by multiple patterns: %s and %s, services must only be matched by a single pattern", f, matches[f], baseService.GetMatch())
 			}
@@ -626,6 +729,19 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 			}
 
 			if svc, ok := baseService.(ServiceConfiguration); ok {
+				serviceEnv, err := loadServiceEnv(fs, projectConfig.Directory, svc.EnvFiles, svc.Env)
+				if err != nil {
+					return nil, fmt.Errorf("unable to load env for service %s: %w", f, err)
+				}
+
+				if err := svc.Restart.validate(); err != nil {
+					return nil, fmt.Errorf("invalid restart configuration for service %s: %w", f, err)
+				}
+
+				if err := svc.HealthCheck.validate(); err != nil {
+					return nil, fmt.Errorf("invalid health check for service %s: %w", f, err)
+				}
+
 				newService := Service{
 					Name:         serviceName,
 					filepath:     relativeFilePath,
@@ -633,6 +749,14 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 					buildContext: *buildContext,
 					Type:         svc.Type,
 					startCmd:     svc.Start,
+					platforms:    svc.Platforms,
+					imageScope:   scope,
+					image:        imageName(scope, serviceName),
+					env:          serviceEnv,
+					resources:    svc.Resources,
+					restart:      svc.Restart,
+					healthCheck:  svc.HealthCheck,
+					runStates:    runStates,
 				}
 
 				if svc.Type == "" {
@@ -641,12 +765,22 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 
 				services = append(services, newService)
 			} else if batch, ok := baseService.(BatchConfiguration); ok {
+				batchEnv, err := loadServiceEnv(fs, projectConfig.Directory, batch.EnvFiles, batch.Env)
+				if err != nil {
+					return nil, fmt.Errorf("unable to load env for batch service %s: %w", f, err)
+				}
+
 				newBatch := Batch{
 					Name:         serviceName,
 					basedir:      batch.Basedir,
 					filepath:     relativeFilePath,
 					buildContext: *buildContext,
 					runCmd:       batch.Start,
+					platforms:    batch.Platforms,
+					imageScope:   scope,
+					image:        imageName(scope, serviceName),
+					env:          batchEnv,
+					resources:    batch.Resources,
 				}
 
 				batches = append(batches, newBatch)
@@ -666,6 +800,9 @@ func fromProjectConfiguration(projectConfig *ProjectConfiguration, localConfig *
 		LocalConfig: *localConfig,
 		services:    services,
 		batches:     batches,
+		imageScope:  scope,
+		build:       projectConfig.Build,
+		runStates:   runStates,
 	}
 
 	if len(project.batches) > 0 && !slices.Contains(project.Preview, preview.Feature_BatchServices) {
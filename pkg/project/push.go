@@ -0,0 +1,248 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/docker"
+)
+
+// DefaultImageTagTemplate tags pushed images with the git commit, or the build fingerprint outside of a git repository
+const DefaultImageTagTemplate = "{{or .GitSha .Digest}}"
+
+// ImageTagData is the data available to image tag templates
+type ImageTagData struct {
+	// Service is the normalized name of the service
+	Service string
+	// GitSha is the short hash of the current git commit
+	GitSha string
+	// Version is the git description of the current commit, e.g. v1.2.0 or v1.2.0-3-gabc1234
+	Version string
+	// Digest is the short build context fingerprint of the image
+	Digest string
+}
+
+var validImageTag = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// ImageTagTemplates renders the tags for pushed images
+type ImageTagTemplates []*template.Template
+
+// ParseImageTagTemplates parses tag templates such as "{{.GitSha}}" or "{{.Version}}-{{.Service}}"
+func ParseImageTagTemplates(tags []string) (ImageTagTemplates, error) {
+	templates := ImageTagTemplates{}
+
+	for _, tag := range tags {
+		tmpl, err := template.New(tag).Option("missingkey=error").Parse(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image tag template %s: %w", tag, err)
+		}
+
+		templates = append(templates, tmpl)
+	}
+
+	return templates, nil
+}
+
+// Render returns the tags for an image, ignoring duplicates
+func (t ImageTagTemplates) Render(data ImageTagData) ([]string, error) {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, tmpl := range t {
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("unable to render image tag %s: %w", tmpl.Name(), err)
+		}
+
+		tag := buf.String()
+		if !validImageTag.MatchString(tag) {
+			return nil, fmt.Errorf("image tag template %s rendered invalid tag %q for service %s", tmpl.Name(), tag, data.Service)
+		}
+
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// gitOutput runs a git command in dir, returning an empty string if it fails, e.g. when dir isn't a git repository
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// PushedImage is an image pushed to a registry for a service
+type PushedImage struct {
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags"`
+	Digest     string   `json:"digest"`
+	// Reference pins the image to its digest, e.g. registry.example.com/service@sha256:...
+	Reference string `json:"reference"`
+}
+
+// PushManifest records the images pushed for each service and batch, so the same images can be deployed to multiple stacks
+type PushManifest struct {
+	Registry string                 `json:"registry"`
+	Services map[string]PushedImage `json:"services"`
+	Batches  map[string]PushedImage `json:"batches,omitempty"`
+}
+
+// remoteRepository returns the repository for a service in a registry, it doesn't include the image scope so it's the same on every machine
+func remoteRepository(registry string, serviceName string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(registry, "/"), notImageNameChars.ReplaceAllString(strings.ToLower(serviceName), "-"))
+}
+
+// PushServices pushes the latest images of the project's services and batches to a registry
+func (p *Project) PushServices(registry string, tagTemplates ImageTagTemplates, pushed func(serviceName string, image PushedImage)) (*PushManifest, error) {
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	imagePusher := &imagePusher{
+		client:       dockerClient,
+		registry:     registry,
+		tagTemplates: tagTemplates,
+		gitSha:       gitOutput(p.Directory, "rev-parse", "--short", "HEAD"),
+		version:      gitOutput(p.Directory, "describe", "--tags", "--always", "--dirty"),
+	}
+
+	manifest := &PushManifest{
+		Registry: registry,
+		Services: map[string]PushedImage{},
+		Batches:  map[string]PushedImage{},
+	}
+
+	for _, svc := range p.services {
+		pushedImage, err := imagePusher.push(svc.Name, svc.image)
+		if err != nil {
+			return manifest, err
+		}
+
+		manifest.Services[svc.Name] = pushedImage
+
+		if pushed != nil {
+			pushed(svc.Name, pushedImage)
+		}
+	}
+
+	for _, batch := range p.batches {
+		pushedImage, err := imagePusher.push(batch.Name, batch.image)
+		if err != nil {
+			return manifest, err
+		}
+
+		manifest.Batches[batch.Name] = pushedImage
+
+		if pushed != nil {
+			pushed(batch.Name, pushedImage)
+		}
+	}
+
+	return manifest, nil
+}
+
+type imagePusher struct {
+	client       *docker.Docker
+	registry     string
+	tagTemplates ImageTagTemplates
+	gitSha       string
+	version      string
+}
+
+// push tags and pushes the latest local image of a service or batch
+func (i *imagePusher) push(name string, image string) (PushedImage, error) {
+	localImage := image + ":latest"
+
+	inspect, _, err := i.client.ImageInspectWithRaw(context.Background(), localImage)
+	if err != nil {
+		return PushedImage{}, fmt.Errorf("unable to find image for %s, it may need to be built: %w", name, err)
+	}
+
+	fingerprint := ""
+	if inspect.Config != nil {
+		fingerprint = inspect.Config.Labels[docker.LabelDigest]
+	}
+
+	tags, err := i.tagTemplates.Render(ImageTagData{
+		Service: notImageNameChars.ReplaceAllString(strings.ToLower(name), "-"),
+		GitSha:  i.gitSha,
+		Version: i.version,
+		Digest:  fingerprint[:min(12, len(fingerprint))],
+	})
+	if err != nil {
+		return PushedImage{}, err
+	}
+
+	if len(tags) == 0 {
+		return PushedImage{}, fmt.Errorf("no tags to push %s with", name)
+	}
+
+	pushedImage := PushedImage{
+		Repository: remoteRepository(i.registry, name),
+		Tags:       tags,
+	}
+
+	for _, tag := range tags {
+		digest, err := i.client.Push(localImage, fmt.Sprintf("%s:%s", pushedImage.Repository, tag))
+		if err != nil {
+			return PushedImage{}, err
+		}
+
+		pushedImage.Digest = digest
+	}
+
+	pushedImage.Reference = fmt.Sprintf("%s@%s", pushedImage.Repository, pushedImage.Digest)
+
+	return pushedImage, nil
+}
+
+// WritePushManifest writes the manifest as JSON to the given path
+func WritePushManifest(fs afero.Fs, manifest *PushManifest, path string) error {
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, contents, 0o644)
+}
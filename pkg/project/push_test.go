@@ -0,0 +1,76 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImageTagTemplatesRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		data    ImageTagData
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default uses the git sha",
+			tags: []string{DefaultImageTagTemplate},
+			data: ImageTagData{Service: "api", GitSha: "abc1234", Digest: "0123456789ab"},
+			want: []string{"abc1234"},
+		},
+		{
+			name: "default falls back to the digest",
+			tags: []string{DefaultImageTagTemplate},
+			data: ImageTagData{Service: "api", Digest: "0123456789ab"},
+			want: []string{"0123456789ab"},
+		},
+		{
+			name: "multiple tags without duplicates",
+			tags: []string{"{{.Version}}", "{{.Service}}-{{.GitSha}}", "v1.0.0"},
+			data: ImageTagData{Service: "api", GitSha: "abc1234", Version: "v1.0.0"},
+			want: []string{"v1.0.0", "api-abc1234"},
+		},
+		{
+			name:    "invalid tag",
+			tags:    []string{"{{.Version}}"},
+			data:    ImageTagData{Service: "api"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates, err := ParseImageTagTemplates(tt.tags)
+			if err != nil {
+				t.Fatalf("ParseImageTagTemplates() error = %v", err)
+			}
+
+			got, err := templates.Render(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Errorf("Render() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ func TestGenerate(t *testing.T) {
 	pythonFile, _ := os.ReadFile("python.dockerfile")
 	jsFile, _ := os.ReadFile("javascript.dockerfile")
 	jvmFile, _ := os.ReadFile("jvm.dockerfile")
+	goFile, _ := os.ReadFile("golang.dockerfile")
 
 	fs := afero.NewOsFs()
 
@@ -57,6 +58,11 @@ func TestGenerate(t *testing.T) {
 			handler:     "outout/fat.jar",
 			wantFwriter: string(jvmFile),
 		},
+		{
+			name:        "go",
+			handler:     "services/hello/main.go",
+			wantFwriter: string(goFile),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -33,6 +33,17 @@ type RuntimeBuildContext struct {
 	IgnoreFileContents string
 }
 
+// IncludeOnly limits the build context to the given paths relative to its base directory, the other ignore rules still apply within them
+func (c *RuntimeBuildContext) IncludeOnly(paths []string) {
+	lines := []string{"*"}
+
+	for _, path := range paths {
+		lines = append(lines, "!"+filepath.ToSlash(filepath.Clean(path)))
+	}
+
+	c.IgnoreFileContents = strings.Join(append(lines, c.IgnoreFileContents), "\n")
+}
+
 type RuntimeExt = string
 
 const (
@@ -41,6 +52,7 @@ const (
 	RuntimePython     RuntimeExt = "py"
 	RuntimeCsharp     RuntimeExt = "cs"
 	RuntimeJvm        RuntimeExt = "jar"
+	RuntimeGo         RuntimeExt = "go"
 
 	RuntimeUnknown RuntimeExt = ""
 )
@@ -196,6 +208,22 @@ func dartBuildContext(entrypointFilePath string, baseDir string, additionalIgnor
 	}, nil
 }
 
+//go:embed golang.dockerfile
+var golangDockerfile string
+var golangIgnores = append([]string{}, commonIgnore...)
+
+// golangBuildContext builds the package containing the entrypoint, so services can be split across multiple files
+func golangBuildContext(entrypointFilePath string, baseDir string, additionalIgnores []string) (*RuntimeBuildContext, error) {
+	return &RuntimeBuildContext{
+		DockerfileContents: golangDockerfile,
+		BaseDirectory:      baseDir, // use the nitric project directory, which should contain go.mod
+		BuildArguments: map[string]string{
+			"HANDLER": filepath.ToSlash(entrypointFilePath),
+		},
+		IgnoreFileContents: strings.Join(append(additionalIgnores, golangIgnores...), "\n"),
+	}, nil
+}
+
 const customDockerfileDocLink = "https://nitric.io/docs/reference/custom-containers#create-a-dockerfile-template"
 
 // NewBuildContext - Creates a new runtime build context.
@@ -244,6 +272,8 @@ func NewBuildContext(entrypointFilePath string, dockerfilePath string, baseDirec
 		return typescriptBuildContext(entrypointFilePath, baseDirectory, additionalIgnores)
 	case ".dart":
 		return dartBuildContext(entrypointFilePath, baseDirectory, additionalIgnores)
+	case ".go":
+		return golangBuildContext(entrypointFilePath, baseDirectory, additionalIgnores)
 	default:
 		return nil, fmt.Errorf("nitric does not support files with extension %s by default", ext)
 	}
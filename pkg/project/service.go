@@ -53,6 +53,22 @@ type Service struct {
 	buildContext runtime.RuntimeBuildContext
 
 	startCmd string
+
+	// platforms the service can be built for, empty if it supports any
+	platforms []string
+
+	// image is the repository the service is built to, scoped to the project
+	imageScope string
+	image      string
+
+	// env is the service's own env, which overrides the project's env
+	env       map[string]string
+	resources ResourcesConfiguration
+
+	restart     RestartConfiguration
+	healthCheck HealthCheckConfiguration
+	// runStates records the service's lifecycle when it's run locally
+	runStates *RunStates
 }
 
 const tempBuildDir = "./.nitric/build"
@@ -74,6 +90,7 @@ const (
 	ServiceBuildStatus_Complete   ServiceBuildStatus = "Complete"
 	ServiceBuildStatus_Error      ServiceBuildStatus = "Error"
 	ServiceBuildStatus_Skipped    ServiceBuildStatus = "Skipped"
+	ServiceBuildStatus_Cached     ServiceBuildStatus = "Cached"
 )
 
 type ServiceBuildUpdate struct {
@@ -89,14 +106,25 @@ const (
 	ServiceRunStatus_Running ServiceRunStatus = "Running"
 	ServiceRunStatus_Done    ServiceRunStatus = "Done"
 	ServiceRunStatus_Error   ServiceRunStatus = "Error"
+
+	ServiceRunStatus_Restarting ServiceRunStatus = "Restarting"
+	ServiceRunStatus_Unhealthy  ServiceRunStatus = "Unhealthy"
+	ServiceRunStatus_CrashLoop  ServiceRunStatus = "CrashLoop"
 )
 
+// Failed returns true for statuses reporting an error or an unhealthy service
+func (s ServiceRunStatus) Failed() bool {
+	return s == ServiceRunStatus_Error || s == ServiceRunStatus_Unhealthy || s == ServiceRunStatus_CrashLoop
+}
+
 type ServiceRunUpdate struct {
 	ServiceName string
 	Label       string
 	Message     string
 	Status      ServiceRunStatus
 	Err         error
+	// Restarts is the number of times the service has been restarted
+	Restarts int
 }
 
 type ServiceRunUpdateWriter struct {
@@ -141,13 +169,8 @@ func NewBuildUpdateWriter(serviceName string, buildUpdateChan chan ServiceBuildU
 	}
 }
 
-func (s *Service) BuildImage(fs afero.Fs, logs io.Writer, useBuilder bool) error {
-	dockerClient, err := docker.New()
-	if err != nil {
-		return err
-	}
-
-	err = fs.MkdirAll(tempBuildDir, os.ModePerm)
+func (s *Service) BuildImage(fs afero.Fs, builder docker.ImageBuilder, logs io.Writer, useBuilder bool, platforms []string, fingerprint string) error {
+	err := fs.MkdirAll(tempBuildDir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("unable to create temporary build directory %s: %w", tempBuildDir, err)
 	}
@@ -170,15 +193,20 @@ func (s *Service) BuildImage(fs afero.Fs, logs io.Writer, useBuilder bool) error
 		}
 	}()
 
-	// build the docker image
-	err = dockerClient.Build(
-		tmpDockerFile.Name(),
-		s.buildContext.BaseDirectory,
-		s.Name,
+	buildOptions := append([]docker.DockerBuildOption{
 		docker.WithBuildArgs(s.buildContext.BuildArguments),
 		docker.WithExcludes(strings.Split(s.buildContext.IgnoreFileContents, "\n")),
 		docker.WithLogger(logs),
 		docker.WithBuilder(useBuilder),
+		docker.WithPlatforms(resolvePlatforms(s.platforms, platforms)),
+	}, imageBuildOptions(s.imageScope, s.Name, s.image, fingerprint)...)
+
+	// build the docker image
+	err = builder.Build(
+		tmpDockerFile.Name(),
+		s.buildContext.BaseDirectory,
+		s.image,
+		buildOptions...,
 	)
 	if err != nil {
 		return err
@@ -203,6 +231,16 @@ var defaultRunContainerOptions = runContainerOptions{
 	envVars:           map[string]string{},
 }
 
+func newRunContainerOptions(opts ...RunContainerOption) *runContainerOptions {
+	runtimeOptions := lo.ToPtr(defaultRunContainerOptions)
+
+	for _, opt := range opts {
+		opt(runtimeOptions)
+	}
+
+	return runtimeOptions
+}
+
 func WithNitricHost(host string) RunContainerOption {
 	return func(o *runContainerOptions) {
 		o.nitricHost = host
@@ -234,9 +272,22 @@ func (wf writerFunc) Write(p []byte) (n int, err error) {
 }
 
 // Run - runs the service using the provided command, typically not in a container.
+// The service is restarted according to its restart policy until stop is received
 func (s *Service) Run(stop <-chan bool, updates chan<- ServiceRunUpdate, env map[string]string) error {
+	commandParts, err := s.startCommand()
+	if err != nil {
+		return err
+	}
+
+	return supervise(s.Name, s.GetFilePath(), s.restart, s.runStates, stop, updates, func(stop <-chan bool, started func()) error {
+		return s.runCommand(stop, updates, commandParts, env, started)
+	})
+}
+
+// startCommand returns the service's start command with its path substituted
+func (s *Service) startCommand() ([]string, error) {
 	if s.startCmd == "" {
-		return fmt.Errorf("no start command provided for service %s", s.filepath)
+		return nil, fmt.Errorf("no start command provided for service %s", s.filepath)
 	}
 
 	// this could be improve with real env var substitution.
@@ -247,7 +298,12 @@ func (s *Service) Run(stop <-chan bool, updates chan<- ServiceRunUpdate, env map
 		logger.Warnf("Start cmd for service %s does not contain $SERVICE_PATH, check the service start configuration in nitric.yaml", s.filepath)
 	}
 
-	commandParts := strings.Split(startCmd, " ")
+	return strings.Split(startCmd, " "), nil
+}
+
+// runCommand runs the service's start command once, blocking until it exits, is stopped or is stopped for being unhealthy.
+// started is called once the command has started, it may be nil
+func (s *Service) runCommand(stop <-chan bool, updates chan<- ServiceRunUpdate, commandParts []string, env map[string]string, started func()) error {
 	cmd := exec.Command(
 		commandParts[0],
 		commandParts[1:]...,
@@ -274,62 +330,133 @@ func (s *Service) Run(stop <-chan bool, updates chan<- ServiceRunUpdate, env map
 		status:      ServiceRunStatus_Error,
 	}
 
-	errChan := make(chan error)
+	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("error starting service %s: %w", s.Name, err)
+	}
+
+	if started != nil {
+		started()
+	}
+
+	updates <- ServiceRunUpdate{
+		ServiceName: s.Name,
+		Label:       "nitric",
+		Status:      ServiceRunStatus_Running,
+		Message:     fmt.Sprintf("started service %s", s.filepath),
+	}
+
+	exited := make(chan error, 1)
 
 	go func() {
-		err := cmd.Start()
+		exited <- cmd.Wait()
+	}()
+
+	stopProcess := func() {
+		err := cmd.Process.Signal(syscall.SIGTERM)
 		if err != nil {
-			errChan <- fmt.Errorf("error starting service %s: %w", s.Name, err)
-		} else {
-			updates <- ServiceRunUpdate{
-				ServiceName: s.Name,
-				Label:       "nitric",
-				Status:      ServiceRunStatus_Running,
-				Message:     fmt.Sprintf("started service %s", s.filepath),
-			}
+			_ = cmd.Process.Kill()
 		}
 
-		err = cmd.Wait()
-		if err != nil {
-			// provide runtime errors as a run update rather than as a fatal error
-			updates <- ServiceRunUpdate{
-				ServiceName: s.Name,
-				Label:       "nitric",
-				Status:      ServiceRunStatus_Error,
-				Err:         err,
-			}
+		<-exited
+	}
+
+	var probe healthProbe
+
+	switch {
+	case len(s.healthCheck.Command) > 0:
+		probe = hostCommandProbe(s.basedir, cmd.Env, s.healthCheck.Command)
+	case s.healthCheck.Path != "" && s.healthCheck.Port > 0:
+		probe = httpProbe(s.healthCheck.Port, s.healthCheck.Path)
+	case s.healthCheck.Path != "":
+		updates <- ServiceRunUpdate{
+			ServiceName: s.Name,
+			Label:       "nitric",
+			Status:      ServiceRunStatus_Running,
+			Message:     fmt.Sprintf("skipping health check for service %s, a port is required for http health checks of start commands", s.filepath),
 		}
+	}
 
-		errChan <- nil
-	}()
+	done := make(chan struct{})
+	defer close(done)
 
-	go func(cmd *exec.Cmd) {
-		<-stop
+	health := s.healthCheck.monitor(probe, done)
 
-		err := cmd.Process.Signal(syscall.SIGTERM)
-		if err != nil {
-			_ = cmd.Process.Kill()
+	for {
+		select {
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("service %s exited: %w", s.Name, err)
+			}
+
+			return nil
+		case <-stop:
+			stopProcess()
+
+			return nil
+		case err := <-health:
+			if s.reportHealth(updates, err) {
+				stopProcess()
+
+				return fmt.Errorf("service %s is unhealthy: %w", s.Name, err)
+			}
 		}
-	}(cmd)
+	}
+}
+
+// reportHealth reports a change in the service's health, returning true if the unhealthy service should be restarted
+func (s *Service) reportHealth(updates chan<- ServiceRunUpdate, err error) bool {
+	if err == nil {
+		updates <- ServiceRunUpdate{
+			ServiceName: s.Name,
+			Label:       "nitric",
+			Status:      ServiceRunStatus_Running,
+			Message:     fmt.Sprintf("service %s is healthy", s.GetFilePath()),
+		}
+
+		s.runStates.setStatus(s.GetFilePath(), ServiceRunStatus_Running, nil)
+
+		return false
+	}
 
-	err := <-errChan
 	updates <- ServiceRunUpdate{
 		ServiceName: s.Name,
-		Status:      ServiceRunStatus_Error,
+		Label:       "nitric",
+		Status:      ServiceRunStatus_Unhealthy,
+		Message:     fmt.Sprintf("service %s is unhealthy: %s", s.GetFilePath(), err.Error()),
 		Err:         err,
 	}
 
-	return err
+	s.runStates.setStatus(s.GetFilePath(), ServiceRunStatus_Unhealthy, err)
+
+	// unhealthy services are treated as failed when they can be restarted
+	return s.restart.restarts(err)
+}
+
+// withoutSupervision returns a copy of the service that's run once, without restarts, health checks or run state updates,
+// as it's run while collecting its requirements
+func (s *Service) withoutSupervision() Service {
+	svc := *s
+	svc.restart = RestartConfiguration{}
+	svc.healthCheck = HealthCheckConfiguration{}
+	svc.runStates = nil
+
+	return svc
 }
 
 // RunContainer - Runs a container for the service, blocking until the container exits
+// The container is restarted according to the service's restart policy until stop is received
 func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate, opts ...RunContainerOption) error {
-	runtimeOptions := lo.ToPtr(defaultRunContainerOptions)
+	runtimeOptions := newRunContainerOptions(opts...)
 
-	for _, opt := range opts {
-		opt(runtimeOptions)
-	}
+	return supervise(s.Name, s.GetFilePath(), s.restart, s.runStates, stop, updates, func(stop <-chan bool, started func()) error {
+		return s.runContainer(stop, updates, runtimeOptions, started)
+	})
+}
 
+// runContainer runs a container for the service once, blocking until it exits, is stopped or is stopped for being unhealthy.
+// started is called once the container has started, it may be nil
+func (s *Service) runContainer(stop <-chan bool, updates chan<- ServiceRunUpdate, runtimeOptions *runContainerOptions, started func()) error {
 	dockerClient, err := docker.New()
 	if err != nil {
 		return err
@@ -357,6 +484,8 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		hostConfig.ExtraHosts = []string{"host.docker.internal:" + dockerHost.String()}
 	}
 
+	applyResourceLimits(hostConfig, s.resources)
+
 	randomPort, _ := netx.TakePort(1)
 	hostProxyPort := fmt.Sprint(randomPort[0])
 	env := []string{
@@ -397,13 +526,29 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 	}
 
 	containerConfig := &container.Config{
-		Image: s.Name, // Select an image to use based on the handler
+		Image: s.image, // Select an image to use based on the handler
 		Env:   env,
 		ExposedPorts: nat.PortSet{
 			nat.Port(hostProxyPort): struct{}{},
 		},
 	}
 
+	probePort := randomPort[0]
+
+	// publish the health check port on a free host port so it can be probed from the host, without clashing with other services using the same port
+	if s.healthCheck.Path != "" && s.healthCheck.Port > 0 {
+		healthHostPort, err := netx.TakePort(1)
+		if err != nil {
+			return fmt.Errorf("unable to find a port for the health check of service %s: %w", s.Name, err)
+		}
+
+		probePort = healthHostPort[0]
+		healthPort := nat.Port(fmt.Sprint(s.healthCheck.Port))
+
+		hostConfig.PortBindings[healthPort] = []nat.PortBinding{{HostPort: fmt.Sprint(probePort)}}
+		containerConfig.ExposedPorts[healthPort] = struct{}{}
+	}
+
 	// Create the container
 	containerId, err := dockerClient.ContainerCreate(
 		containerConfig,
@@ -412,15 +557,7 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		s.Name,
 	)
 	if err != nil {
-		updates <- ServiceRunUpdate{
-			ServiceName: s.Name,
-			Label:       s.GetFilePath(),
-			Status:      ServiceRunStatus_Error,
-			Err:         err,
-		}
-
-		// Failing to create the container is a fatal error so we will return here
-		return err
+		return fmt.Errorf("error creating container for service %s: %w", s.Name, err)
 	}
 
 	// defer removing container so logs can be retrieved, used instead of AutoRemove
@@ -438,15 +575,11 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 
 	err = dockerClient.ContainerStart(context.TODO(), containerId, container.StartOptions{})
 	if err != nil {
-		updates <- ServiceRunUpdate{
-			ServiceName: s.Name,
-			Label:       s.GetFilePath(),
-			Status:      ServiceRunStatus_Error,
-			Err:         err,
-		}
+		return fmt.Errorf("error starting container for service %s: %w", s.Name, err)
+	}
 
-		// Failing to create the container is a fatal error so we will return here
-		return err
+	if started != nil {
+		started()
 	}
 
 	updates <- ServiceRunUpdate{
@@ -492,18 +625,25 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 		}
 	}()
 
+	var probe healthProbe
+
+	switch {
+	case len(s.healthCheck.Command) > 0:
+		probe = containerCommandProbe(dockerClient, containerId, s.healthCheck.Command)
+	case s.healthCheck.Path != "":
+		probe = httpProbe(probePort, s.healthCheck.Path)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	health := s.healthCheck.monitor(probe, done)
+
 	okChan, errChan := dockerClient.ContainerWait(context.TODO(), containerId, container.WaitConditionNotRunning)
 
 	for {
 		select {
 		case err := <-errChan:
-			updates <- ServiceRunUpdate{
-				ServiceName: s.Name,
-				Label:       s.GetFilePath(),
-				Err:         err,
-				Status:      ServiceRunStatus_Error,
-			}
-
 			return err
 		case okBody := <-okChan:
 			if okBody.StatusCode != 0 {
@@ -520,26 +660,18 @@ func (s *Service) RunContainer(stop <-chan bool, updates chan<- ServiceRunUpdate
 					return fmt.Errorf("error reading logs for service %s: %w", s.Name, err)
 				}
 
-				err = fmt.Errorf("service %s exited with non 0 status\n %s", s.Name, logs.String())
+				return fmt.Errorf("service %s exited with non 0 status\n %s", s.Name, logs.String())
+			}
 
-				updates <- ServiceRunUpdate{
-					ServiceName: s.Name,
-					Label:       s.filepath,
-					Err:         err,
-					Status:      ServiceRunStatus_Error,
+			return nil
+		case err := <-health:
+			if s.reportHealth(updates, err) {
+				if stopErr := dockerClient.ContainerStop(context.Background(), containerId, container.StopOptions{}); stopErr != nil {
+					return stopErr
 				}
 
-				return err
-			} else {
-				updates <- ServiceRunUpdate{
-					Label:       s.GetFilePath(),
-					ServiceName: s.Name,
-					Message:     "Service successfully exited",
-					Status:      ServiceRunStatus_Done,
-				}
+				return fmt.Errorf("service %s is unhealthy: %w", s.Name, err)
 			}
-
-			return nil
 		case <-stop:
 			if err := dockerClient.ContainerStop(context.Background(), containerId, container.StopOptions{}); err != nil {
 				updates <- ServiceRunUpdate{
@@ -124,6 +124,32 @@ func ConfigFromName[T any](fs afero.Fs, stackName string) (*StackConfig[T], erro
 	return configFromFile[T](fs, filepath.Join("./", stackFile))
 }
 
+// Platforms returns the platforms a stack's images should be built for, from the optional platforms list in its stack file
+func Platforms(config map[string]any) ([]string, error) {
+	value, ok := config["platforms"]
+	if !ok {
+		return nil, nil
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("stack platforms must be a list, e.g. [linux/amd64, linux/arm64]")
+	}
+
+	platforms := make([]string, len(list))
+
+	for i, item := range list {
+		platform, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("stack platform %v must be a string, e.g. linux/arm64", item)
+		}
+
+		platforms[i] = platform
+	}
+
+	return platforms, nil
+}
+
 // GetAllStackFiles returns a list of all stack files in the current directory
 func GetAllStackFiles(fs afero.Fs) ([]string, error) {
 	return afero.Glob(fs, "./nitric.*.yaml")
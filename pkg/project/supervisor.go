@@ -0,0 +1,463 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/samber/lo"
+
+	"github.com/nitrictech/cli/pkg/docker"
+)
+
+const (
+	defaultRestartBackoff    = time.Second
+	defaultRestartMaxBackoff = 30 * time.Second
+	defaultCrashLoopRestarts = 5
+	defaultCrashLoopWindow   = time.Minute
+
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+	defaultHealthCheckRetries  = 3
+)
+
+func (r RestartConfiguration) validate() error {
+	switch r.Policy {
+	case "", RestartPolicy_Never, RestartPolicy_OnFailure, RestartPolicy_Always:
+	default:
+		return fmt.Errorf("invalid restart policy %s, must be one of %s, %s or %s", r.Policy, RestartPolicy_Never, RestartPolicy_OnFailure, RestartPolicy_Always)
+	}
+
+	if r.Backoff < 0 || r.MaxBackoff < 0 || r.CrashLoopWindow < 0 || r.CrashLoopRestarts < 0 {
+		return fmt.Errorf("restart backoff, max-backoff, crash-loop-restarts and crash-loop-window can't be negative")
+	}
+
+	return nil
+}
+
+// restarts returns true if the service is restarted after an attempt exits with the given error
+func (r RestartConfiguration) restarts(err error) bool {
+	switch r.Policy {
+	case RestartPolicy_Always:
+		return true
+	case RestartPolicy_OnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+func (r RestartConfiguration) backoff() time.Duration {
+	return lo.Ternary(r.Backoff > 0, r.Backoff, defaultRestartBackoff)
+}
+
+func (r RestartConfiguration) maxBackoff() time.Duration {
+	return lo.Ternary(r.MaxBackoff > 0, r.MaxBackoff, defaultRestartMaxBackoff)
+}
+
+func (r RestartConfiguration) crashLoopRestarts() int {
+	return lo.Ternary(r.CrashLoopRestarts > 0, r.CrashLoopRestarts, defaultCrashLoopRestarts)
+}
+
+func (r RestartConfiguration) crashLoopWindow() time.Duration {
+	return lo.Ternary(r.CrashLoopWindow > 0, r.CrashLoopWindow, defaultCrashLoopWindow)
+}
+
+func (h HealthCheckConfiguration) validate() error {
+	if len(h.Command) > 0 && h.Path != "" {
+		return fmt.Errorf("health check can have a command or a path, not both")
+	}
+
+	if h.Port < 0 || h.Interval < 0 || h.Timeout < 0 || h.Retries < 0 || h.StartPeriod < 0 {
+		return fmt.Errorf("health check port, interval, timeout, retries and start-period can't be negative")
+	}
+
+	return nil
+}
+
+func (h HealthCheckConfiguration) enabled() bool {
+	return len(h.Command) > 0 || h.Path != ""
+}
+
+func (h HealthCheckConfiguration) interval() time.Duration {
+	return lo.Ternary(h.Interval > 0, h.Interval, defaultHealthCheckInterval)
+}
+
+func (h HealthCheckConfiguration) timeout() time.Duration {
+	return lo.Ternary(h.Timeout > 0, h.Timeout, defaultHealthCheckTimeout)
+}
+
+func (h HealthCheckConfiguration) retries() int {
+	return lo.Ternary(h.Retries > 0, h.Retries, defaultHealthCheckRetries)
+}
+
+// healthProbe checks the health of a running service once
+type healthProbe func(ctx context.Context) error
+
+// monitor probes the service until done is closed, sending an error once enough consecutive probes fail and nil once a probe passes again
+func (h HealthCheckConfiguration) monitor(probe healthProbe, done <-chan struct{}) <-chan error {
+	changes := make(chan error)
+
+	if !h.enabled() || probe == nil {
+		return changes
+	}
+
+	send := func(err error) bool {
+		select {
+		case changes <- err:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	go func() {
+		started := time.Now()
+		failures := 0
+		healthy := true
+
+		ticker := time.NewTicker(h.interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), h.timeout())
+			err := probe(ctx)
+			cancel()
+
+			if err == nil {
+				failures = 0
+
+				if !healthy {
+					healthy = true
+
+					if !send(nil) {
+						return
+					}
+				}
+
+				continue
+			}
+
+			if time.Since(started) < h.StartPeriod {
+				continue
+			}
+
+			failures++
+
+			if healthy && failures >= h.retries() {
+				healthy = false
+
+				if !send(err) {
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// httpProbe requests the path from the service on the given local port
+func httpProbe(port int, path string) healthProbe {
+	url := fmt.Sprintf("http://localhost:%d/%s", port, strings.TrimPrefix(path, "/"))
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s responded with status %d", url, resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// hostCommandProbe runs the command on the host from the given directory
+func hostCommandProbe(dir string, env []string, command []string) healthProbe {
+	return func(ctx context.Context) error {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Dir = dir
+		cmd.Env = env
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %w %s", strings.Join(command, " "), err, strings.TrimSpace(string(output)))
+		}
+
+		return nil
+	}
+}
+
+// containerCommandProbe runs the command in the service's container
+func containerCommandProbe(dockerClient *docker.Docker, containerId string, command []string) healthProbe {
+	return func(ctx context.Context) error {
+		execResp, err := dockerClient.ContainerExecCreate(ctx, containerId, types.ExecConfig{
+			Cmd:          command,
+			AttachStdout: true,
+			AttachStderr: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		attachResp, err := dockerClient.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+		if err != nil {
+			return err
+		}
+		defer attachResp.Close()
+
+		// the attached connection doesn't observe the context once established
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = attachResp.Conn.SetDeadline(deadline)
+		}
+
+		var output bytes.Buffer
+		if _, err := stdcopy.StdCopy(&output, &output, attachResp.Reader); err != nil {
+			return err
+		}
+
+		inspect, err := dockerClient.ContainerExecInspect(ctx, execResp.ID)
+		if err != nil {
+			return err
+		}
+
+		if inspect.ExitCode != 0 {
+			return fmt.Errorf("%s exited with status %d %s", strings.Join(command, " "), inspect.ExitCode, strings.TrimSpace(output.String()))
+		}
+
+		return nil
+	}
+}
+
+// ServiceRunState is the latest lifecycle status of a locally run service
+type ServiceRunState struct {
+	Status    ServiceRunStatus `json:"status"`
+	Restarts  int              `json:"restarts"`
+	LastError string           `json:"lastError,omitempty"`
+}
+
+const runStatesTopic = "run_states"
+
+// RunStates tracks the run state of the project's services by file path
+type RunStates struct {
+	lock   sync.RWMutex
+	states map[string]ServiceRunState
+	bus    EventBus.Bus
+}
+
+func NewRunStates() *RunStates {
+	return &RunStates{
+		states: map[string]ServiceRunState{},
+		bus:    EventBus.New(),
+	}
+}
+
+func (r *RunStates) GetState() map[string]ServiceRunState {
+	if r == nil {
+		return map[string]ServiceRunState{}
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return maps.Clone(r.states)
+}
+
+func (r *RunStates) SubscribeToState(fn func(map[string]ServiceRunState)) {
+	// ignore the error, it's only returned if the fn param isn't a function
+	_ = r.bus.Subscribe(runStatesTopic, fn)
+}
+
+// update applies fn to the service's state and publishes the result, a nil RunStates is a no-op
+func (r *RunStates) update(name string, fn func(state *ServiceRunState)) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	state := r.states[name]
+	fn(&state)
+	r.states[name] = state
+	states := maps.Clone(r.states)
+	r.lock.Unlock()
+
+	r.bus.Publish(runStatesTopic, states)
+}
+
+func (r *RunStates) setStatus(name string, status ServiceRunStatus, err error) {
+	r.update(name, func(state *ServiceRunState) {
+		state.Status = status
+		if err != nil {
+			state.LastError = err.Error()
+		}
+	})
+}
+
+// supervise runs attempts of a service until it's stopped, restarting it according to its restart configuration.
+// Attempts call started once the service is actually running, e.g. after its container has started
+func supervise(serviceName string, label string, restart RestartConfiguration, states *RunStates, stop <-chan bool, updates chan<- ServiceRunUpdate, attempt func(stop <-chan bool, started func()) error) error {
+	restarts := 0
+	recentRestarts := []time.Time{}
+	backoff := restart.backoff()
+
+	for {
+		// attempts receive a single stop, the same as the stop channels they were given before being supervised
+		attemptStop := make(chan bool, 1)
+		result := make(chan error, 1)
+		started := time.Now()
+
+		go func() {
+			result <- attempt(attemptStop, func() {
+				states.setStatus(label, ServiceRunStatus_Running, nil)
+			})
+		}()
+
+		var err error
+
+		select {
+		case <-stop:
+			attemptStop <- true
+			<-result
+
+			states.setStatus(label, ServiceRunStatus_Done, nil)
+
+			return nil
+		case err = <-result:
+		}
+
+		if !restart.restarts(err) {
+			if err != nil {
+				updates <- ServiceRunUpdate{
+					ServiceName: serviceName,
+					Label:       label,
+					Message:     err.Error(),
+					Status:      ServiceRunStatus_Error,
+					Err:         err,
+					Restarts:    restarts,
+				}
+
+				states.setStatus(label, ServiceRunStatus_Error, err)
+
+				return err
+			}
+
+			updates <- ServiceRunUpdate{
+				ServiceName: serviceName,
+				Label:       label,
+				Message:     "Service successfully exited",
+				Status:      ServiceRunStatus_Done,
+				Restarts:    restarts,
+			}
+
+			states.setStatus(label, ServiceRunStatus_Done, nil)
+
+			return nil
+		}
+
+		// a service that ran for a while before exiting starts backing off again
+		if time.Since(started) >= restart.crashLoopWindow() {
+			backoff = restart.backoff()
+		}
+
+		now := time.Now()
+		recentRestarts = append(lo.Filter(recentRestarts, func(t time.Time, _ int) bool {
+			return now.Sub(t) < restart.crashLoopWindow()
+		}), now)
+
+		if len(recentRestarts) > restart.crashLoopRestarts() {
+			crashErr := fmt.Errorf("service %s restarted %d times within %s, it won't be restarted again", label, restart.crashLoopRestarts(), restart.crashLoopWindow())
+			if err != nil {
+				crashErr = fmt.Errorf("%w, last error: %w", crashErr, err)
+			}
+
+			updates <- ServiceRunUpdate{
+				ServiceName: serviceName,
+				Label:       "nitric",
+				Message:     crashErr.Error(),
+				Status:      ServiceRunStatus_CrashLoop,
+				Err:         crashErr,
+				Restarts:    restarts,
+			}
+
+			states.setStatus(label, ServiceRunStatus_CrashLoop, crashErr)
+
+			// the service stays down, the rest of the project keeps running as callers wait for every service
+			return crashErr
+		}
+
+		restarts++
+
+		message := fmt.Sprintf("restarting service %s in %s (restart %d)", label, backoff, restarts)
+		if err != nil {
+			message = fmt.Sprintf("%s\n%s", err.Error(), message)
+		}
+
+		updates <- ServiceRunUpdate{
+			ServiceName: serviceName,
+			Label:       "nitric",
+			Message:     message,
+			Status:      ServiceRunStatus_Restarting,
+			Err:         err,
+			Restarts:    restarts,
+		}
+
+		states.update(label, func(state *ServiceRunState) {
+			state.Status = ServiceRunStatus_Restarting
+			state.Restarts = restarts
+
+			if err != nil {
+				state.LastError = err.Error()
+			}
+		})
+
+		select {
+		case <-stop:
+			states.setStatus(label, ServiceRunStatus_Done, nil)
+
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, restart.maxBackoff())
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSupervise(t *testing.T) {
+	errCrash := errors.New("crash")
+
+	tests := []struct {
+		name         string
+		restart      RestartConfiguration
+		results      []error
+		wantErr      bool
+		wantAttempts int
+		wantState    ServiceRunState
+	}{
+		{
+			name:         "never restarts a crash",
+			restart:      RestartConfiguration{},
+			results:      []error{errCrash},
+			wantErr:      true,
+			wantAttempts: 1,
+			wantState:    ServiceRunState{Status: ServiceRunStatus_Error, LastError: "crash"},
+		},
+		{
+			name:         "on-failure restarts until a clean exit",
+			restart:      RestartConfiguration{Policy: RestartPolicy_OnFailure, Backoff: time.Millisecond},
+			results:      []error{errCrash, errCrash, nil},
+			wantAttempts: 3,
+			wantState:    ServiceRunState{Status: ServiceRunStatus_Done, Restarts: 2, LastError: "crash"},
+		},
+		{
+			name:         "always stops restarting a crash loop",
+			restart:      RestartConfiguration{Policy: RestartPolicy_Always, Backoff: time.Millisecond, CrashLoopRestarts: 2},
+			results:      []error{nil, nil, nil, nil},
+			wantErr:      true,
+			wantAttempts: 3,
+			wantState: ServiceRunState{
+				Status:    ServiceRunStatus_CrashLoop,
+				Restarts:  2,
+				LastError: "service services/api.ts restarted 2 times within 1m0s, it won't be restarted again",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := NewRunStates()
+			updates := make(chan ServiceRunUpdate, 100)
+			attempts := 0
+
+			err := supervise("api", "services/api.ts", tt.restart, states, make(chan bool), updates, func(stop <-chan bool, started func()) error {
+				attempts++
+				started()
+				return tt.results[attempts-1]
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("supervise() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("supervise() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+
+			if diff := cmp.Diff(tt.wantState, states.GetState()["services/api.ts"]); diff != "" {
+				t.Errorf("supervise() state mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSuperviseRunningOnceStarted(t *testing.T) {
+	states := NewRunStates()
+	updates := make(chan ServiceRunUpdate, 100)
+
+	err := supervise("api", "services/api.ts", RestartConfiguration{}, states, make(chan bool), updates, func(stop <-chan bool, started func()) error {
+		if status := states.GetState()["services/api.ts"].Status; status == ServiceRunStatus_Running {
+			t.Errorf("supervise() status = %s before the attempt started", status)
+		}
+
+		started()
+
+		if status := states.GetState()["services/api.ts"].Status; status != ServiceRunStatus_Running {
+			t.Errorf("supervise() status = %s once the attempt started, want %s", status, ServiceRunStatus_Running)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("supervise() error = %v", err)
+	}
+}
+
+func TestRunCommandWithoutSupervision(t *testing.T) {
+	service := &Service{
+		Name:     "api",
+		filepath: "services/api.ts",
+		basedir:  t.TempDir(),
+		restart:  RestartConfiguration{Policy: RestartPolicy_Always},
+		healthCheck: HealthCheckConfiguration{
+			Command:  []string{"false"},
+			Interval: 10 * time.Millisecond,
+			Retries:  1,
+		},
+		runStates: NewRunStates(),
+	}
+
+	tests := []struct {
+		name          string
+		service       Service
+		wantUnhealthy bool
+	}{
+		{
+			name:          "stops an unhealthy service",
+			service:       *service,
+			wantUnhealthy: true,
+		},
+		{
+			name:    "runs the service to completion without health checks",
+			service: service.withoutSupervision(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan ServiceRunUpdate, 100)
+
+			err := tt.service.runCommand(make(chan bool), updates, []string{"sleep", "0.5"}, nil, nil)
+			close(updates)
+
+			if gotUnhealthy := err != nil; gotUnhealthy != tt.wantUnhealthy {
+				t.Fatalf("runCommand() error = %v, want unhealthy %t", err, tt.wantUnhealthy)
+			}
+
+			for update := range updates {
+				if !tt.wantUnhealthy && update.Status == ServiceRunStatus_Unhealthy {
+					t.Errorf("runCommand() reported the service unhealthy: %s", update.Message)
+				}
+			}
+		})
+	}
+
+	if status := service.runStates.GetState()[service.GetFilePath()].Status; status != ServiceRunStatus_Unhealthy {
+		t.Errorf("run state = %s, want only the supervised run to update it", status)
+	}
+}
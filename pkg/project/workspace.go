@@ -0,0 +1,74 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// isOutsideDir returns true if a path relative to a directory escapes it
+func isOutsideDir(relPath string) bool {
+	return relPath == ".." || strings.HasPrefix(relPath, "../")
+}
+
+// contextIncludes returns the paths relative to the build context that a service needs: its own directory, and the runtime's includes and shared packages
+func contextIncludes(fs afero.Fs, contextDir string, entrypoint string, runtimeConfig RuntimeConfiguration) ([]string, error) {
+	serviceDir, err := filepath.Rel(contextDir, filepath.Dir(entrypoint))
+	if err != nil {
+		return nil, err
+	}
+
+	serviceDir = filepath.ToSlash(serviceDir)
+	if isOutsideDir(serviceDir) {
+		return nil, fmt.Errorf("service %s is outside of the runtime context %s", entrypoint, contextDir)
+	}
+
+	includes := []string{serviceDir}
+
+	// services at the root of the context only need their entrypoint, rather than the whole context
+	if serviceDir == "." {
+		includes = []string{filepath.Base(entrypoint)}
+	}
+
+	for _, include := range runtimeConfig.Include {
+		include = filepath.ToSlash(filepath.Clean(include))
+		if filepath.IsAbs(include) || isOutsideDir(include) {
+			return nil, fmt.Errorf("include %s is outside of the runtime context %s, set the runtime context to a directory containing it", include, contextDir)
+		}
+
+		includes = append(includes, include)
+	}
+
+	for _, pkg := range runtimeConfig.Packages {
+		pkg = filepath.ToSlash(filepath.Clean(pkg))
+		if filepath.IsAbs(pkg) || isOutsideDir(pkg) {
+			return nil, fmt.Errorf("package %s is outside of the runtime context %s, set the runtime context to a directory containing it", pkg, contextDir)
+		}
+
+		if _, err := fs.Stat(filepath.Join(contextDir, pkg)); err != nil {
+			return nil, fmt.Errorf("unable to find shared package %s in the runtime context %s: %w", pkg, contextDir, err)
+		}
+
+		includes = append(includes, pkg)
+	}
+
+	return includes, nil
+}
@@ -0,0 +1,126 @@
+// Copyright Nitric Pty Ltd.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/nitrictech/cli/pkg/project/runtime"
+)
+
+func TestContextIncludes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "repo/packages/shared/package.json", []byte("{}"), 0o644)
+
+	tests := []struct {
+		name          string
+		contextDir    string
+		entrypoint    string
+		runtimeConfig RuntimeConfiguration
+		want          []string
+		wantErr       bool
+	}{
+		{
+			name:       "service directory, includes and packages",
+			contextDir: "repo",
+			entrypoint: "repo/services/api/index.ts",
+			runtimeConfig: RuntimeConfiguration{
+				Include:  []string{"package.json", "./pnpm-lock.yaml"},
+				Packages: []string{"packages/shared/"},
+			},
+			want: []string{"services/api", "package.json", "pnpm-lock.yaml", "packages/shared"},
+		},
+		{
+			name:          "service at the root of the context",
+			contextDir:    "repo",
+			entrypoint:    "repo/main.py",
+			runtimeConfig: RuntimeConfiguration{Include: []string{"requirements.txt"}},
+			want:          []string{"main.py", "requirements.txt"},
+		},
+		{
+			name:          "include outside of the context",
+			contextDir:    "repo",
+			entrypoint:    "repo/services/api/index.ts",
+			runtimeConfig: RuntimeConfiguration{Include: []string{"../shared"}},
+			wantErr:       true,
+		},
+		{
+			name:          "missing package",
+			contextDir:    "repo",
+			entrypoint:    "repo/services/api/index.ts",
+			runtimeConfig: RuntimeConfiguration{Packages: []string{"packages/missing"}},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := contextIncludes(fs, tt.contextDir, tt.entrypoint, tt.runtimeConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("contextIncludes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Errorf("contextIncludes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIncludeOnlyFingerprint(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "repo/services/api/index.ts", []byte("api"), 0o644)
+	_ = afero.WriteFile(fs, "repo/services/api/node_modules/lib/index.js", []byte("lib"), 0o644)
+	_ = afero.WriteFile(fs, "repo/services/worker/index.ts", []byte("worker"), 0o644)
+	_ = afero.WriteFile(fs, "repo/packages/shared/index.ts", []byte("shared"), 0o644)
+
+	buildContext := runtime.RuntimeBuildContext{
+		DockerfileContents: "FROM node",
+		BaseDirectory:      "repo",
+		IgnoreFileContents: "**/node_modules",
+	}
+	buildContext.IncludeOnly([]string{"services/api", "packages/shared"})
+
+	fingerprint := func() string {
+		fp, err := buildFingerprint(fs, buildContext, []string{"linux/amd64"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return fp
+	}
+
+	original := fingerprint()
+
+	_ = afero.WriteFile(fs, "repo/services/worker/index.ts", []byte("changed"), 0o644)
+	_ = afero.WriteFile(fs, "repo/services/api/node_modules/lib/index.js", []byte("changed"), 0o644)
+
+	if fingerprint() != original {
+		t.Errorf("expected files outside of the included paths not to change the fingerprint")
+	}
+
+	_ = afero.WriteFile(fs, "repo/packages/shared/index.ts", []byte("changed"), 0o644)
+
+	if fingerprint() == original {
+		t.Errorf("expected a changed shared package to change the fingerprint")
+	}
+}
@@ -101,7 +101,7 @@ func (m *Model) AllDone() bool {
 				continue
 			}
 
-			if update.Status == project.ServiceBuildStatus_Complete {
+			if update.Status == project.ServiceBuildStatus_Complete || update.Status == project.ServiceBuildStatus_Cached {
 				continue
 			}
 
@@ -154,6 +154,8 @@ func (m Model) View() string {
 			statusColor := tui.Colors.TextMuted
 			if latestUpdate.Status == project.ServiceBuildStatus_Complete {
 				statusColor = tui.Colors.Green
+			} else if latestUpdate.Status == project.ServiceBuildStatus_Cached {
+				statusColor = tui.Colors.Teal
 			} else if latestUpdate.Status == project.ServiceBuildStatus_InProgress {
 				statusColor = tui.Colors.Blue
 			} else if latestUpdate.Status == project.ServiceBuildStatus_Error {
@@ -173,7 +175,7 @@ func (m Model) View() string {
 			}
 		} else {
 			messageLines := strings.Split(strings.TrimSpace(latestUpdate.Message), "\n")
-			if len(messageLines) > 0 && latestUpdate.Status != project.ServiceBuildStatus_Complete && latestUpdate.Status != project.ServiceBuildStatus_Skipped {
+			if len(messageLines) > 0 && latestUpdate.Status != project.ServiceBuildStatus_Complete && latestUpdate.Status != project.ServiceBuildStatus_Cached && latestUpdate.Status != project.ServiceBuildStatus_Skipped {
 				serviceUpdates.Addln("  %s", messageLines[len(messageLines)-1]).WithStyle(lipgloss.NewStyle().Foreground(tui.Colors.TextMuted))
 			}
 		}
@@ -178,6 +178,13 @@ func (t *TuiModel) ReactiveUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 		}
 
+		if ingressAddress := t.localCloud.Gateway.GetIngressAddress(); ingressAddress != "" {
+			newHttpProxiesSummary = append(newHttpProxiesSummary, HttpProxySummary{
+				name: "ingress",
+				url:  ingressAddress,
+			})
+		}
+
 		t.httpProxies = newHttpProxiesSummary
 	case topics.State:
 		// update the api state by getting the latest API addresses
@@ -17,6 +17,7 @@
 package services
 
 import (
+	"fmt"
 	"slices"
 	"strings"
 
@@ -41,6 +42,7 @@ type Model struct {
 	stopChan           chan<- bool
 	updateChan         <-chan project.ServiceRunUpdate
 	localServicesModel tea.Model
+	runStates          *project.RunStates
 
 	windowSize tea.WindowSizeMsg
 	viewOffset int
@@ -90,8 +92,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Write log to file and handle any errors
 		level := logrus.InfoLevel
 
-		if msg.Value.Status == project.ServiceRunStatus_Error {
+		if msg.Value.Status.Failed() {
 			level = logrus.ErrorLevel
+		} else if msg.Value.Status == project.ServiceRunStatus_Restarting {
+			level = logrus.WarnLevel
 		}
 
 		logger.WriteLog(level, msg.Value.Message, msg.Value.Label)
@@ -206,6 +210,8 @@ func (m Model) View() string {
 		lv.Addln(" services registered with local nitric server")
 	}
 
+	m.viewRunStates(lv)
+
 	svcColors := map[string]lipgloss.CompleteAdaptiveColor{}
 	serviceNames := lo.Keys(m.serviceStatus)
 
@@ -217,8 +223,10 @@ func (m Model) View() string {
 
 	for _, update := range m.serviceRunUpdates {
 		statusColor := tui.Colors.TextMuted
-		if update.Status == project.ServiceRunStatus(project.ServiceBuildStatus_Error) {
+		if update.Status.Failed() {
 			statusColor = tui.Colors.Red
+		} else if update.Status == project.ServiceRunStatus_Restarting {
+			statusColor = tui.Colors.Orange
 		}
 
 		rv.Addf("%s: ", update.Label).WithStyle(lipgloss.NewStyle().Foreground(svcColors[update.ServiceName]))
@@ -246,12 +254,50 @@ func (m Model) View() string {
 	return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderForeground(tui.Colors.Gray).Render(sideBySide) + "\n " + fragments.Hotkey("esc", "quit") + " " + fragments.Hotkey("↑/↓", "navigate logs")
 }
 
-func NewModel(stopChannel chan<- bool, updateChannel <-chan project.ServiceRunUpdate, localCloud *cloud.LocalCloud, dashboardUrl string) Model {
+// viewRunStates lists services that have restarted or aren't running normally
+func (m Model) viewRunStates(v *view.View) {
+	if m.runStates == nil {
+		return
+	}
+
+	states := m.runStates.GetState()
+	names := lo.Keys(states)
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		state := states[name]
+		if state.Status == project.ServiceRunStatus_Running && state.Restarts == 0 {
+			continue
+		}
+
+		statusColor := tui.Colors.TextMuted
+
+		switch {
+		case state.Status.Failed():
+			statusColor = tui.Colors.Red
+		case state.Status == project.ServiceRunStatus_Restarting:
+			statusColor = tui.Colors.Orange
+		}
+
+		v.Addf("%s: ", name)
+		v.Add(string(state.Status)).WithStyle(lipgloss.NewStyle().Foreground(statusColor))
+
+		if state.Restarts > 0 {
+			v.Addf(" (%s)", lo.Ternary(state.Restarts == 1, "1 restart", fmt.Sprintf("%d restarts", state.Restarts)))
+		}
+
+		v.Break()
+	}
+}
+
+func NewModel(stopChannel chan<- bool, updateChannel <-chan project.ServiceRunUpdate, localCloud *cloud.LocalCloud, runStates *project.RunStates, dashboardUrl string) Model {
 	localServicesModel := local.NewTuiModel(localCloud, dashboardUrl)
 
 	return Model{
 		stopChan:           stopChannel,
 		localServicesModel: localServicesModel,
+		runStates:          runStates,
 		updateChan:         updateChannel,
 		serviceStatus:      make(map[string]project.ServiceRunUpdate),
 		serviceRunUpdates:  []project.ServiceRunUpdate{},
@@ -49,13 +49,19 @@ type Model struct {
 	errs               []error
 	resultOutput       string
 
-	done bool
+	done      bool
+	completed bool
 
 	spinner spinner.Model
 }
 
 var _ tea.Model = Model{}
 
+// Deployed returns true if the provider finished the deployment without reporting errors
+func (m Model) Deployed() bool {
+	return m.completed && len(m.errs) == 0
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
@@ -92,6 +98,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// the source channel is closed
 		if !msg.Ok {
 			m.done = true
+			m.completed = true
 			return m, teax.Quit
 		}
 
@@ -162,8 +162,27 @@ func RequirePodman() *DependencyError {
 	return nil
 }
 
+func RequireBuildKit() *DependencyError {
+	if _, err := exec.LookPath("buildctl-daemonless.sh"); err == nil {
+		return nil
+	}
+
+	_, err := exec.LookPath("buildctl")
+	if err != nil {
+		return &DependencyError{
+			details: err.Error(),
+			assist:  "buildctl is required to build images without docker, see https://github.com/moby/buildkit#rootless-mode for installation instructions",
+		}
+	}
+
+	return nil
+}
+
 var RequireContainerBuilder = atLeastOne(RequireDocker, RequirePodman)
 
+// RequireImageBuilder allows commands that only build images to use a daemonless builder
+var RequireImageBuilder = atLeastOne(RequireDocker, RequirePodman, RequireBuildKit)
+
 // AddDependencyCheck - Wraps a cobra command with a pre-run that
 // will check for dependencies
 func AddDependencyCheck(cmd *cobra.Command, deps ...Dependency) *cobra.Command {